@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// registerAdaptiveRoutes mounts the adaptive delivery endpoints under
+// /api/quizzes/:id. An adaptive quiz's attempts start with no questions and
+// ask for each next one until they are told they are done.
+func registerAdaptiveRoutes(quizzes *gin.RouterGroup, adaptiveRepo repository.AdaptiveStore, attemptRepo repository.AttemptStore) {
+	quizzes.GET("/:id/adaptive", func(c *gin.Context) {
+		settings, err := adaptiveRepo.GetSettings(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	})
+
+	quizzes.PUT("/:id/adaptive", func(c *gin.Context) {
+		var data models.AdaptiveSettings
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		settings, err := adaptiveRepo.ReplaceSettings(c.Request.Context(), c.Param("id"), data)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	})
+
+	quizzes.DELETE("/:id/adaptive", func(c *gin.Context) {
+		if err := adaptiveRepo.DeleteSettings(c.Request.Context(), c.Param("id")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Adaptive settings deleted successfully"})
+	})
+
+	// Difficulty and discrimination of every question the quiz could ask
+	quizzes.GET("/:id/calibration", func(c *gin.Context) {
+		calibrations, err := adaptiveRepo.GetCalibrations(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, calibrations)
+	})
+
+	// Refit them from the responses of submitted attempts
+	quizzes.POST("/:id/calibration", func(c *gin.Context) {
+		calibrations, err := adaptiveRepo.Calibrate(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, calibrations)
+	})
+
+	// The ability estimate so far and the question to answer next, which
+	// stays the same until it is answered
+	quizzes.POST("/:id/attempts/:attempt_id/next", func(c *gin.Context) {
+		step, err := attemptRepo.Next(c.Request.Context(), c.Param("id"), c.Param("attempt_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, step)
+	})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// responseRequest carries answer IDs for questions with answers, or the
+// typed text for text questions. Clients may report the time spent on the
+// question for item analysis.
+type responseRequest struct {
+	AnswerIDs   []int64  `json:"answer_ids" binding:"required_without=Text"`
+	Text        []string `json:"text" binding:"required_without=AnswerIDs"`
+	TimeSpentMs *int64   `json:"time_spent_ms" binding:"omitempty,min=0"`
+}
+
+type submittedResponse struct {
+	QuestionID  int      `json:"question_id" binding:"required"`
+	AnswerIDs   []int64  `json:"answer_ids" binding:"required_without=Text"`
+	Text        []string `json:"text" binding:"required_without=AnswerIDs"`
+	TimeSpentMs *int64   `json:"time_spent_ms" binding:"omitempty,min=0"`
+}
+
+type submitAttemptRequest struct {
+	Responses []submittedResponse `json:"responses" binding:"dive"`
+}
+
+// registerAttemptRoutes mounts the attempt endpoints under /api/quizzes/:id.
+// Scoring happens entirely on the server; clients only send answer IDs or
+// typed text.
+func registerAttemptRoutes(quizzes *gin.RouterGroup, attemptRepo repository.AttemptStore) {
+	quizzes.POST("/:id/attempts", func(c *gin.Context) {
+		quizID := c.Param("id")
+		var req models.AttemptStart
+		if c.Request.ContentLength > 0 {
+			if !bindJSON(c, &req) {
+				return
+			}
+		}
+
+		attempt, err := attemptRepo.Start(c.Request.Context(), quizID, req)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, attempt)
+	})
+
+	// Replays what an earlier attempt was given: its seed and its questions
+	// with their answers, as stored when it started. Nothing is written, so
+	// the replay never counts towards statistics or review schedules. The
+	// answers are only shown once the attempt is submitted.
+	quizzes.GET("/:id/attempts/:attempt_id/replay", func(c *gin.Context) {
+		attempt, err := attemptRepo.GetByID(c.Request.Context(), c.Param("id"), c.Param("attempt_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if attempt.Status != models.AttemptSubmitted {
+			respondError(c, repository.Conflict("attempt has not been submitted"))
+			return
+		}
+
+		questions, err := attemptRepo.GetQuestions(c.Request.Context(), c.Param("id"), c.Param("attempt_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"attempt":   attempt,
+			"questions": questions,
+		})
+	})
+
+	quizzes.GET("/:id/attempts", func(c *gin.Context) {
+		quizID := c.Param("id")
+		attempts, err := attemptRepo.GetByQuizID(c.Request.Context(), quizID, c.Query("user_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, attempts)
+	})
+
+	quizzes.GET("/:id/attempts/:attempt_id", func(c *gin.Context) {
+		attempt, err := attemptRepo.GetByID(c.Request.Context(), c.Param("id"), c.Param("attempt_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, attempt)
+	})
+
+	// The questions the attempt was given, in its order and without their
+	// correct answers
+	quizzes.GET("/:id/attempts/:attempt_id/play", func(c *gin.Context) {
+		questions, err := attemptRepo.GetQuestions(c.Request.Context(), c.Param("id"), c.Param("attempt_id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		player := make([]models.PlayerQuestion, 0, len(questions))
+		for _, question := range questions {
+			player = append(player, models.NewPlayerQuestion(question))
+		}
+
+		c.JSON(http.StatusOK, player)
+	})
+
+	quizzes.PUT("/:id/attempts/:attempt_id/responses/:question_id", func(c *gin.Context) {
+		questionID, ok := pathID(c, "question_id")
+		if !ok {
+			return
+		}
+
+		var req responseRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		err := attemptRepo.RecordResponse(c.Request.Context(), c.Param("id"), c.Param("attempt_id"), models.AttemptResponse{
+			QuestionID:  questionID,
+			AnswerIDs:   req.AnswerIDs,
+			Text:        req.Text,
+			TimeSpentMs: req.TimeSpentMs,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Response recorded successfully"})
+	})
+
+	// Each call reveals the next tier of the question's hints. Its penalty
+	// is taken off the question's points when the attempt is submitted.
+	quizzes.POST("/:id/attempts/:attempt_id/hints/:question_id", func(c *gin.Context) {
+		questionID, ok := pathID(c, "question_id")
+		if !ok {
+			return
+		}
+
+		hint, err := attemptRepo.RevealHint(c.Request.Context(), c.Param("id"), c.Param("attempt_id"), questionID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, hint)
+	})
+
+	quizzes.POST("/:id/attempts/:attempt_id/submit", func(c *gin.Context) {
+		// Responses may be recorded one at a time beforehand, sent here in
+		// bulk, or both.
+		var req submitAttemptRequest
+		if c.Request.ContentLength > 0 {
+			if !bindJSON(c, &req) {
+				return
+			}
+		}
+
+		responses := make([]models.AttemptResponse, 0, len(req.Responses))
+		for _, response := range req.Responses {
+			responses = append(responses, models.AttemptResponse{
+				QuestionID:  response.QuestionID,
+				AnswerIDs:   response.AnswerIDs,
+				Text:        response.Text,
+				TimeSpentMs: response.TimeSpentMs,
+			})
+		}
+
+		attempt, err := attemptRepo.Submit(c.Request.Context(), c.Param("id"), c.Param("attempt_id"), responses)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, attempt)
+	})
+}
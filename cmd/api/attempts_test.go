@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAttemptGradedOnlyByServer(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+	first, second := created.Questions[0], created.Questions[1]
+
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts", `{"user_id": "ada"}`, nil), http.StatusCreated, "start attempt")
+
+	// An answer to another question cannot be chosen
+	expectStatus(t, do(t, router, http.MethodPut, "/api/quizzes/1/attempts/1/responses/"+itoa(first.ID),
+		`{"answer_ids": [`+itoa(second.AnswerIDs[0])+`]}`, nil), http.StatusUnprocessableEntity, "respond with another question's answer")
+
+	// Grading the client sends is ignored
+	expectStatus(t, do(t, router, http.MethodPut, "/api/quizzes/1/attempts/1/responses/"+itoa(first.ID),
+		`{"answer_ids": [`+itoa(first.AnswerIDs[1])+`], "is_correct": true, "points": 1}`, nil), http.StatusOK, "record wrong response")
+
+	var attempt struct {
+		Score     *float64 `json:"score"`
+		Responses []struct {
+			IsCorrect *bool    `json:"is_correct"`
+			Points    *float64 `json:"points"`
+		} `json:"responses"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/1/attempts/1", "", &attempt), http.StatusOK, "get attempt")
+	if attempt.Score != nil || len(attempt.Responses) != 1 || attempt.Responses[0].IsCorrect != nil || attempt.Responses[0].Points != nil {
+		t.Errorf("attempt before submit = %+v, want it ungraded", attempt)
+	}
+
+	var submitted struct {
+		Score    float64 `json:"score"`
+		MaxScore float64 `json:"max_score"`
+	}
+	body := `{"score": 2, "responses": [{"question_id": ` + itoa(second.ID) + `, "answer_ids": [` + itoa(second.AnswerIDs[1]) + `], "is_correct": true}]}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts/1/submit", body, &submitted), http.StatusOK, "submit")
+	if submitted.Score != 0 || submitted.MaxScore != 2 {
+		t.Errorf("submit got %+v, want 0/2 from the answers table", submitted)
+	}
+}
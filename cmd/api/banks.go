@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// registerBankRoutes mounts the question bank endpoints under /api/banks.
+// Once added, a bank's questions are edited through /api/questions like any
+// other question.
+func registerBankRoutes(api *gin.RouterGroup, bankRepo repository.BankStore) {
+	banks := api.Group("/banks")
+
+	banks.GET("", func(c *gin.Context) {
+		all, err := bankRepo.GetAll(c.Request.Context())
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, all)
+	})
+
+	banks.GET("/:id", func(c *gin.Context) {
+		bank, err := bankRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, bank)
+	})
+
+	banks.POST("", func(c *gin.Context) {
+		var data models.BankInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		id, err := bankRepo.Create(c.Request.Context(), data)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+
+	banks.PUT("/:id", func(c *gin.Context) {
+		var data models.BankInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		if err := bankRepo.Replace(c.Request.Context(), c.Param("id"), data); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Bank updated successfully"})
+	})
+
+	banks.DELETE("/:id", func(c *gin.Context) {
+		if err := bankRepo.Delete(c.Request.Context(), c.Param("id")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Bank deleted successfully"})
+	})
+
+	banks.GET("/:id/questions", func(c *gin.Context) {
+		var query models.QuestionQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		questions, err := bankRepo.GetQuestions(c.Request.Context(), c.Param("id"), query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, questions)
+	})
+
+	banks.POST("/:id/questions", func(c *gin.Context) {
+		var data models.QuestionDraft
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		created, err := bankRepo.AddQuestion(c.Request.Context(), c.Param("id"), data)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, created)
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// problem is an RFC 7807 problem details body. Fields lists the invalid
+// fields when the problem is with the request.
+type problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Fields   []models.FieldError `json:"fields,omitempty"`
+}
+
+// writeProblem aborts the request with an application/problem+json response.
+func writeProblem(c *gin.Context, status int, detail string, fields []models.FieldError) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(status, problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Fields:   fields,
+	})
+}
+
+// respondError maps an error from the repositories to a problem response.
+// Domain errors carry messages meant for clients. Anything else is logged and
+// reported as a bare 500, so database error text never reaches the client.
+func respondError(c *gin.Context, err error) {
+	var domainErr *repository.Error
+	if !errors.As(err, &domainErr) {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		writeProblem(c, http.StatusInternalServerError, "", nil)
+		return
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeProblem(c, http.StatusNotFound, domainErr.Error(), nil)
+	case errors.Is(err, repository.ErrConflict):
+		writeProblem(c, http.StatusConflict, domainErr.Error(), nil)
+	case errors.Is(err, repository.ErrReference):
+		writeProblem(c, http.StatusUnprocessableEntity, domainErr.Error(), nil)
+	case errors.Is(err, repository.ErrValidation):
+		var fields []models.FieldError
+		if domainErr.Field != "" {
+			fields = []models.FieldError{{Field: domainErr.Field, Message: domainErr.Message}}
+		}
+		writeProblem(c, http.StatusUnprocessableEntity, domainErr.Error(), fields)
+	default:
+		log.Printf("%s %s: unmapped domain error: %v", c.Request.Method, c.Request.URL.Path, err)
+		writeProblem(c, http.StatusInternalServerError, "", nil)
+	}
+}
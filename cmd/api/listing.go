@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// listQuizzes serves GET /api/quizzes. The response is an envelope with the
+// page of quizzes and the cursor for the next page, which is also sent as a
+// Link header so clients can follow it without parsing the body.
+func listQuizzes(quizRepo repository.QuizStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query models.QuizQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		page, err := quizRepo.List(c.Request.Context(), query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		if page.NextCursor != nil {
+			next := *c.Request.URL
+			values := next.Query()
+			values.Set("cursor", *page.NextCursor)
+			next.RawQuery = values.Encode()
+			c.Header("Link", "<"+next.RequestURI()+`>; rel="next"`)
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
@@ -5,18 +5,31 @@ import (
 	"net/http"
 
 	"github.com/changangus/go-quiz-backend/db"
+	"github.com/changangus/go-quiz-backend/internal/models"
 	"github.com/changangus/go-quiz-backend/internal/repository"
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 )
 
-func setupRouter(database *sqlx.DB) *gin.Engine {
+// hintsRequest replaces every hint of a question. The order of Hints sets
+// their tiers, and an empty list removes them.
+type hintsRequest struct {
+	Hints []models.HintDraft `json:"hints" binding:"dive"`
+}
+
+// drawRulesRequest replaces every draw rule of a quiz. The order of Rules
+// sets the order they are applied in, and an empty list removes them.
+type drawRulesRequest struct {
+	Rules []models.DrawRuleDraft `json:"rules" binding:"dive"`
+}
+
+func setupRouter(repos repository.Repositories) *gin.Engine {
 	router := gin.Default()
+	router.Use(validatePathIDs)
 
-	// Create repositories
-	quizRepo := repository.NewQuizRepository(database)
-	questionRepo := repository.NewQuestionRepository(database)
-	answerRepo := repository.NewAnswerRepository(database)
+	quizRepo := repos.Quizzes
+	questionRepo := repos.Questions
+	answerRepo := repos.Answers
+	attemptRepo := repos.Attempts
 
 	// Health check endpoint
 	router.GET("/ping", func(c *gin.Context) {
@@ -28,199 +41,349 @@ func setupRouter(database *sqlx.DB) *gin.Engine {
 	// API routes
 	api := router.Group("/api")
 	{
+		// Full-text search across quizzes, questions and answers
+		api.GET("/search", searchContent(repos.Search))
+
 		// Quizzes endpoints
 		quizzes := api.Group("/quizzes")
 		{
-			quizzes.GET("", func(c *gin.Context) {
-				quizzes, err := quizRepo.GetAll()
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-					return
-				}
-				c.JSON(http.StatusOK, quizzes)
-			})
+			// Listing is paginated with an opaque cursor; see listQuizzes
+			quizzes.GET("", listQuizzes(quizRepo))
 
 			quizzes.GET("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				quiz, err := quizRepo.GetByID(id)
+				quiz, err := quizRepo.GetByID(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+					respondError(c, err)
 					return
 				}
-				
+
 				// Get questions for this quiz
-				questions, err := questionRepo.GetByQuizID(id)
+				questions, err := questionRepo.GetByQuizID(c.Request.Context(), id, models.QuestionQuery{})
 				if err == nil {
-					// If we have questions, attach them to the quiz response
-					// This could be enhanced to include answers as well
+					// If we have questions, attach them to the quiz response.
+					// Use /quizzes/:id/full to include answers as well.
 					c.JSON(http.StatusOK, gin.H{
-						"quiz": quiz,
+						"quiz":      quiz,
 						"questions": questions,
 					})
 					return
 				}
-				
+
+				c.JSON(http.StatusOK, quiz)
+			})
+
+			// Admin view of the whole quiz tree in a single response
+			quizzes.GET("/:id/full", func(c *gin.Context) {
+				quiz, err := quizRepo.GetFull(c.Request.Context(), c.Param("id"))
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
 				c.JSON(http.StatusOK, quiz)
 			})
 
+			// Player view: the same quiz with correctness stripped out
+			quizzes.GET("/:id/play", func(c *gin.Context) {
+				quiz, err := quizRepo.GetFull(c.Request.Context(), c.Param("id"))
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, models.NewPlayerQuiz(*quiz))
+			})
+
 			quizzes.POST("", func(c *gin.Context) {
-				var quiz map[string]interface{}
-				if err := c.ShouldBindJSON(&quiz); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				var quiz models.QuizInput
+				if !bindJSON(c, &quiz) {
 					return
 				}
-				
-				id, err := quizRepo.Create(quiz)
+
+				id, err := quizRepo.Create(c.Request.Context(), quiz)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusCreated, gin.H{"id": id})
 			})
-			
+
+			// Create a quiz with all of its questions and answers atomically
+			quizzes.POST("/full", func(c *gin.Context) {
+				var draft models.QuizDraft
+				if !bindJSON(c, &draft) {
+					return
+				}
+
+				created, err := quizRepo.CreateFull(c.Request.Context(), draft)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusCreated, created)
+			})
+
+			// PUT replaces the whole quiz; PATCH applies a JSON Merge Patch
 			quizzes.PUT("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				var data map[string]interface{}
-				if err := c.ShouldBindJSON(&data); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				var data models.QuizInput
+				if !bindJSON(c, &data) {
 					return
 				}
-				
-				err := quizRepo.Update(id, data)
+
+				err := quizRepo.Replace(c.Request.Context(), id, data)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, gin.H{"message": "Quiz updated successfully"})
+			})
+
+			quizzes.PATCH("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				var patch models.QuizPatch
+				if !bindPatch(c, &patch) {
+					return
+				}
+
+				err := quizRepo.Patch(c.Request.Context(), id, patch)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Quiz updated successfully"})
 			})
-			
+
 			quizzes.DELETE("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				err := quizRepo.Delete(id)
+				err := quizRepo.Delete(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Quiz deleted successfully"})
 			})
-			
-			// Questions related to a quiz
+
+			// Questions related to a quiz, optionally filtered by a tag
+			// expression
 			quizzes.GET("/:id/questions", func(c *gin.Context) {
 				id := c.Param("id")
-				questions, err := questionRepo.GetByQuizID(id)
+				var query models.QuestionQuery
+				if !bindQuery(c, &query) {
+					return
+				}
+
+				questions, err := questionRepo.GetByQuizID(c.Request.Context(), id, query)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, questions)
 			})
-			
+
 			quizzes.POST("/:id/questions", func(c *gin.Context) {
-				quizID := c.Param("id")
-				var data map[string]interface{}
-				if err := c.ShouldBindJSON(&data); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				quizID, ok := pathID(c, "id")
+				if !ok {
 					return
 				}
-				
-				// Make sure the quiz ID is included
-				data["quiz_id"] = quizID
-				
-				id, err := questionRepo.Create(data)
+
+				var data models.QuestionInput
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				// The quiz always comes from the path, never the body
+				data.QuizID = quizID
+
+				id, err := questionRepo.Create(c.Request.Context(), data)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusCreated, gin.H{"id": id})
 			})
+
+			// Random questions added to every attempt from question banks
+			quizzes.GET("/:id/draw-rules", func(c *gin.Context) {
+				rules, err := quizRepo.GetDrawRules(c.Request.Context(), c.Param("id"))
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, rules)
+			})
+
+			quizzes.PUT("/:id/draw-rules", func(c *gin.Context) {
+				var data drawRulesRequest
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				rules, err := quizRepo.ReplaceDrawRules(c.Request.Context(), c.Param("id"), data.Rules)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, rules)
+			})
+
+			// Attempts at a quiz
+			registerAttemptRoutes(quizzes, attemptRepo)
+
+			// Adaptive delivery, one question at a time
+			registerAdaptiveRoutes(quizzes, repos.Adaptive, attemptRepo)
 		}
 
+		// Question banks that quizzes draw from
+		registerBankRoutes(api, repos.Banks)
+
+		// Tags on quizzes and questions
+		registerTagRoutes(api, repos.Tags)
+
+		// Learning objectives and mastery reports
+		registerObjectiveRoutes(api, repos.Objectives)
+
+		// Item analysis of questions from submitted attempts
+		registerStatsRoutes(api, repos.Stats)
+
+		// Spaced-repetition reviews due for each learner
+		registerReviewRoutes(api, repos.Reviews)
+
 		// Questions endpoints
 		questions := api.Group("/questions")
 		{
 			questions.GET("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				question, err := questionRepo.GetByID(id)
+				question, err := questionRepo.GetByID(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+					respondError(c, err)
 					return
 				}
-				
+
 				// Get answers for this question
-				answers, err := answerRepo.GetByQuestionID(id)
+				answers, err := answerRepo.GetByQuestionID(c.Request.Context(), id)
 				if err == nil {
 					c.JSON(http.StatusOK, gin.H{
 						"question": question,
-						"answers": answers,
+						"answers":  answers,
 					})
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, question)
 			})
-			
+
 			questions.PUT("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				var data map[string]interface{}
-				if err := c.ShouldBindJSON(&data); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				var data models.QuestionInput
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				err := questionRepo.Replace(c.Request.Context(), id, data)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, gin.H{"message": "Question updated successfully"})
+			})
+
+			questions.PATCH("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				var patch models.QuestionPatch
+				if !bindPatch(c, &patch) {
 					return
 				}
-				
-				err := questionRepo.Update(id, data)
+
+				err := questionRepo.Patch(c.Request.Context(), id, patch)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Question updated successfully"})
 			})
-			
+
 			questions.DELETE("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				err := questionRepo.Delete(id)
+				err := questionRepo.Delete(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Question deleted successfully"})
 			})
-			
+
+			// Hints for a question, edited as a whole list
+			questions.GET("/:id/hints", func(c *gin.Context) {
+				hints, err := questionRepo.GetHints(c.Request.Context(), c.Param("id"))
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, hints)
+			})
+
+			questions.PUT("/:id/hints", func(c *gin.Context) {
+				var data hintsRequest
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				hints, err := questionRepo.ReplaceHints(c.Request.Context(), c.Param("id"), data.Hints)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, hints)
+			})
+
 			// Answers for a question
 			questions.GET("/:id/answers", func(c *gin.Context) {
 				id := c.Param("id")
-				answers, err := answerRepo.GetByQuestionID(id)
+				answers, err := answerRepo.GetByQuestionID(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, answers)
 			})
-			
+
 			questions.POST("/:id/answers", func(c *gin.Context) {
-				questionID := c.Param("id")
-				var data map[string]interface{}
-				if err := c.ShouldBindJSON(&data); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				questionID, ok := pathID(c, "id")
+				if !ok {
 					return
 				}
-				
-				// Make sure the question ID is included
-				data["question_id"] = questionID
-				
-				id, err := answerRepo.Create(data)
+
+				var data models.AnswerInput
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				// The question always comes from the path, never the body
+				data.QuestionID = questionID
+
+				id, err := answerRepo.Create(c.Request.Context(), data)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusCreated, gin.H{"id": id})
 			})
 		}
@@ -230,40 +393,55 @@ func setupRouter(database *sqlx.DB) *gin.Engine {
 		{
 			answers.GET("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				answer, err := answerRepo.GetByID(id)
+				answer, err := answerRepo.GetByID(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, answer)
 			})
-			
+
 			answers.PUT("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				var data map[string]interface{}
-				if err := c.ShouldBindJSON(&data); err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				var data models.AnswerInput
+				if !bindJSON(c, &data) {
+					return
+				}
+
+				err := answerRepo.Replace(c.Request.Context(), id, data)
+				if err != nil {
+					respondError(c, err)
+					return
+				}
+
+				c.JSON(http.StatusOK, gin.H{"message": "Answer updated successfully"})
+			})
+
+			answers.PATCH("/:id", func(c *gin.Context) {
+				id := c.Param("id")
+				var patch models.AnswerPatch
+				if !bindPatch(c, &patch) {
 					return
 				}
-				
-				err := answerRepo.Update(id, data)
+
+				err := answerRepo.Patch(c.Request.Context(), id, patch)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Answer updated successfully"})
 			})
-			
+
 			answers.DELETE("/:id", func(c *gin.Context) {
 				id := c.Param("id")
-				err := answerRepo.Delete(id)
+				err := answerRepo.Delete(c.Request.Context(), id)
 				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 					return
 				}
-				
+
 				c.JSON(http.StatusOK, gin.H{"message": "Answer deleted successfully"})
 			})
 		}
@@ -278,7 +456,7 @@ func main() {
 	defer database.Close()
 
 	// Setup router with database
-	router := setupRouter(database)
+	router := setupRouter(repository.NewRepositories(database, repository.WithQueryTimeout(db.QueryTimeout())))
 
 	// Start the server
 	log.Println("Server is running on port 8080 with Gin")
@@ -0,0 +1,1288 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/repository/memory"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return setupRouter(memory.NewRepositories())
+}
+
+// do sends a request to the router and decodes the JSON response into out,
+// when out is not nil.
+func do(t *testing.T, router *gin.Engine, method, path, body string, out interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+func expectStatus(t *testing.T, got, want int, what string) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("%s: status = %d, want %d", what, got, want)
+	}
+}
+
+const sampleQuiz = `{
+	"title": "Capitals",
+	"description": "European capitals",
+	"questions": [
+		{"question": "Capital of France?", "type": "multiple_choice", "answers": [
+			{"answer": "Paris", "is_correct": true},
+			{"answer": "Lyon"}
+		]},
+		{"question": "Berlin is in Germany", "type": "true_false", "answers": [
+			{"answer": "True", "is_correct": true},
+			{"answer": "False"}
+		]}
+	]
+}`
+
+type createdQuiz struct {
+	ID        int64 `json:"id"`
+	Questions []struct {
+		ID        int64   `json:"id"`
+		AnswerIDs []int64 `json:"answer_ids"`
+	} `json:"questions"`
+}
+
+func createSampleQuiz(t *testing.T, router *gin.Engine) createdQuiz {
+	t.Helper()
+	var created createdQuiz
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", sampleQuiz, &created), http.StatusCreated, "create full quiz")
+	return created
+}
+
+func TestQuizCRUD(t *testing.T) {
+	router := newTestRouter()
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes", `{"title": "Go", "description": "Basics"}`, &created), http.StatusCreated, "create")
+
+	path := "/api/quizzes/1"
+	expectStatus(t, do(t, router, http.MethodPatch, path, `{"description": null}`, nil), http.StatusOK, "patch")
+
+	var got struct {
+		Quiz struct {
+			Title       string  `json:"title"`
+			Description *string `json:"description"`
+		} `json:"quiz"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, path, "", &got), http.StatusOK, "get")
+	if got.Quiz.Title != "Go" || got.Quiz.Description != nil {
+		t.Errorf("after patch got %+v, want title Go and no description", got.Quiz)
+	}
+
+	expectStatus(t, do(t, router, http.MethodPut, path, `{"title": "Go 2"}`, nil), http.StatusOK, "put")
+	expectStatus(t, do(t, router, http.MethodDelete, path, "", nil), http.StatusOK, "delete")
+	expectStatus(t, do(t, router, http.MethodGet, path, "", nil), http.StatusNotFound, "get after delete")
+}
+
+func TestFullQuizAndPlayerView(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+
+	var full struct {
+		Questions []struct {
+			Order   int `json:"order_num"`
+			Answers []struct {
+				Correct bool `json:"is_correct"`
+			} `json:"answers"`
+		} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/1/full", "", &full), http.StatusOK, "get full")
+	if len(full.Questions) != 2 || full.Questions[0].Order != 1 || full.Questions[1].Order != 2 {
+		t.Fatalf("unexpected questions: %+v", full.Questions)
+	}
+	if !full.Questions[0].Answers[0].Correct {
+		t.Error("admin view should include is_correct")
+	}
+
+	var play map[string]interface{}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/1/play", "", &play), http.StatusOK, "get play")
+	raw, _ := json.Marshal(play)
+	if strings.Contains(string(raw), "is_correct") {
+		t.Errorf("player view leaks correctness: %s", raw)
+	}
+
+	// Deleting a question takes its answers with it
+	questionPath := "/api/questions/" + itoa(created.Questions[0].ID)
+	expectStatus(t, do(t, router, http.MethodDelete, questionPath, "", nil), http.StatusOK, "delete question")
+	expectStatus(t, do(t, router, http.MethodGet, "/api/answers/"+itoa(created.Questions[0].AnswerIDs[0]), "", nil), http.StatusNotFound, "get cascaded answer")
+}
+
+func TestAttemptScoring(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+
+	var attempt struct {
+		ID int `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts", `{"user_id": "ada"}`, &attempt), http.StatusCreated, "start attempt")
+
+	attemptPath := "/api/quizzes/1/attempts/" + itoa(int64(attempt.ID))
+	first := created.Questions[0]
+	expectStatus(t, do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(first.ID),
+		`{"answer_ids": [`+itoa(first.AnswerIDs[0])+`]}`, nil), http.StatusOK, "record response")
+
+	second := created.Questions[1]
+	var submitted struct {
+		Status   string  `json:"status"`
+		Score    float64 `json:"score"`
+		MaxScore float64 `json:"max_score"`
+	}
+	body := `{"responses": [{"question_id": ` + itoa(second.ID) + `, "answer_ids": [` + itoa(second.AnswerIDs[1]) + `]}]}`
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", body, &submitted), http.StatusOK, "submit")
+	if submitted.Status != "submitted" || submitted.Score != 1 || submitted.MaxScore != 2 {
+		t.Errorf("submit got %+v, want submitted with 1/2", submitted)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	router := newTestRouter()
+
+	var resp struct {
+		Fields []struct {
+			Field string `json:"field"`
+		} `json:"fields"`
+	}
+	body := `{"title": "", "questions": [{"question": "Q", "type": "essay"}]}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", body, &resp), http.StatusBadRequest, "invalid draft")
+
+	var fields []string
+	for _, f := range resp.Fields {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "title,questions[0].type" {
+		t.Errorf("fields = %s, want title,questions[0].type", got)
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func TestErrorResponses(t *testing.T) {
+	router := newTestRouter()
+	createSampleQuiz(t, router)
+
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts", "", nil), http.StatusCreated, "start attempt")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts/1/submit", "", nil), http.StatusOK, "submit")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts", "", nil), http.StatusCreated, "start second attempt")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"malformed id", http.MethodGet, "/api/quizzes/abc", "", http.StatusBadRequest},
+		{"missing quiz", http.MethodGet, "/api/quizzes/99/full", "", http.StatusNotFound},
+		{"replace missing quiz", http.MethodPut, "/api/quizzes/99", `{"title": "Gone"}`, http.StatusNotFound},
+		{"delete missing answer", http.MethodDelete, "/api/answers/99", "", http.StatusNotFound},
+		{"question for missing quiz", http.MethodPost, "/api/quizzes/99/questions", `{"question": "Q", "type": "true_false"}`, http.StatusUnprocessableEntity},
+		{"empty patch", http.MethodPatch, "/api/quizzes/1", `{}`, http.StatusUnprocessableEntity},
+		{"respond after submit", http.MethodPut, "/api/quizzes/1/attempts/1/responses/1", `{"answer_ids": []}`, http.StatusConflict},
+		{"question from another quiz", http.MethodPut, "/api/quizzes/1/attempts/2/responses/99", `{"answer_ids": []}`, http.StatusUnprocessableEntity},
+		{"submit twice", http.MethodPost, "/api/quizzes/1/attempts/1/submit", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/problem+json") {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+
+			var body struct {
+				Title  string `json:"title"`
+				Status int    `json:"status"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding %q: %v", w.Body.String(), err)
+			}
+			if body.Status != tt.status || body.Title != http.StatusText(tt.status) {
+				t.Errorf("problem = %+v, want status %d", body, tt.status)
+			}
+		})
+	}
+}
+
+func TestQuizListing(t *testing.T) {
+	router := newTestRouter()
+	for _, title := range []string{"Rust", "Go", "Python", "Haskell", "Go generics"} {
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes", `{"title": "`+title+`"}`, nil), http.StatusCreated, "create "+title)
+	}
+
+	type page struct {
+		Data []struct {
+			Title string `json:"title"`
+		} `json:"data"`
+		NextCursor *string `json:"next_cursor"`
+		Total      *int    `json:"total"`
+	}
+
+	// Walk every page following the Link header
+	var titles []string
+	path := "/api/quizzes?sort=title&limit=2&include_total=true"
+	for pages := 0; path != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		expectStatus(t, w.Code, http.StatusOK, "list "+path)
+
+		var got page
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decoding %q: %v", w.Body.String(), err)
+		}
+		if got.Total == nil || *got.Total != 5 {
+			t.Errorf("total = %v, want 5", got.Total)
+		}
+		for _, quiz := range got.Data {
+			titles = append(titles, quiz.Title)
+		}
+
+		path = ""
+		if link := w.Header().Get("Link"); link != "" {
+			path = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		} else if got.NextCursor != nil {
+			t.Error("next_cursor set without a Link header")
+		}
+	}
+	if got := strings.Join(titles, ","); got != "Go,Go generics,Haskell,Python,Rust" {
+		t.Errorf("titles = %s", got)
+	}
+
+	var filtered page
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes?title=GO&sort=-id", "", &filtered), http.StatusOK, "filter")
+	if len(filtered.Data) != 2 || filtered.Data[0].Title != "Go generics" || filtered.Total != nil {
+		t.Errorf("filtered = %+v", filtered)
+	}
+
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes?sort=score", "", nil), http.StatusBadRequest, "unknown sort")
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes?cursor=bogus", "", nil), http.StatusUnprocessableEntity, "bad cursor")
+}
+
+func TestSearch(t *testing.T) {
+	router := newTestRouter()
+	draft := `{"title": "WCAG 2.2", "questions": [
+		{"question": "Which criterion is 1.4.13 Content on Hover?", "type": "true_false", "answers": [
+			{"answer": "Reflow"}, {"answer": "Content on Hover or Focus", "is_correct": true}
+		]}
+	]}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", draft, nil), http.StatusCreated, "create quiz")
+
+	var results struct {
+		Data []struct {
+			Kind       string `json:"kind"`
+			QuizID     *int   `json:"quiz_id"`
+			QuizTitle  string `json:"quiz_title"`
+			BankID     *int   `json:"bank_id"`
+			QuestionID *int   `json:"question_id"`
+			Snippet    string `json:"snippet"`
+		} `json:"data"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/search?q=1.4.13", "", &results), http.StatusOK, "search")
+	if len(results.Data) != 1 {
+		t.Fatalf("expected one hit, got %+v", results.Data)
+	}
+	hit := results.Data[0]
+	if hit.Kind != "question" || hit.QuizTitle != "WCAG 2.2" || hit.QuestionID == nil {
+		t.Errorf("unexpected hit: %+v", hit)
+	}
+	if !strings.Contains(hit.Snippet, "<mark>1.4.13</mark>") {
+		t.Errorf("snippet %q does not highlight the match", hit.Snippet)
+	}
+
+	// Authored text comes back escaped, with only the highlighting as markup
+	draft = `{"title": "<script>alert(1)</script> Tooltips & popovers"}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", draft, nil), http.StatusCreated, "create quiz")
+	expectStatus(t, do(t, router, http.MethodGet, "/api/search?q=popovers", "", &results), http.StatusOK, "search")
+	want := "&lt;script&gt;alert(1)&lt;/script&gt; Tooltips &amp; <mark>popovers</mark>"
+	if len(results.Data) != 1 || results.Data[0].Snippet != want {
+		t.Errorf("hits = %+v, want one with snippet %q", results.Data, want)
+	}
+
+	// Bank questions belong to no quiz
+	expectStatus(t, do(t, router, http.MethodPost, "/api/banks", `{"title": "WCAG 2.2 pool"}`, nil), http.StatusCreated, "create bank")
+	question := `{"question": "Which criterion is 2.5.8 Target Size?", "type": "true_false", "answers": [
+		{"answer": "Yes", "is_correct": true}, {"answer": "No"}
+	]}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/banks/1/questions", question, nil), http.StatusCreated, "create bank question")
+	expectStatus(t, do(t, router, http.MethodGet, "/api/search?q=2.5.8", "", &results), http.StatusOK, "search")
+	if len(results.Data) != 1 || results.Data[0].QuizID != nil || results.Data[0].BankID == nil || *results.Data[0].BankID != 1 {
+		t.Errorf("hits = %+v, want the bank question", results.Data)
+	}
+
+	expectStatus(t, do(t, router, http.MethodGet, "/api/search", "", nil), http.StatusBadRequest, "missing q")
+}
+
+func TestQuestionTypeRules(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+	choice, trueFalse := created.Questions[0], created.Questions[1]
+
+	expectStatus(t, do(t, router, http.MethodPost, "/api/questions/"+itoa(choice.ID)+"/answers",
+		`{"answer": "Marseille", "is_correct": true}`, nil), http.StatusUnprocessableEntity, "second correct multiple_choice answer")
+	expectStatus(t, do(t, router, http.MethodPatch, "/api/answers/"+itoa(choice.AnswerIDs[1]),
+		`{"is_correct": true}`, nil), http.StatusUnprocessableEntity, "marking a second answer correct")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/questions/"+itoa(trueFalse.ID)+"/answers",
+		`{"answer": "Maybe"}`, nil), http.StatusUnprocessableEntity, "third true_false answer")
+	expectStatus(t, do(t, router, http.MethodPatch, "/api/questions/"+itoa(choice.ID),
+		`{"scoring": "partial"}`, nil), http.StatusUnprocessableEntity, "partial scoring on multiple_choice")
+
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full",
+		`{"title": "Short", "questions": [{"question": "Pick one", "type": "true_false", "answers": [{"answer": "Yes", "is_correct": true}]}]}`, nil),
+		http.StatusUnprocessableEntity, "true_false with one answer")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/questions",
+		`{"question": "Pick", "type": "multiple_select", "scoring": "lenient"}`, nil),
+		http.StatusBadRequest, "unknown scoring strategy")
+}
+
+func TestMultipleSelectScoring(t *testing.T) {
+	tests := []struct {
+		scoring string
+		picks   []int
+		want    float64
+	}{
+		{"all_or_nothing", []int{0, 1}, 1},
+		{"all_or_nothing", []int{0}, 0},
+		{"partial", []int{0}, 0.75},
+		{"partial", []int{0, 2}, 0.5},
+		{"penalty", []int{0}, 0.5},
+		{"penalty", []int{0, 2}, 0},
+		{"penalty", []int{0, 1, 2}, 0.5},
+	}
+
+	for _, tt := range tests {
+		router := newTestRouter()
+
+		var created createdQuiz
+		body := `{"title": "Colours", "questions": [{"question": "Which are primary colours?", "type": "multiple_select", "scoring": "` + tt.scoring + `", "answers": [
+			{"answer": "Red", "is_correct": true},
+			{"answer": "Blue", "is_correct": true},
+			{"answer": "Green"},
+			{"answer": "Orange"}
+		]}]}`
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", body, &created), http.StatusCreated, "create multiple_select quiz")
+		question := created.Questions[0]
+
+		var attempt struct {
+			ID int `json:"id"`
+		}
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts", `{}`, &attempt), http.StatusCreated, "start attempt")
+
+		var picks []string
+		for _, i := range tt.picks {
+			picks = append(picks, itoa(question.AnswerIDs[i]))
+		}
+		var submitted struct {
+			Score float64 `json:"score"`
+		}
+		body = `{"responses": [{"question_id": ` + itoa(question.ID) + `, "answer_ids": [` + strings.Join(picks, ", ") + `]}]}`
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/1/attempts/"+itoa(int64(attempt.ID))+"/submit", body, &submitted), http.StatusOK, "submit")
+		if submitted.Score != tt.want {
+			t.Errorf("%s with picks %v scored %v, want %v", tt.scoring, tt.picks, submitted.Score, tt.want)
+		}
+	}
+}
+
+const textQuiz = `{
+	"title": "Physics",
+	"questions": [
+		{"question": "Who proposed relativity?", "type": "short_answer",
+			"config": {"accepted": ["Albert Einstein", "Einstein"]}},
+		{"question": "Acceleration due to gravity?", "type": "numeric",
+			"config": {"value": 9.81, "tolerance": 0.05, "unit": "m/s2"}},
+		{"question": "Force is ___ times ___", "type": "fill_in_blank", "scoring": "partial",
+			"config": {"blanks": [{"accepted": ["mass"]}, {"pattern": "^accel(eration)?$"}]}}
+	]
+}`
+
+func TestTextQuestions(t *testing.T) {
+	router := newTestRouter()
+	var created createdQuiz
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", textQuiz, &created), http.StatusCreated, "create text quiz")
+
+	var player struct {
+		Questions []map[string]interface{} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/"+itoa(created.ID)+"/play", "", &player), http.StatusOK, "player view")
+	for _, question := range player.Questions {
+		if _, leaked := question["config"]; leaked {
+			t.Errorf("player view leaks config: %v", question)
+		}
+	}
+	if player.Questions[2]["blanks"] != float64(2) || player.Questions[1]["unit"] != "m/s2" {
+		t.Errorf("player view lacks blanks or unit: %v", player.Questions)
+	}
+
+	var attempt struct {
+		ID int `json:"id"`
+	}
+	quizPath := "/api/quizzes/" + itoa(created.ID)
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", `{}`, &attempt), http.StatusCreated, "start attempt")
+	attemptPath := quizPath + "/attempts/" + itoa(int64(attempt.ID))
+
+	shortAnswer, numeric, blanks := created.Questions[0], created.Questions[1], created.Questions[2]
+	expectStatus(t, do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(shortAnswer.ID),
+		`{"answer_ids": [1]}`, nil), http.StatusUnprocessableEntity, "answer IDs for a short_answer question")
+	expectStatus(t, do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(blanks.ID),
+		`{"text": ["mass"]}`, nil), http.StatusUnprocessableEntity, "too few blanks")
+
+	var submitted struct {
+		Score     float64 `json:"score"`
+		Responses []struct {
+			Points float64 `json:"points"`
+		} `json:"responses"`
+	}
+	body := `{"responses": [
+		{"question_id": ` + itoa(shortAnswer.ID) + `, "text": ["  albert   EINSTEIN "]},
+		{"question_id": ` + itoa(numeric.ID) + `, "text": ["9.8 m/s2"]},
+		{"question_id": ` + itoa(blanks.ID) + `, "text": ["Mass", "velocity"]}
+	]}`
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", body, &submitted), http.StatusOK, "submit")
+	if submitted.Score != 2.5 {
+		t.Errorf("score = %v, want 2.5 (responses %+v)", submitted.Score, submitted.Responses)
+	}
+}
+
+func TestTextQuestionValidation(t *testing.T) {
+	router := newTestRouter()
+	createSampleQuiz(t, router)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"short_answer with answers", `{"question": "Q", "type": "short_answer", "config": {"accepted": ["A"]}, "answers": [{"answer": "A", "is_correct": true}]}`},
+		{"short_answer without config", `{"question": "Q", "type": "short_answer"}`},
+		{"numeric with value and range", `{"question": "Q", "type": "numeric", "config": {"value": 1, "min": 0, "max": 2}}`},
+		{"blanks not matching the text", `{"question": "One ___ here", "type": "fill_in_blank", "config": {"blanks": [{"accepted": ["a"]}, {"accepted": ["b"]}]}}`},
+		{"invalid pattern", `{"question": "Q", "type": "short_answer", "config": {"pattern": "("}}`},
+		{"pattern escaping its anchors", `{"question": "Q", "type": "short_answer", "config": {"pattern": "a)|(b"}}`},
+		{"config on multiple_choice", `{"question": "Q", "type": "multiple_choice", "config": {"accepted": ["A"]}}`},
+	}
+
+	for _, tt := range tests {
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", `{"title": "T", "questions": [`+tt.body+`]}`, nil),
+			http.StatusUnprocessableEntity, tt.name)
+	}
+}
+
+const arrangeQuiz = `{
+	"title": "Biology",
+	"questions": [
+		{"question": "Order the stages of mitosis", "type": "ordering", "scoring": "partial", "answers": [
+			{"answer": "Prophase", "position": 1},
+			{"answer": "Metaphase", "position": 2},
+			{"answer": "Anaphase", "position": 3},
+			{"answer": "Telophase", "position": 4}
+		]},
+		{"question": "Match each organelle to its role", "type": "matching", "answers": [
+			{"answer": "Mitochondria", "match": "Energy"},
+			{"answer": "Ribosome", "match": "Protein synthesis"}
+		]}
+	]
+}`
+
+func TestOrderingAndMatchingQuestions(t *testing.T) {
+	router := newTestRouter()
+	var created createdQuiz
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", arrangeQuiz, &created), http.StatusCreated, "create quiz")
+	ordering, matching := created.Questions[0], created.Questions[1]
+	quizPath := "/api/quizzes/" + itoa(created.ID)
+
+	var player struct {
+		Questions []struct {
+			Answers []map[string]interface{} `json:"answers"`
+			Matches []string                 `json:"matches"`
+		} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/play", "", &player), http.StatusOK, "player view")
+	for _, question := range player.Questions {
+		for _, answer := range question.Answers {
+			if _, leaked := answer["position"]; leaked {
+				t.Errorf("player view leaks position: %v", answer)
+			}
+			if _, leaked := answer["match"]; leaked {
+				t.Errorf("player view leaks match: %v", answer)
+			}
+		}
+	}
+	if len(player.Questions[1].Matches) != 2 {
+		t.Errorf("matches = %v, want both definitions", player.Questions[1].Matches)
+	}
+
+	// Sorting the steps by ID must not give the solution away
+	var full struct {
+		Questions []struct {
+			Answers []struct {
+				ID       int64 `json:"id"`
+				Position int   `json:"position"`
+			} `json:"answers"`
+		} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/full", "", &full), http.StatusOK, "full quiz")
+	positions := map[int64]int{}
+	for _, answer := range full.Questions[0].Answers {
+		positions[answer.ID] = answer.Position
+	}
+	var stepIDs []int64
+	for _, answer := range player.Questions[0].Answers {
+		stepIDs = append(stepIDs, int64(answer["id"].(float64)))
+	}
+	sort.Slice(stepIDs, func(i, j int) bool { return stepIDs[i] < stepIDs[j] })
+	var byID []int
+	for _, id := range stepIDs {
+		byID = append(byID, positions[id])
+	}
+	if len(byID) != 4 || sort.IntsAreSorted(byID) {
+		t.Errorf("positions of the steps sorted by ID = %v, want them out of order", byID)
+	}
+
+	var attempt struct {
+		ID int `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", `{}`, &attempt), http.StatusCreated, "start attempt")
+	attemptPath := quizPath + "/attempts/" + itoa(int64(attempt.ID))
+
+	expectStatus(t, do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(matching.ID),
+		`{"answer_ids": [`+itoa(matching.AnswerIDs[0])+`], "text": ["Energy", "Protein synthesis"]}`, nil),
+		http.StatusUnprocessableEntity, "more matches than answers")
+
+	// Prophase and Telophase are in place, the middle two are swapped
+	ids := ordering.AnswerIDs
+	order := itoa(ids[0]) + ", " + itoa(ids[2]) + ", " + itoa(ids[1]) + ", " + itoa(ids[3])
+	var submitted struct {
+		Score float64 `json:"score"`
+	}
+	body := `{"responses": [
+		{"question_id": ` + itoa(ordering.ID) + `, "answer_ids": [` + order + `]},
+		{"question_id": ` + itoa(matching.ID) + `, "answer_ids": [` + itoa(matching.AnswerIDs[1]) + `, ` + itoa(matching.AnswerIDs[0]) + `],
+			"text": ["protein  synthesis", "Energy"]}
+	]}`
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", body, &submitted), http.StatusOK, "submit")
+	if submitted.Score != 1.5 {
+		t.Errorf("score = %v, want 1.5", submitted.Score)
+	}
+
+	expectStatus(t, do(t, router, http.MethodPatch, "/api/answers/"+itoa(ordering.AnswerIDs[0]), `{"position": null}`, nil),
+		http.StatusUnprocessableEntity, "clearing an ordering position")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", `{"title": "T", "questions": [
+		{"question": "Q", "type": "ordering", "answers": [{"answer": "A", "position": 1}, {"answer": "B", "position": 3}]}]}`, nil),
+		http.StatusUnprocessableEntity, "gap in positions")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", `{"title": "T", "questions": [
+		{"question": "Q", "type": "matching", "answers": [{"answer": "A", "match": "x", "is_correct": true}, {"answer": "B", "match": "y"}]}]}`, nil),
+		http.StatusUnprocessableEntity, "matching answer marked correct")
+}
+
+const hintedQuiz = `{
+	"title": "Chemistry",
+	"questions": [
+		{"question": "Symbol for gold?", "type": "multiple_choice",
+			"explanation": "From the Latin aurum.",
+			"answers": [
+				{"answer": "Au", "is_correct": true, "rationale": "Aurum"},
+				{"answer": "Ag", "rationale": "That is silver"}
+			],
+			"hints": [
+				{"hint": "Think Latin", "penalty": 0.25},
+				{"hint": "It starts with A", "penalty": 0.5}
+			]}
+	]
+}`
+
+func TestHintsAndFeedback(t *testing.T) {
+	router := newTestRouter()
+	var created createdQuiz
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/full", hintedQuiz, &created), http.StatusCreated, "create quiz")
+	question := created.Questions[0]
+	quizPath := "/api/quizzes/" + itoa(created.ID)
+
+	var player struct {
+		Questions []map[string]interface{} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/play", "", &player), http.StatusOK, "player view")
+	if player.Questions[0]["hints"] != float64(2) {
+		t.Errorf("player hints = %v, want a count of 2", player.Questions[0]["hints"])
+	}
+	if _, leaked := player.Questions[0]["explanation"]; leaked {
+		t.Error("player view leaks the explanation")
+	}
+
+	var attempt struct {
+		ID int `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", `{}`, &attempt), http.StatusCreated, "start attempt")
+	attemptPath := quizPath + "/attempts/" + itoa(int64(attempt.ID))
+	hintPath := attemptPath + "/hints/" + itoa(question.ID)
+
+	var hint struct {
+		Tier      int    `json:"tier"`
+		Hint      string `json:"hint"`
+		Remaining int    `json:"remaining"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, hintPath, "", &hint), http.StatusOK, "first hint")
+	if hint.Tier != 1 || hint.Hint != "Think Latin" || hint.Remaining != 1 {
+		t.Errorf("first hint = %+v", hint)
+	}
+
+	var inProgress struct {
+		Responses []map[string]interface{} `json:"responses"`
+	}
+	expectStatus(t, do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(question.ID),
+		`{"answer_ids": [`+itoa(question.AnswerIDs[0])+`]}`, nil), http.StatusOK, "respond")
+	expectStatus(t, do(t, router, http.MethodGet, attemptPath, "", &inProgress), http.StatusOK, "get attempt")
+	if _, leaked := inProgress.Responses[0]["feedback"]; leaked {
+		t.Error("feedback shown before the attempt is submitted")
+	}
+
+	var submitted struct {
+		Score     float64 `json:"score"`
+		Responses []struct {
+			IsCorrect bool    `json:"is_correct"`
+			Points    float64 `json:"points"`
+			HintsUsed int     `json:"hints_used"`
+			Feedback  *struct {
+				Explanation string `json:"explanation"`
+				Answers     []struct {
+					Rationale string `json:"rationale"`
+				} `json:"answers"`
+			} `json:"feedback"`
+		} `json:"responses"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", "", &submitted), http.StatusOK, "submit")
+	response := submitted.Responses[0]
+	if submitted.Score != 0.75 || !response.IsCorrect || response.HintsUsed != 1 {
+		t.Errorf("score = %v, response = %+v, want 0.75 for a correct answer with one hint", submitted.Score, response)
+	}
+	if response.Feedback == nil || response.Feedback.Explanation != "From the Latin aurum." ||
+		len(response.Feedback.Answers) != 2 || response.Feedback.Answers[1].Rationale != "That is silver" {
+		t.Errorf("feedback = %+v", response.Feedback)
+	}
+	expectStatus(t, do(t, router, http.MethodPost, hintPath, "", nil), http.StatusConflict, "hint after submitting")
+
+	// A second attempt uses up both hints, which cost more than the point
+	expectStatus(t, do(t, router, http.MethodPut, "/api/questions/"+itoa(question.ID)+"/hints",
+		`{"hints": [{"hint": "Latin", "penalty": 0.75}, {"hint": "Au or Ag", "penalty": 0.5}]}`, nil), http.StatusOK, "replace hints")
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", `{}`, &attempt), http.StatusCreated, "start second attempt")
+	attemptPath = quizPath + "/attempts/" + itoa(int64(attempt.ID))
+	hintPath = attemptPath + "/hints/" + itoa(question.ID)
+	expectStatus(t, do(t, router, http.MethodPost, hintPath, "", nil), http.StatusOK, "first hint")
+	expectStatus(t, do(t, router, http.MethodPost, hintPath, "", nil), http.StatusOK, "second hint")
+	expectStatus(t, do(t, router, http.MethodPost, hintPath, "", nil), http.StatusConflict, "no hints left")
+	body := `{"responses": [{"question_id": ` + itoa(question.ID) + `, "answer_ids": [` + itoa(question.AnswerIDs[0]) + `]}]}`
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", body, &submitted), http.StatusOK, "submit second")
+	if submitted.Score != 0 {
+		t.Errorf("score = %v, want penalties to stop at 0", submitted.Score)
+	}
+
+	expectStatus(t, do(t, router, http.MethodPut, "/api/questions/"+itoa(question.ID)+"/hints",
+		`{"hints": [{"hint": "Too costly", "penalty": 2}]}`, nil), http.StatusBadRequest, "penalty above 1")
+}
+
+func TestQuestionBanks(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+	quizPath := "/api/quizzes/" + itoa(created.ID)
+
+	var bank struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, "/api/banks", `{"title": "Geography"}`, &bank), http.StatusCreated, "create bank")
+	bankPath := "/api/banks/" + itoa(bank.ID)
+
+	// correct maps each question of the attempt to its correct answer
+	correct := map[int64]int64{}
+	for _, question := range created.Questions {
+		correct[question.ID] = question.AnswerIDs[0]
+	}
+	for i, tag := range []string{"europe", "europe", "europe", "asia"} {
+		var question struct {
+			ID        int64   `json:"id"`
+			AnswerIDs []int64 `json:"answer_ids"`
+		}
+		body := `{"question": "Bank question ` + itoa(int64(i)) + `", "type": "true_false", "tags": ["` + tag + `", " maps "],
+			"answers": [{"answer": "True", "is_correct": true}, {"answer": "False"}]}`
+		expectStatus(t, do(t, router, http.MethodPost, bankPath+"/questions", body, &question), http.StatusCreated, "add bank question")
+		correct[question.ID] = question.AnswerIDs[0]
+	}
+
+	var questions []struct {
+		QuizID *int     `json:"quiz_id"`
+		Tags   []string `json:"tags"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, bankPath+"/questions", "", &questions), http.StatusOK, "bank questions")
+	if len(questions) != 4 || questions[0].QuizID != nil || len(questions[0].Tags) != 2 || questions[0].Tags[0] != "europe" || questions[0].Tags[1] != "maps" {
+		t.Fatalf("bank questions = %+v", questions)
+	}
+
+	rules := `{"rules": [{"bank_id": ` + itoa(bank.ID) + `, "count": 2, "tag": "europe"}]}`
+	expectStatus(t, do(t, router, http.MethodPut, quizPath+"/draw-rules", rules, nil), http.StatusOK, "set draw rules")
+	expectStatus(t, do(t, router, http.MethodPut, quizPath+"/draw-rules", `{"rules": [{"bank_id": 999, "count": 1}]}`, nil),
+		http.StatusUnprocessableEntity, "rule for a missing bank")
+
+	type attempt struct {
+		ID          int   `json:"id"`
+		Seed        int64 `json:"seed"`
+		QuestionIDs []int `json:"question_ids"`
+	}
+	var first, second attempt
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", `{"seed": 42}`, &first), http.StatusCreated, "start attempt")
+	if first.Seed == 42 || len(first.QuestionIDs) != 4 || int64(first.QuestionIDs[0]) != created.Questions[0].ID {
+		t.Fatalf("attempt = %+v, want the quiz's two questions then two drawn with a seed of the server's", first)
+	}
+
+	// Players cannot reuse a seed
+	body := `{"seed": ` + itoa(first.Seed) + `}`
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", body, &second), http.StatusCreated, "start attempt with its seed")
+	if second.Seed == first.Seed {
+		t.Errorf("seed %d taken from the request", second.Seed)
+	}
+
+	// The answers of an attempt stay hidden while it is in progress
+	replayPath := quizPath + "/attempts/" + itoa(int64(first.ID)) + "/replay"
+	expectStatus(t, do(t, router, http.MethodGet, replayPath, "", nil), http.StatusConflict, "replay attempt in progress")
+
+	attemptPath := quizPath + "/attempts/" + itoa(int64(first.ID))
+	var player []struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, attemptPath+"/play", "", &player), http.StatusOK, "play attempt")
+	if len(player) != 4 || int(player[3].ID) != first.QuestionIDs[3] {
+		t.Errorf("play = %+v, want the attempt's questions in order", player)
+	}
+
+	drawn := map[int64]bool{}
+	for _, id := range first.QuestionIDs {
+		drawn[int64(id)] = true
+	}
+	for questionID, answerID := range correct {
+		status := do(t, router, http.MethodPut, attemptPath+"/responses/"+itoa(questionID), `{"answer_ids": [`+itoa(answerID)+`]}`, nil)
+		if drawn[questionID] {
+			expectStatus(t, status, http.StatusOK, "respond to a drawn question")
+		} else {
+			expectStatus(t, status, http.StatusUnprocessableEntity, "respond to a question not drawn")
+		}
+	}
+
+	var submitted struct {
+		Score    float64 `json:"score"`
+		MaxScore float64 `json:"max_score"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, attemptPath+"/submit", "", &submitted), http.StatusOK, "submit")
+	if submitted.Score != 4 || submitted.MaxScore != 4 {
+		t.Errorf("score = %v/%v, want 4/4", submitted.Score, submitted.MaxScore)
+	}
+
+	// A replay only reads back what the attempt was given
+	var listed []attempt
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/attempts", "", &listed), http.StatusOK, "list attempts")
+	var replay struct {
+		Attempt   attempt `json:"attempt"`
+		Questions []struct {
+			ID      int64 `json:"id"`
+			Answers []struct {
+				IsCorrect bool `json:"is_correct"`
+			} `json:"answers"`
+		} `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, replayPath, "", nil), http.StatusNotFound, "replay cannot start an attempt")
+	expectStatus(t, do(t, router, http.MethodGet, replayPath, "", &replay), http.StatusOK, "replay attempt")
+	if replay.Attempt.Seed != first.Seed || !reflect.DeepEqual(first.QuestionIDs, replay.Attempt.QuestionIDs) || len(replay.Questions) != 4 {
+		t.Fatalf("replay = %+v, want the seed and questions of %+v", replay, first)
+	}
+	for i, question := range replay.Questions {
+		if int(question.ID) != first.QuestionIDs[i] || len(question.Answers) != 2 {
+			t.Errorf("replay question %d = %+v, want question %d with its answers", i, question, first.QuestionIDs[i])
+		}
+	}
+	var after []attempt
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/attempts", "", &after), http.StatusOK, "list attempts after replay")
+	if len(after) != len(listed) {
+		t.Errorf("replay started an attempt: %d attempts, want %d", len(after), len(listed))
+	}
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/attempts/999/replay", "", nil), http.StatusNotFound, "replay missing attempt")
+
+	// Only three questions are tagged europe
+	rules = `{"rules": [{"bank_id": ` + itoa(bank.ID) + `, "count": 4, "tag": "europe"}]}`
+	expectStatus(t, do(t, router, http.MethodPut, quizPath+"/draw-rules", rules, nil), http.StatusOK, "set draw rules")
+	expectStatus(t, do(t, router, http.MethodPost, quizPath+"/attempts", "", nil), http.StatusConflict, "bank too small")
+
+	expectStatus(t, do(t, router, http.MethodDelete, bankPath, "", nil), http.StatusOK, "delete bank")
+	var left []interface{}
+	expectStatus(t, do(t, router, http.MethodGet, quizPath+"/draw-rules", "", &left), http.StatusOK, "draw rules")
+	if len(left) != 0 {
+		t.Errorf("draw rules = %v, want them deleted with the bank", left)
+	}
+}
+
+func TestTags(t *testing.T) {
+	router := newTestRouter()
+	first := createSampleQuiz(t, router)
+	second := createSampleQuiz(t, router)
+
+	var standards, wcag struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, "/api/tags", `{"name": "standards"}`, &standards), http.StatusCreated, "create tag")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/tags", `{"name": "wcag", "parent_id": `+itoa(standards.ID)+`}`, &wcag),
+		http.StatusCreated, "create child tag")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/tags", `{"name": "wcag"}`, nil), http.StatusConflict, "duplicate tag")
+	expectStatus(t, do(t, router, http.MethodPut, "/api/tags/"+itoa(standards.ID), `{"name": "standards", "parent_id": `+itoa(wcag.ID)+`}`, nil),
+		http.StatusUnprocessableEntity, "tag beneath its own child")
+
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/"+itoa(first.ID)+"/tags", `{"tag": "wcag"}`, nil), http.StatusOK, "tag quiz")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/"+itoa(second.ID)+"/tags", `{"tag": "draft"}`, nil), http.StatusOK, "tag quiz with a new tag")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/"+itoa(second.ID)+"/tags", `{"tag": "a|b"}`, nil),
+		http.StatusUnprocessableEntity, "tag name with an operator")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/questions/"+itoa(first.Questions[1].ID)+"/tags", `{"tag": "wcag"}`, nil), http.StatusOK, "tag question")
+
+	// Filtering by a parent matches quizzes tagged with its children
+	tests := []struct {
+		tags string
+		want []int64
+	}{
+		{"standards", []int64{first.ID}},
+		{"standards|draft", []int64{first.ID, second.ID}},
+		{"!wcag", []int64{second.ID}},
+		{"standards,draft", nil},
+	}
+	for _, tt := range tests {
+		var page struct {
+			Data []struct {
+				ID int64 `json:"id"`
+			} `json:"data"`
+		}
+		expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes?tags="+url.QueryEscape(tt.tags), "", &page), http.StatusOK, "list "+tt.tags)
+		var got []int64
+		for _, quiz := range page.Data {
+			got = append(got, quiz.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("quizzes tagged %q = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes?tags=a,,b", "", nil), http.StatusUnprocessableEntity, "empty tag in expression")
+
+	var questions []struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/"+itoa(first.ID)+"/questions?tags=standards", "", &questions), http.StatusOK, "filter questions")
+	if len(questions) != 1 || questions[0].ID != first.Questions[1].ID {
+		t.Errorf("questions tagged standards = %+v, want only the tagged one", questions)
+	}
+
+	var usage []struct {
+		Name      string `json:"name"`
+		ParentID  *int64 `json:"parent_id"`
+		Quizzes   int    `json:"quizzes"`
+		Questions int    `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/tags", "", &usage), http.StatusOK, "list tags")
+	if len(usage) != 3 || usage[2].Name != "wcag" || usage[2].Quizzes != 1 || usage[2].Questions != 1 ||
+		usage[2].ParentID == nil || *usage[2].ParentID != standards.ID {
+		t.Errorf("tags = %+v", usage)
+	}
+
+	quizTags := "/api/quizzes/" + itoa(first.ID) + "/tags/"
+	expectStatus(t, do(t, router, http.MethodDelete, quizTags+itoa(wcag.ID), "", nil), http.StatusOK, "untag quiz")
+	expectStatus(t, do(t, router, http.MethodDelete, quizTags+itoa(wcag.ID), "", nil), http.StatusNotFound, "untag quiz again")
+
+	// Deleting a parent lifts its children to the top level
+	expectStatus(t, do(t, router, http.MethodDelete, "/api/tags/"+itoa(standards.ID), "", nil), http.StatusOK, "delete tag")
+	var child struct {
+		ParentID *int64 `json:"parent_id"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/tags/"+itoa(wcag.ID), "", &child), http.StatusOK, "get child tag")
+	if child.ParentID != nil {
+		t.Errorf("parent_id = %v, want none once the parent is deleted", *child.ParentID)
+	}
+}
+
+func TestObjectivesAndMastery(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+
+	var reflow, audio, other struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, "/api/objectives", `{"framework": "WCAG 2.2", "code": "1.4.10", "title": "Reflow"}`, &reflow),
+		http.StatusCreated, "create objective")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/objectives", `{"framework": "WCAG 2.2", "code": "1.4.2", "title": "Audio Control"}`, &audio),
+		http.StatusCreated, "create objective")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/objectives", `{"framework": "Geography", "code": "1", "title": "Capitals"}`, &other),
+		http.StatusCreated, "create objective in another framework")
+	expectStatus(t, do(t, router, http.MethodPost, "/api/objectives", `{"framework": "WCAG 2.2", "code": "1.4.2", "title": "Again"}`, nil),
+		http.StatusConflict, "duplicate objective code")
+
+	first, second := created.Questions[0], created.Questions[1]
+	expectStatus(t, do(t, router, http.MethodPut, "/api/questions/"+itoa(first.ID)+"/objectives",
+		`{"objective_ids": [`+itoa(reflow.ID)+`, `+itoa(other.ID)+`]}`, nil), http.StatusOK, "link objectives")
+	expectStatus(t, do(t, router, http.MethodPut, "/api/questions/"+itoa(second.ID)+"/objectives",
+		`{"objective_ids": [`+itoa(audio.ID)+`]}`, nil), http.StatusOK, "link objective")
+	expectStatus(t, do(t, router, http.MethodPut, "/api/questions/"+itoa(second.ID)+"/objectives", `{"objective_ids": [999]}`, nil),
+		http.StatusUnprocessableEntity, "link missing objective")
+
+	var listed []struct {
+		Code string `json:"code"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/objectives?framework="+url.QueryEscape("WCAG 2.2"), "", &listed), http.StatusOK, "list objectives")
+	if len(listed) != 2 || listed[0].Code != "1.4.2" || listed[1].Code != "1.4.10" {
+		t.Errorf("objectives = %+v, want 1.4.2 then 1.4.10", listed)
+	}
+
+	// Answer the first question right and the second wrong
+	var attempt struct {
+		ID int64 `json:"id"`
+	}
+	attempts := "/api/quizzes/" + itoa(created.ID) + "/attempts"
+	expectStatus(t, do(t, router, http.MethodPost, attempts, `{"user_id": "ada"}`, &attempt), http.StatusCreated, "start attempt")
+	body := `{"responses": [
+		{"question_id": ` + itoa(first.ID) + `, "answer_ids": [` + itoa(first.AnswerIDs[0]) + `]},
+		{"question_id": ` + itoa(second.ID) + `, "answer_ids": [` + itoa(second.AnswerIDs[1]) + `]}
+	]}`
+	expectStatus(t, do(t, router, http.MethodPost, attempts+"/"+itoa(attempt.ID)+"/submit", body, nil), http.StatusOK, "submit")
+
+	var report []struct {
+		Code  string  `json:"code"`
+		Score float64 `json:"score"`
+		Level string  `json:"level"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/users/ada/mastery?framework="+url.QueryEscape("WCAG 2.2"), "", &report),
+		http.StatusOK, "mastery")
+	want := []struct {
+		code  string
+		score float64
+		level string
+	}{{"1.4.2", 0, "weak"}, {"1.4.10", 1, "mastered"}}
+	if len(report) != len(want) {
+		t.Fatalf("mastery = %+v, want %d objectives", report, len(want))
+	}
+	for i, w := range want {
+		if report[i].Code != w.code || report[i].Score != w.score || report[i].Level != w.level {
+			t.Errorf("mastery[%d] = %+v, want %s at %v (%s)", i, report[i], w.code, w.score, w.level)
+		}
+	}
+
+	expectStatus(t, do(t, router, http.MethodGet, "/api/users/grace/mastery", "", &report), http.StatusOK, "mastery without attempts")
+	if len(report) != 0 {
+		t.Errorf("mastery for a user without attempts = %+v, want none", report)
+	}
+}
+
+func TestItemStats(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+	first, second := created.Questions[0], created.Questions[1]
+	attempts := "/api/quizzes/" + itoa(created.ID) + "/attempts"
+
+	// Right on both, wrong on both, then right on the first only while
+	// reporting the time spent on it across two responses
+	play := []struct{ first, second int }{{0, 0}, {1, 1}, {0, 1}}
+	for i, p := range play {
+		var attempt struct {
+			ID int64 `json:"id"`
+		}
+		expectStatus(t, do(t, router, http.MethodPost, attempts, `{}`, &attempt), http.StatusCreated, "start attempt")
+		path := attempts + "/" + itoa(attempt.ID)
+		if i == 2 {
+			for _, spent := range []string{"1000", "2000"} {
+				expectStatus(t, do(t, router, http.MethodPut, path+"/responses/"+itoa(first.ID),
+					`{"answer_ids": [`+itoa(first.AnswerIDs[p.first])+`], "time_spent_ms": `+spent+`}`, nil), http.StatusOK, "record response")
+			}
+		}
+		body := `{"responses": [
+			{"question_id": ` + itoa(first.ID) + `, "answer_ids": [` + itoa(first.AnswerIDs[p.first]) + `]},
+			{"question_id": ` + itoa(second.ID) + `, "answer_ids": [` + itoa(second.AnswerIDs[p.second]) + `]}
+		]}`
+		expectStatus(t, do(t, router, http.MethodPost, path+"/submit", body, nil), http.StatusOK, "submit")
+	}
+	// Attempts in progress are not counted
+	expectStatus(t, do(t, router, http.MethodPost, attempts, `{}`, nil), http.StatusCreated, "start attempt")
+
+	type itemStats struct {
+		Attempts      int      `json:"attempts"`
+		Correct       int      `json:"correct"`
+		PValue        *float64 `json:"p_value"`
+		Difficulty    *string  `json:"difficulty"`
+		PointBiserial *float64 `json:"point_biserial"`
+		AverageTimeMs *float64 `json:"average_time_ms"`
+		Answers       []struct {
+			Selections int      `json:"selections"`
+			Rate       *float64 `json:"rate"`
+		} `json:"answers"`
+	}
+	var stats itemStats
+	expectStatus(t, do(t, router, http.MethodGet, "/api/questions/"+itoa(first.ID)+"/stats", "", &stats), http.StatusOK, "question stats")
+	near := func(got *float64, want float64) bool { return got != nil && math.Abs(*got-want) < 1e-9 }
+	if stats.Attempts != 3 || stats.Correct != 2 || !near(stats.PValue, 2.0/3) ||
+		stats.Difficulty == nil || *stats.Difficulty != "medium" {
+		t.Errorf("question stats = %+v, want 2 of 3 correct", stats)
+	}
+	// Only the players who got the second question right too got this one right
+	if !near(stats.PointBiserial, 0.5) {
+		t.Errorf("point_biserial = %v, want 0.5", stats.PointBiserial)
+	}
+	if !near(stats.AverageTimeMs, 3000) {
+		t.Errorf("average_time_ms = %v, want 3000", stats.AverageTimeMs)
+	}
+	if len(stats.Answers) != 2 || stats.Answers[0].Selections != 2 || stats.Answers[1].Selections != 1 ||
+		!near(stats.Answers[1].Rate, 1.0/3) {
+		t.Errorf("answer stats = %+v, want 2 and 1 selections", stats.Answers)
+	}
+
+	var quiz struct {
+		Attempts     int         `json:"attempts"`
+		AverageScore *float64    `json:"average_score"`
+		Questions    []itemStats `json:"questions"`
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/"+itoa(created.ID)+"/stats", "", &quiz), http.StatusOK, "quiz stats")
+	if quiz.Attempts != 3 || !near(quiz.AverageScore, 0.5) || len(quiz.Questions) != 2 || quiz.Questions[1].Correct != 1 {
+		t.Errorf("quiz stats = %+v, want 3 attempts averaging 0.5", quiz)
+	}
+
+	expectStatus(t, do(t, router, http.MethodGet, "/api/questions/999/stats", "", nil), http.StatusNotFound, "missing question")
+	expectStatus(t, do(t, router, http.MethodGet, "/api/quizzes/999/stats", "", nil), http.StatusNotFound, "missing quiz")
+}
+
+func TestAdaptiveAttempts(t *testing.T) {
+	router := newTestRouter()
+	created := createSampleQuiz(t, router)
+	quiz := "/api/quizzes/" + itoa(created.ID)
+	answers := map[int64][]int64{}
+	for _, question := range created.Questions {
+		answers[question.ID] = question.AnswerIDs
+	}
+
+	var fixed struct {
+		ID int64 `json:"id"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, quiz+"/attempts", `{}`, &fixed), http.StatusCreated, "start fixed attempt")
+
+	expectStatus(t, do(t, router, http.MethodGet, quiz+"/adaptive", "", nil), http.StatusNotFound, "settings before any")
+	expectStatus(t, do(t, router, http.MethodPut, quiz+"/adaptive",
+		`{"model": "1pl", "min_questions": 3, "max_questions": 2, "target_standard_error": 0.1}`, nil),
+		http.StatusUnprocessableEntity, "min above max")
+	expectStatus(t, do(t, router, http.MethodPut, quiz+"/adaptive",
+		`{"model": "1pl", "max_questions": 2, "target_standard_error": 0.1}`, nil), http.StatusOK, "make adaptive")
+
+	var attempt struct {
+		ID          int64   `json:"id"`
+		Adaptive    bool    `json:"adaptive"`
+		QuestionIDs []int64 `json:"question_ids"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, quiz+"/attempts", `{}`, &attempt), http.StatusCreated, "start adaptive attempt")
+	if !attempt.Adaptive || len(attempt.QuestionIDs) != 0 {
+		t.Fatalf("attempt = %+v, want adaptive with no questions yet", attempt)
+	}
+	path := quiz + "/attempts/" + itoa(attempt.ID)
+
+	type step struct {
+		Done     bool `json:"done"`
+		Answered int  `json:"answered"`
+		Question *struct {
+			ID int64 `json:"id"`
+		} `json:"question"`
+	}
+	// Right on the first question asked, wrong on the second
+	var asked []int64
+	for i := 0; i < 2; i++ {
+		var next, again step
+		expectStatus(t, do(t, router, http.MethodPost, path+"/next", "", &next), http.StatusOK, "next question")
+		expectStatus(t, do(t, router, http.MethodPost, path+"/next", "", &again), http.StatusOK, "next question again")
+		if next.Done || next.Question == nil || next.Answered != i {
+			t.Fatalf("step %d = %+v, want a question", i, next)
+		}
+		if again.Question == nil || again.Question.ID != next.Question.ID {
+			t.Fatalf("asking again gave %+v, want question %d until it is answered", again, next.Question.ID)
+		}
+
+		id := next.Question.ID
+		asked = append(asked, id)
+		expectStatus(t, do(t, router, http.MethodPut, path+"/responses/"+itoa(id),
+			`{"answer_ids": [`+itoa(answers[id][i])+`]}`, nil), http.StatusOK, "record response")
+	}
+	if asked[0] == asked[1] {
+		t.Errorf("asked question %d twice", asked[0])
+	}
+
+	var last step
+	expectStatus(t, do(t, router, http.MethodPost, path+"/next", "", &last), http.StatusOK, "next after max")
+	if !last.Done || last.Question != nil || last.Answered != 2 {
+		t.Errorf("step = %+v, want done after max_questions", last)
+	}
+
+	var submitted struct {
+		Score         *float64 `json:"score"`
+		Ability       *float64 `json:"ability"`
+		StandardError *float64 `json:"standard_error"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, path+"/submit", `{}`, &submitted), http.StatusOK, "submit")
+	// One right and one wrong on equally difficult questions is average
+	if submitted.Ability == nil || math.Abs(*submitted.Ability) > 1e-9 ||
+		submitted.StandardError == nil || *submitted.StandardError >= 1 {
+		t.Errorf("submitted = %+v, want an ability of 0 more precise than the prior", submitted)
+	}
+
+	var calibrations []struct {
+		QuestionID   int64   `json:"question_id"`
+		Difficulty   float64 `json:"difficulty"`
+		Responses    int     `json:"responses"`
+		CalibratedAt *string `json:"calibrated_at"`
+	}
+	expectStatus(t, do(t, router, http.MethodPost, quiz+"/calibration", "", &calibrations), http.StatusOK, "calibrate")
+	if len(calibrations) != 2 || calibrations[0].Responses != 1 || calibrations[0].CalibratedAt == nil {
+		t.Fatalf("calibrations = %+v, want both questions fitted from one response", calibrations)
+	}
+	for _, c := range calibrations {
+		// The question answered wrongly comes out harder
+		if harder := c.QuestionID == asked[1]; harder != (c.Difficulty > 0) {
+			t.Errorf("question %d difficulty = %v", c.QuestionID, c.Difficulty)
+		}
+	}
+
+	expectStatus(t, do(t, router, http.MethodPost, quiz+"/attempts/"+itoa(fixed.ID)+"/next", "", nil),
+		http.StatusConflict, "next on a fixed attempt")
+	expectStatus(t, do(t, router, http.MethodPost, path+"/next", "", nil), http.StatusConflict, "next once submitted")
+
+	expectStatus(t, do(t, router, http.MethodDelete, quiz+"/adaptive", "", nil), http.StatusOK, "stop adaptive")
+	expectStatus(t, do(t, router, http.MethodPost, quiz+"/calibration", "", nil), http.StatusConflict, "calibrate non-adaptive")
+	expectStatus(t, do(t, router, http.MethodDelete, quiz+"/adaptive", "", nil), http.StatusNotFound, "stop again")
+}
+
+// testClock is a clock the test moves by hand.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestReviewsDue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	clock := &testClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store.UseClock(clock)
+	router := setupRouter(store.Repositories())
+
+	created := createSampleQuiz(t, router)
+	first, second := created.Questions[0], created.Questions[1]
+	attempts := "/api/quizzes/" + itoa(created.ID) + "/attempts"
+	play := func(user string, firstAnswer, secondAnswer int) {
+		t.Helper()
+		var attempt struct {
+			ID int64 `json:"id"`
+		}
+		expectStatus(t, do(t, router, http.MethodPost, attempts, `{"user_id": "`+user+`"}`, &attempt), http.StatusCreated, "start attempt")
+		body := `{"responses": [
+			{"question_id": ` + itoa(first.ID) + `, "answer_ids": [` + itoa(first.AnswerIDs[firstAnswer]) + `]},
+			{"question_id": ` + itoa(second.ID) + `, "answer_ids": [` + itoa(second.AnswerIDs[secondAnswer]) + `]}
+		]}`
+		expectStatus(t, do(t, router, http.MethodPost, attempts+"/"+itoa(attempt.ID)+"/submit", body, nil), http.StatusOK, "submit")
+	}
+	type dueReview struct {
+		QuestionID   int  `json:"question_id"`
+		QuizID       *int `json:"quiz_id"`
+		Repetitions  int  `json:"repetitions"`
+		IntervalDays int  `json:"interval_days"`
+		Lapses       int  `json:"lapses"`
+		Question     struct {
+			ID      int `json:"id"`
+			Answers []struct {
+				IsCorrect *bool `json:"is_correct"`
+			} `json:"answers"`
+		} `json:"question"`
+	}
+	due := func(query string) []dueReview {
+		t.Helper()
+		var reviews []dueReview
+		expectStatus(t, do(t, router, http.MethodGet, "/api/users/ada/reviews/due"+query, "", &reviews), http.StatusOK, "due reviews")
+		return reviews
+	}
+
+	// Right on the first question, wrong on the second: both come back the
+	// next day, and not before
+	play("ada", 0, 1)
+	play("", 1, 1)
+	if reviews := due(""); len(reviews) != 0 {
+		t.Fatalf("due straight away: %+v", reviews)
+	}
+	clock.now = clock.now.Add(24 * time.Hour)
+	reviews := due("")
+	if len(reviews) != 2 || reviews[0].QuestionID != int(first.ID) || reviews[1].Repetitions != 0 {
+		t.Fatalf("due after a day = %+v, want both questions", reviews)
+	}
+	if reviews[0].Question.ID != int(first.ID) || reviews[0].QuizID == nil || *reviews[0].QuizID != int(created.ID) ||
+		reviews[0].Question.Answers[0].IsCorrect != nil {
+		t.Errorf("review = %+v, want the player view of the question and its quiz", reviews[0])
+	}
+	if limited := due("?limit=1"); len(limited) != 1 {
+		t.Errorf("limit=1 gave %d reviews", len(limited))
+	}
+
+	// Both right: the first question waits 6 days now, the second only 1
+	play("ada", 0, 0)
+	clock.now = clock.now.Add(24 * time.Hour)
+	reviews = due("")
+	if len(reviews) != 1 || reviews[0].QuestionID != int(second.ID) || reviews[0].IntervalDays != 1 {
+		t.Errorf("due after another day = %+v, want only the second question", reviews)
+	}
+
+	// Forgetting the first question once it is learnt is a lapse
+	clock.now = clock.now.Add(5 * 24 * time.Hour)
+	play("ada", 1, 0)
+	clock.now = clock.now.Add(24 * time.Hour)
+	reviews = due("")
+	if len(reviews) != 1 || reviews[0].QuestionID != int(first.ID) || reviews[0].Lapses != 1 {
+		t.Errorf("due after a lapse = %+v, want the first question", reviews)
+	}
+
+	var others []dueReview
+	expectStatus(t, do(t, router, http.MethodGet, "/api/users/bob/reviews/due", "", &others), http.StatusOK, "other learner")
+	if len(others) != 0 {
+		t.Errorf("bob has %d reviews, want none", len(others))
+	}
+	expectStatus(t, do(t, router, http.MethodGet, "/api/users/ada/reviews/due?limit=500", "", nil),
+		http.StatusBadRequest, "limit over the maximum")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// questionObjectivesRequest replaces every objective a question assesses.
+// An empty list unlinks them all.
+type questionObjectivesRequest struct {
+	ObjectiveIDs []int `json:"objective_ids" binding:"dive,min=1"`
+}
+
+// registerObjectiveRoutes mounts the learning objective endpoints, the
+// links between questions and objectives, and learners' mastery reports.
+// User IDs are free-form strings, so the user path parameter is not named
+// like the integer IDs validatePathIDs checks.
+func registerObjectiveRoutes(api *gin.RouterGroup, objectiveRepo repository.ObjectiveStore) {
+	objectives := api.Group("/objectives")
+
+	objectives.GET("", func(c *gin.Context) {
+		all, err := objectiveRepo.GetAll(c.Request.Context(), c.Query("framework"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, all)
+	})
+
+	objectives.GET("/:id", func(c *gin.Context) {
+		objective, err := objectiveRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, objective)
+	})
+
+	objectives.POST("", func(c *gin.Context) {
+		var data models.ObjectiveInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		id, err := objectiveRepo.Create(c.Request.Context(), data)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+
+	objectives.PUT("/:id", func(c *gin.Context) {
+		var data models.ObjectiveInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		if err := objectiveRepo.Replace(c.Request.Context(), c.Param("id"), data); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Objective updated successfully"})
+	})
+
+	objectives.DELETE("/:id", func(c *gin.Context) {
+		if err := objectiveRepo.Delete(c.Request.Context(), c.Param("id")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Objective deleted successfully"})
+	})
+
+	questions := api.Group("/questions")
+
+	questions.GET("/:id/objectives", func(c *gin.Context) {
+		linked, err := objectiveRepo.GetQuestionObjectives(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, linked)
+	})
+
+	questions.PUT("/:id/objectives", func(c *gin.Context) {
+		var data questionObjectivesRequest
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		linked, err := objectiveRepo.ReplaceQuestionObjectives(c.Request.Context(), c.Param("id"), data.ObjectiveIDs)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, linked)
+	})
+
+	// How well a learner has met each objective across their submitted
+	// attempts, optionally within one framework or quiz
+	api.GET("/users/:user/mastery", func(c *gin.Context) {
+		var query models.MasteryQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		report, err := objectiveRepo.Mastery(c.Request.Context(), c.Param("user"), query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// registerReviewRoutes mounts learners' spaced-repetition reviews. Submitting
+// an attempt reschedules the player's reviews of its questions; this serves
+// the ones now due from every quiz. As for mastery reports, the user path
+// parameter is a free-form string.
+func registerReviewRoutes(api *gin.RouterGroup, reviewRepo repository.ReviewStore) {
+	api.GET("/users/:user/reviews/due", func(c *gin.Context) {
+		var query models.ReviewQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		due, err := reviewRepo.GetDue(c.Request.Context(), c.Param("user"), query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, due)
+	})
+}
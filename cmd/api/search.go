@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// searchContent serves GET /api/search?q=..., returning ranked hits across
+// quizzes, questions and answers.
+func searchContent(searchRepo repository.SearchStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query models.SearchQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		hits, err := searchRepo.Search(c.Request.Context(), query)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"query": query.Q, "data": hits})
+	}
+}
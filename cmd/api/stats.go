@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// registerStatsRoutes mounts the item analysis endpoints. The statistics are
+// kept up to date as attempts are submitted, so reading them is cheap.
+func registerStatsRoutes(api *gin.RouterGroup, statsRepo repository.StatsStore) {
+	// Over every quiz the question has been given in
+	api.GET("/questions/:id/stats", func(c *gin.Context) {
+		stats, err := statsRepo.GetQuestionStats(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	})
+
+	// Over the quiz's attempts only, including the bank questions drawn
+	api.GET("/quizzes/:id/stats", func(c *gin.Context) {
+		stats, err := statsRepo.GetQuizStats(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	})
+}
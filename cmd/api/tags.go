@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// registerTagRoutes mounts the tag endpoints: /api/tags to manage the tags
+// themselves, and /tags under a quiz or question to tag and untag it.
+func registerTagRoutes(api *gin.RouterGroup, tagRepo repository.TagStore) {
+	tags := api.Group("/tags")
+
+	// Every tag with how many quizzes and questions use it
+	tags.GET("", func(c *gin.Context) {
+		all, err := tagRepo.GetAll(c.Request.Context())
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, all)
+	})
+
+	tags.GET("/:id", func(c *gin.Context) {
+		tag, err := tagRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tag)
+	})
+
+	tags.POST("", func(c *gin.Context) {
+		var data models.TagInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		id, err := tagRepo.Create(c.Request.Context(), data)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"id": id})
+	})
+
+	// Renames a tag and moves it within the hierarchy
+	tags.PUT("/:id", func(c *gin.Context) {
+		var data models.TagInput
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		if err := tagRepo.Replace(c.Request.Context(), c.Param("id"), data); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Tag updated successfully"})
+	})
+
+	tags.DELETE("/:id", func(c *gin.Context) {
+		if err := tagRepo.Delete(c.Request.Context(), c.Param("id")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+	})
+
+	registerTaggable(api.Group("/quizzes"), tagRepo.GetQuizTags, tagRepo.TagQuiz, tagRepo.UntagQuiz)
+	registerTaggable(api.Group("/questions"), tagRepo.GetQuestionTags, tagRepo.TagQuestion, tagRepo.UntagQuestion)
+}
+
+// registerTaggable mounts the endpoints that list, add and remove the tags
+// of whatever group serves at /:id.
+func registerTaggable(
+	group *gin.RouterGroup,
+	list func(ctx context.Context, id string) ([]models.Tag, error),
+	tag func(ctx context.Context, id string, name string) (*models.Tag, error),
+	untag func(ctx context.Context, id string, tagID string) error,
+) {
+	group.GET("/:id/tags", func(c *gin.Context) {
+		tags, err := list(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tags)
+	})
+
+	group.POST("/:id/tags", func(c *gin.Context) {
+		var data models.TagRequest
+		if !bindJSON(c, &data) {
+			return
+		}
+
+		added, err := tag(c.Request.Context(), c.Param("id"), data.Tag)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, added)
+	})
+
+	group.DELETE("/:id/tags/:tag_id", func(c *gin.Context) {
+		if err := untag(c.Request.Context(), c.Param("id"), c.Param("tag_id")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Tag removed successfully"})
+	})
+}
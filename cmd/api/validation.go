@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by their JSON names so clients can match errors to the
+	// request they sent.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("question_type", func(fl validator.FieldLevel) bool {
+		return models.IsKnownQuestionType(fl.Field().String())
+	})
+	v.RegisterValidation("scoring_strategy", func(fl validator.FieldLevel) bool {
+		return models.IsKnownScoringStrategy(fl.Field().String())
+	})
+}
+
+// bindJSON decodes and validates the request body into obj. On failure it
+// writes a 400 problem listing every invalid field and returns false.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	writeProblem(c, http.StatusBadRequest, "validation failed", fieldErrors(err))
+	return false
+}
+
+// bindQuery binds and validates the query string into obj. On failure it
+// writes a 400 problem listing every invalid parameter and returns false.
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindQuery(obj)
+	if err == nil {
+		return true
+	}
+
+	writeProblem(c, http.StatusBadRequest, "validation failed", fieldErrors(err))
+	return false
+}
+
+// bindPatch decodes a JSON Merge Patch body into patch, which must be a
+// pointer, and runs its own validation. On failure it writes a 400 problem
+// listing every invalid field and returns false.
+func bindPatch(c *gin.Context, patch interface{ Validate() []models.FieldError }) bool {
+	if !bindJSON(c, patch) {
+		return false
+	}
+
+	if errs := patch.Validate(); len(errs) > 0 {
+		writeProblem(c, http.StatusBadRequest, "validation failed", errs)
+		return false
+	}
+
+	return true
+}
+
+// pathID parses an integer ID from the URL path. On failure it writes a 400
+// problem and returns false.
+func pathID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		writeProblem(c, http.StatusBadRequest, "validation failed",
+			[]models.FieldError{{Field: name, Message: "must be a positive integer"}})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// validatePathIDs rejects a request whose route has an ID parameter that is
+// not a positive integer, before any handler passes it to a repository.
+func validatePathIDs(c *gin.Context) {
+	for _, param := range c.Params {
+		if param.Key == "id" || strings.HasSuffix(param.Key, "_id") {
+			if _, ok := pathID(c, param.Key); !ok {
+				return
+			}
+		}
+	}
+}
+
+func fieldErrors(err error) []models.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, models.FieldError{Field: fieldPath(fe), Message: fieldMessage(fe)})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []models.FieldError{{Field: typeErr.Field, Message: "must be a " + typeErr.Type.String()}}
+	}
+
+	return []models.FieldError{{Field: "body", Message: err.Error()}}
+}
+
+// fieldPath drops the root struct name from the validator's namespace, so
+// "QuizDraft.questions[0].type" becomes "questions[0].type".
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required unless answer_ids or text is given"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must not be empty"
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "question_type":
+		return "must be one of: " + strings.Join(models.QuestionTypes, ", ")
+	case "scoring_strategy":
+		return "must be one of: " + strings.Join(models.ScoringStrategies, ", ")
+	default:
+		return "is invalid"
+	}
+}
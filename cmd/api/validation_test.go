@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestQuestionQuizComesFromPath(t *testing.T) {
+	router := newTestRouter()
+	for _, title := range []string{"First", "Second"} {
+		expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes", `{"title": "`+title+`"}`, nil), http.StatusCreated, "create quiz")
+	}
+
+	// A quiz_id in the body is not a field of the input and is ignored
+	body := `{"question": "Q", "type": "true_false", "quiz_id": 1}`
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes/2/questions", body, nil), http.StatusCreated, "create question")
+
+	for quizID, want := range map[int]int{1: 0, 2: 1} {
+		var questions []struct {
+			QuizID *int `json:"quiz_id"`
+		}
+		path := "/api/quizzes/" + strconv.Itoa(quizID) + "/questions"
+		expectStatus(t, do(t, router, http.MethodGet, path, "", &questions), http.StatusOK, "list questions")
+		if len(questions) != want {
+			t.Errorf("quiz %d has %d questions, want %d", quizID, len(questions), want)
+		}
+		for _, q := range questions {
+			if q.QuizID == nil || *q.QuizID != quizID {
+				t.Errorf("question listed under quiz %d has quiz_id %v", quizID, q.QuizID)
+			}
+		}
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	router := newTestRouter()
+	expectStatus(t, do(t, router, http.MethodPost, "/api/quizzes", `{"title": "Go"}`, nil), http.StatusCreated, "create quiz")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		fields []models.FieldError
+	}{
+		{"missing field", "/api/quizzes", `{}`,
+			[]models.FieldError{{Field: "title", Message: "is required"}}},
+		{"wrong type", "/api/quizzes", `{"title": 5}`,
+			[]models.FieldError{{Field: "title", Message: "must be a string"}}},
+		{"malformed path id", "/api/quizzes/abc/questions", `{"question": "Q", "type": "true_false"}`,
+			[]models.FieldError{{Field: "id", Message: "must be a positive integer"}}},
+		{"every invalid field", "/api/quizzes/1/questions", `{"question": "", "type": "essay", "order_num": -1}`,
+			[]models.FieldError{
+				{Field: "question", Message: "is required"},
+				{Field: "type", Message: "must be one of: " + strings.Join(models.QuestionTypes, ", ")},
+				{Field: "order_num", Message: "must be at least 0"},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/problem+json") {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+
+			var got problem
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding %q: %v", w.Body.String(), err)
+			}
+			want := problem{
+				Type:     "about:blank",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "validation failed",
+				Instance: tt.path,
+				Fields:   tt.fields,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("problem = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
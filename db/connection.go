@@ -3,6 +3,7 @@ package db
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -51,3 +52,19 @@ func GetDB() *sqlx.DB {
 	log.Println("Successfully connected to the database")
 	return db
 }
+
+// QueryTimeout returns how long a single repository call may run, read from
+// DB_QUERY_TIMEOUT as a Go duration such as "5s". It defaults to five seconds;
+// "0" disables the timeout.
+func QueryTimeout() time.Duration {
+	value := os.Getenv("DB_QUERY_TIMEOUT")
+	if value == "" {
+		return 5 * time.Second
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid DB_QUERY_TIMEOUT %q: %v", value, err)
+	}
+	return timeout
+}
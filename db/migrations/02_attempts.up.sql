@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS attempts (
+  id SERIAL PRIMARY KEY,
+  quiz_id INT NOT NULL,
+  user_id VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+  score DOUBLE PRECISION,
+  max_score DOUBLE PRECISION,
+  started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  submitted_at TIMESTAMP,
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS attempts_quiz_id_idx ON attempts (quiz_id);
+
+CREATE TABLE IF NOT EXISTS attempt_responses (
+  id SERIAL PRIMARY KEY,
+  attempt_id INT NOT NULL,
+  question_id INT NOT NULL,
+  answer_ids INT[] NOT NULL DEFAULT '{}',
+  is_correct BOOLEAN,
+  points DOUBLE PRECISION,
+  responded_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (attempt_id, question_id),
+  FOREIGN KEY (attempt_id) REFERENCES attempts(id) ON DELETE CASCADE,
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
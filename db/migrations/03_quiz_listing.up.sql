@@ -0,0 +1,2 @@
+-- Keyset pagination over quizzes sorted by title walks (title, id)
+CREATE INDEX IF NOT EXISTS quizzes_title_id_idx ON quizzes (title, id);
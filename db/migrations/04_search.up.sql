@@ -0,0 +1,19 @@
+-- Full-text search. Each searchable table gets a generated tsvector column
+-- kept up to date by Postgres, with a GIN index for @@ matching.
+ALTER TABLE quizzes ADD COLUMN IF NOT EXISTS search tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS quizzes_search_idx ON quizzes USING GIN (search);
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS search tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', coalesce(question, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS questions_search_idx ON questions USING GIN (search);
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS search tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', coalesce(answer, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS answers_search_idx ON answers USING GIN (search);
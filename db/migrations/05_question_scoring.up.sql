@@ -0,0 +1,3 @@
+-- How a question's responses are scored. Only multiple_select questions may
+-- use anything other than all_or_nothing; the application enforces this.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS scoring VARCHAR(20) NOT NULL DEFAULT 'all_or_nothing';
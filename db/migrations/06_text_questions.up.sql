@@ -0,0 +1,7 @@
+-- Grading rules for question types answered with text rather than by
+-- picking answers: short_answer, numeric and fill_in_blank. NULL for the
+-- others.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS config JSONB;
+
+-- What the player typed, one value per blank for fill_in_blank
+ALTER TABLE attempt_responses ADD COLUMN IF NOT EXISTS text_answers TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+-- Ordering questions store each answer's place in the correct sequence, and
+-- matching questions store what each answer pairs with. Both are NULL for
+-- other question types.
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS position INT;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS match_text TEXT;
@@ -0,0 +1,26 @@
+-- Feedback shown to players once an attempt is submitted: why a question's
+-- answer is what it is, and why each answer option is right or wrong.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS explanation TEXT;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS rationale TEXT;
+
+-- Hints are revealed one tier at a time, lowest first. Each tier revealed
+-- takes its penalty off the points the question earns.
+CREATE TABLE IF NOT EXISTS question_hints (
+  id SERIAL PRIMARY KEY,
+  question_id INT NOT NULL,
+  tier INT NOT NULL,
+  hint TEXT NOT NULL,
+  penalty DOUBLE PRECISION NOT NULL DEFAULT 0,
+  UNIQUE (question_id, tier),
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS attempt_hints (
+  attempt_id INT NOT NULL,
+  question_id INT NOT NULL,
+  tier INT NOT NULL,
+  revealed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (attempt_id, question_id, tier),
+  FOREIGN KEY (attempt_id) REFERENCES attempts(id) ON DELETE CASCADE,
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,62 @@
+-- Question banks hold questions that no single quiz owns. Quizzes draw from
+-- them at random through draw rules. Every question belongs to exactly one
+-- quiz or one bank.
+CREATE TABLE IF NOT EXISTS banks (
+  id SERIAL PRIMARY KEY,
+  title VARCHAR(255) NOT NULL,
+  description TEXT
+);
+
+ALTER TABLE questions ALTER COLUMN quiz_id DROP NOT NULL;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS bank_id INT REFERENCES banks(id) ON DELETE CASCADE;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_owner_check;
+ALTER TABLE questions ADD CONSTRAINT questions_owner_check CHECK ((quiz_id IS NULL) <> (bank_id IS NULL));
+CREATE INDEX IF NOT EXISTS questions_bank_id_idx ON questions (bank_id);
+
+-- Tags narrow what a draw rule picks from a bank
+CREATE TABLE IF NOT EXISTS tags (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS question_tags (
+  question_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  PRIMARY KEY (question_id, tag_id),
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE,
+  FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Each rule picks count questions from a bank, optionally only those with a
+-- tag. Rules are applied in position order.
+CREATE TABLE IF NOT EXISTS quiz_draw_rules (
+  id SERIAL PRIMARY KEY,
+  quiz_id INT NOT NULL,
+  bank_id INT NOT NULL,
+  position INT NOT NULL,
+  count INT NOT NULL CHECK (count > 0),
+  tag VARCHAR(100),
+  UNIQUE (quiz_id, position),
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE,
+  FOREIGN KEY (bank_id) REFERENCES banks(id) ON DELETE CASCADE
+);
+
+-- An attempt records the seed its questions were drawn with, and the
+-- questions themselves, so it can be reviewed exactly as it was taken.
+ALTER TABLE attempts ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS attempt_questions (
+  attempt_id INT NOT NULL,
+  question_id INT NOT NULL,
+  position INT NOT NULL,
+  PRIMARY KEY (attempt_id, question_id),
+  FOREIGN KEY (attempt_id) REFERENCES attempts(id) ON DELETE CASCADE,
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+-- Attempts started before banks existed were made of their quiz's questions
+INSERT INTO attempt_questions (attempt_id, question_id, position)
+SELECT a.id, q.id, ROW_NUMBER() OVER (PARTITION BY a.id ORDER BY q.order_num, q.id)
+FROM attempts a
+JOIN questions q ON q.quiz_id = a.quiz_id
+ON CONFLICT DO NOTHING;
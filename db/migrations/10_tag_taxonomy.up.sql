@@ -0,0 +1,17 @@
+-- Tags form a hierarchy of categories: filtering by a tag also matches
+-- anything tagged with a tag beneath it. Deleting a tag lifts its children
+-- to the top level.
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES tags(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS tags_parent_id_idx ON tags (parent_id);
+
+CREATE TABLE IF NOT EXISTS quiz_tags (
+  quiz_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  PRIMARY KEY (quiz_id, tag_id),
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE,
+  FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Filters look tags up from the tag side
+CREATE INDEX IF NOT EXISTS quiz_tags_tag_id_idx ON quiz_tags (tag_id);
+CREATE INDEX IF NOT EXISTS question_tags_tag_id_idx ON question_tags (tag_id);
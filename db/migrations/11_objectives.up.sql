@@ -0,0 +1,22 @@
+-- Learning objectives, such as the success criteria of a standard, that
+-- questions assess. A code is unique within its framework.
+CREATE TABLE IF NOT EXISTS objectives (
+  id SERIAL PRIMARY KEY,
+  framework VARCHAR(100) NOT NULL,
+  code VARCHAR(50) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT,
+  UNIQUE (framework, code)
+);
+
+CREATE TABLE IF NOT EXISTS question_objectives (
+  question_id INT NOT NULL,
+  objective_id INT NOT NULL,
+  PRIMARY KEY (question_id, objective_id),
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE,
+  FOREIGN KEY (objective_id) REFERENCES objectives(id) ON DELETE CASCADE
+);
+
+-- Mastery reports start from an objective's questions
+CREATE INDEX IF NOT EXISTS question_objectives_objective_id_idx ON question_objectives (objective_id);
+CREATE INDEX IF NOT EXISTS attempts_user_id_idx ON attempts (user_id);
@@ -0,0 +1,78 @@
+-- How long the player reported spending on a question, added up over every
+-- time they responded to it. NULL when never reported.
+ALTER TABLE attempt_responses ADD COLUMN IF NOT EXISTS time_spent_ms BIGINT CHECK (time_spent_ms >= 0);
+
+-- Running totals for item analysis, added to as each attempt is submitted
+-- so statistics never have to be computed from every attempt. Totals are
+-- kept per quiz so a bank question's statistics can be read for one quiz or
+-- summed over all of them. See internal/analytics for what each column is.
+CREATE TABLE IF NOT EXISTS quiz_stats (
+  quiz_id INT PRIMARY KEY,
+  attempts INT NOT NULL DEFAULT 0,
+  score_shares DOUBLE PRECISION NOT NULL DEFAULT 0,
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS item_stats (
+  quiz_id INT NOT NULL,
+  question_id INT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  responses INT NOT NULL DEFAULT 0,
+  correct INT NOT NULL DEFAULT 0,
+  paired INT NOT NULL DEFAULT 0,
+  paired_correct INT NOT NULL DEFAULT 0,
+  rest_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+  rest_squares DOUBLE PRECISION NOT NULL DEFAULT 0,
+  correct_rest DOUBLE PRECISION NOT NULL DEFAULT 0,
+  timed INT NOT NULL DEFAULT 0,
+  time_ms BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (quiz_id, question_id),
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE,
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS item_stats_question_id_idx ON item_stats (question_id);
+
+CREATE TABLE IF NOT EXISTS answer_stats (
+  quiz_id INT NOT NULL,
+  answer_id INT NOT NULL,
+  selections INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (quiz_id, answer_id),
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE,
+  FOREIGN KEY (answer_id) REFERENCES answers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS answer_stats_answer_id_idx ON answer_stats (answer_id);
+
+-- Tally the attempts submitted before the totals existed
+INSERT INTO quiz_stats (quiz_id, attempts, score_shares)
+SELECT quiz_id, COUNT(*), COALESCE(SUM(score / max_score) FILTER (WHERE max_score > 0), 0)
+FROM attempts
+WHERE status = 'submitted'
+GROUP BY quiz_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO item_stats (quiz_id, question_id, attempts, responses, correct,
+  paired, paired_correct, rest_sum, rest_squares, correct_rest)
+SELECT quiz_id, question_id, COUNT(*), COUNT(*) FILTER (WHERE answered), COUNT(*) FILTER (WHERE correct),
+  COUNT(rest), COUNT(rest) FILTER (WHERE correct), COALESCE(SUM(rest), 0), COALESCE(SUM(rest * rest), 0),
+  COALESCE(SUM(rest) FILTER (WHERE correct), 0)
+FROM (
+  SELECT a.quiz_id, aq.question_id, r.id IS NOT NULL AS answered, COALESCE(r.is_correct, false) AS correct,
+    CASE WHEN a.max_score > 1 THEN (a.score - COALESCE(r.points, 0)) / (a.max_score - 1) END AS rest
+  FROM attempts a
+  JOIN attempt_questions aq ON aq.attempt_id = a.id
+  LEFT JOIN attempt_responses r ON r.attempt_id = a.id AND r.question_id = aq.question_id
+  WHERE a.status = 'submitted'
+) outcomes
+GROUP BY quiz_id, question_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO answer_stats (quiz_id, answer_id, selections)
+SELECT a.quiz_id, ans.id, COUNT(*)
+FROM attempts a
+JOIN attempt_responses r ON r.attempt_id = a.id
+JOIN answers ans ON ans.id = ANY (r.answer_ids)
+WHERE a.status = 'submitted'
+GROUP BY a.quiz_id, ans.id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,28 @@
+-- Quizzes with settings here are adaptive: attempts are asked one question
+-- at a time, chosen by item response theory, until the ability estimate is
+-- precise enough or max_questions have been asked.
+CREATE TABLE IF NOT EXISTS quiz_adaptive_settings (
+  quiz_id INT PRIMARY KEY,
+  model VARCHAR(3) NOT NULL CHECK (model IN ('1pl', '2pl')),
+  min_questions INT NOT NULL DEFAULT 0 CHECK (min_questions >= 0),
+  max_questions INT NOT NULL CHECK (max_questions > 0),
+  target_se DOUBLE PRECISION NOT NULL CHECK (target_se > 0),
+  FOREIGN KEY (quiz_id) REFERENCES quizzes(id) ON DELETE CASCADE
+);
+
+-- Item parameters fitted from past responses. Questions without a row are
+-- treated as of average difficulty with a discrimination of 1.
+CREATE TABLE IF NOT EXISTS question_calibrations (
+  question_id INT PRIMARY KEY,
+  difficulty DOUBLE PRECISION NOT NULL,
+  discrimination DOUBLE PRECISION NOT NULL CHECK (discrimination > 0),
+  responses INT NOT NULL,
+  calibrated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+-- Whether an attempt was delivered adaptively is fixed when it starts, and
+-- its ability estimate is recorded when it is submitted.
+ALTER TABLE attempts ADD COLUMN IF NOT EXISTS adaptive BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE attempts ADD COLUMN IF NOT EXISTS ability DOUBLE PRECISION;
+ALTER TABLE attempts ADD COLUMN IF NOT EXISTS ability_se DOUBLE PRECISION;
@@ -0,0 +1,20 @@
+-- Each learner's spaced-repetition schedule for every question they have
+-- been given in a submitted attempt. The schedule is computed by the
+-- application, so its times carry their time zone. Attempts submitted before
+-- this migration are not replayed; schedules start with the next one.
+CREATE TABLE IF NOT EXISTS review_cards (
+  user_id VARCHAR(255) NOT NULL,
+  question_id INT NOT NULL,
+  repetitions INT NOT NULL DEFAULT 0,
+  interval_days INT NOT NULL DEFAULT 0,
+  ease DOUBLE PRECISION NOT NULL,
+  lapses INT NOT NULL DEFAULT 0,
+  reviews INT NOT NULL DEFAULT 0,
+  last_quality INT NOT NULL,
+  last_reviewed_at TIMESTAMPTZ NOT NULL,
+  due_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (user_id, question_id),
+  FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS review_cards_due_idx ON review_cards (user_id, due_at);
@@ -0,0 +1,3 @@
+-- Quiz statistics, listings and the full quiz tree all look up a quiz's own
+-- questions by quiz_id, in order
+CREATE INDEX IF NOT EXISTS questions_quiz_id_idx ON questions (quiz_id, order_num);
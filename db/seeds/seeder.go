@@ -13,18 +13,25 @@ import (
 )
 
 type (
-	Quiz     = models.Quiz
-	Question = models.Question
-	Answer   = models.Answer
+	Quiz      = models.Quiz
+	Question  = models.Question
+	Answer    = models.Answer
+	Objective = models.Objective
 )
 
 var (
 	// CreateQuiz creates a new quiz in the database
 	createQuiz = repository.CreateQuiz
+	// TagQuiz tags a quiz, creating the tag if needed
+	tagQuiz = repository.TagQuiz
 	// CreateQuestion creates a new question in the database
 	createQuestion = repository.CreateQuestion
 	// CreateAnswer creates a new answer in the database
 	createAnswer = repository.CreateAnswer
+	// CreateObjective creates a new learning objective in the database
+	createObjective = repository.CreateObjective
+	// LinkObjective records that a question assesses an objective
+	linkObjective = repository.LinkObjective
 )
 
 // Helper function to get environment variable with a default value
@@ -86,14 +93,21 @@ func main() {
 	}
 
 	// Create a WCAG 2.2 Section 1 quiz
+	description := "Test your knowledge of WCAG 2.2 Section 1 guidelines on making information and user interface components perceivable to users."
 	quizID, err := createQuiz(db, &Quiz{
 		Title:       "WCAG 2.2 Section 1: Perceivable",
-		Description: "Test your knowledge of WCAG 2.2 Section 1 guidelines on making information and user interface components perceivable to users.",
+		Description: &description,
 	})
 	if err != nil {
 		log.Fatal("Failed to create quiz:", err)
 	}
 
+	for _, tag := range []string{"wcag-2.2", "perceivable"} {
+		if err := tagQuiz(db, quizID, tag); err != nil {
+			log.Fatal("Failed to tag quiz:", err)
+		}
+	}
+
 	// Seed questions and answers
 	seedQuestions(db, quizID)
 
@@ -101,9 +115,11 @@ func main() {
 }
 
 func seedQuestions(db *sql.DB, quizID int64) {
+	quiz := int(quizID)
+
 	// Guideline 1.1: Text Alternatives
 	q1ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 guideline 1.1.1 (Non-text Content), what must be provided for all non-text content?",
 		Type:     "multiple_choice",
 		Order:    1,
@@ -126,7 +142,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Guideline 1.2: Time-based Media
 	q2ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "For prerecorded audio-only content, what is required to meet WCAG 2.2 Success Criterion 1.2.1 (Audio-only and Video-only)?",
 		Type:     "multiple_choice",
 		Order:    2,
@@ -149,7 +165,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// More on Time-based Media
 	q3ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What is required for prerecorded video content to meet WCAG 2.2 Success Criterion 1.2.2 (Captions)?",
 		Type:     "multiple_choice",
 		Order:    3,
@@ -172,7 +188,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Audio Description
 	q4ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.2.3, what must be provided for prerecorded video content?",
 		Type:     "multiple_choice",
 		Order:    4,
@@ -195,7 +211,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Guideline 1.3: Adaptable
 	q5ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 guideline 1.3.1 (Info and Relationships), what should be programmatically determined or available in text?",
 		Type:     "multiple_choice",
 		Order:    5,
@@ -218,7 +234,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Meaningful Sequence
 	q6ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What does WCAG 2.2 Success Criterion 1.3.2 (Meaningful Sequence) require?",
 		Type:     "multiple_choice",
 		Order:    6,
@@ -241,7 +257,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Sensory Characteristics
 	q7ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.3.3 (Sensory Characteristics), instructions for understanding content should not rely solely on what?",
 		Type:     "multiple_choice",
 		Order:    7,
@@ -264,7 +280,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Orientation (1.3.4)
 	q8ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What does WCAG 2.2 Success Criterion 1.3.4 (Orientation) require?",
 		Type:     "multiple_choice",
 		Order:    8,
@@ -287,7 +303,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Identify Input Purpose (1.3.5)
 	q9ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.3.5 (Identify Input Purpose), what should be true about input fields that collect information about the user?",
 		Type:     "multiple_choice",
 		Order:    9,
@@ -310,7 +326,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Guideline 1.4: Distinguishable
 	q10ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.1 (Use of Color), color should not be used as what?",
 		Type:     "multiple_choice",
 		Order:    10,
@@ -333,7 +349,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Audio Control
 	q11ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What does WCAG 2.2 Success Criterion 1.4.2 (Audio Control) require for any audio that plays automatically for more than 3 seconds?",
 		Type:     "multiple_choice",
 		Order:    11,
@@ -356,7 +372,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Contrast Minimum
 	q12ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What is the minimum contrast ratio required for normal text according to WCAG 2.2 Success Criterion 1.4.3 (Contrast Minimum)?",
 		Type:     "multiple_choice",
 		Order:    12,
@@ -379,7 +395,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Resize Text
 	q13ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.4 (Resize Text), text should be able to be resized without assistive technology up to what percentage without loss of content or functionality?",
 		Type:     "multiple_choice",
 		Order:    13,
@@ -402,7 +418,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Images of Text
 	q14ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.5 (Images of Text), when should text be used instead of images of text?",
 		Type:     "multiple_choice",
 		Order:    14,
@@ -425,7 +441,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Reflow
 	q15ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.10 (Reflow), content should be presentable without loss of information or functionality at what viewport width?",
 		Type:     "multiple_choice",
 		Order:    15,
@@ -448,7 +464,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Non-Text Contrast
 	q16ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "What minimum contrast ratio is required for user interface components and graphical objects according to WCAG 2.2 Success Criterion 1.4.11 (Non-text Contrast)?",
 		Type:     "multiple_choice",
 		Order:    16,
@@ -471,7 +487,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Text Spacing
 	q17ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.12 (Text Spacing), no loss of content or functionality should occur when users modify which of the following text properties?",
 		Type:     "multiple_choice",
 		Order:    17,
@@ -494,7 +510,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// Content on Hover or Focus
 	q18ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2 Success Criterion 1.4.13 (Content on Hover or Focus), which requirement applies to additional content that appears on hover or focus?",
 		Type:     "multiple_choice",
 		Order:    18,
@@ -517,7 +533,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 
 	// True/False questions
 	q19ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2, content should rely solely on color to convey important information.",
 		Type:     "true_false",
 		Order:    19,
@@ -537,7 +553,7 @@ func seedQuestions(db *sql.DB, quizID int64) {
 	}
 
 	q20ID, err := createQuestion(db, &Question{
-		QuizID:   int(quizID),
+		QuizID:   &quiz,
 		Question: "According to WCAG 2.2, all audio that plays automatically for more than 3 seconds must have a mechanism to pause, stop, or control the volume independently from the system volume.",
 		Type:     "true_false",
 		Order:    20,
@@ -555,4 +571,68 @@ func seedQuestions(db *sql.DB, quizID int64) {
 			log.Fatal("Failed to create answer:", err)
 		}
 	}
+
+	// Each question assesses the success criterion it asks about
+	seedObjectives(db, map[string][]int64{
+		"1.1.1":  {q1ID},
+		"1.2.1":  {q2ID},
+		"1.2.2":  {q3ID},
+		"1.2.3":  {q4ID},
+		"1.3.1":  {q5ID},
+		"1.3.2":  {q6ID},
+		"1.3.3":  {q7ID},
+		"1.3.4":  {q8ID},
+		"1.3.5":  {q9ID},
+		"1.4.1":  {q10ID, q19ID},
+		"1.4.2":  {q11ID, q20ID},
+		"1.4.3":  {q12ID},
+		"1.4.4":  {q13ID},
+		"1.4.5":  {q14ID},
+		"1.4.10": {q15ID},
+		"1.4.11": {q16ID},
+		"1.4.12": {q17ID},
+		"1.4.13": {q18ID},
+	})
+}
+
+// seedObjectives creates the WCAG 2.2 Section 1 success criteria as learning
+// objectives and links each to the questions, by code, that assess it.
+func seedObjectives(db *sql.DB, questions map[string][]int64) {
+	criteria := []struct{ code, title string }{
+		{"1.1.1", "Non-text Content"},
+		{"1.2.1", "Audio-only and Video-only (Prerecorded)"},
+		{"1.2.2", "Captions (Prerecorded)"},
+		{"1.2.3", "Audio Description or Media Alternative (Prerecorded)"},
+		{"1.3.1", "Info and Relationships"},
+		{"1.3.2", "Meaningful Sequence"},
+		{"1.3.3", "Sensory Characteristics"},
+		{"1.3.4", "Orientation"},
+		{"1.3.5", "Identify Input Purpose"},
+		{"1.4.1", "Use of Color"},
+		{"1.4.2", "Audio Control"},
+		{"1.4.3", "Contrast (Minimum)"},
+		{"1.4.4", "Resize Text"},
+		{"1.4.5", "Images of Text"},
+		{"1.4.10", "Reflow"},
+		{"1.4.11", "Non-text Contrast"},
+		{"1.4.12", "Text Spacing"},
+		{"1.4.13", "Content on Hover or Focus"},
+	}
+
+	for _, criterion := range criteria {
+		objectiveID, err := createObjective(db, &Objective{
+			Framework: "WCAG 2.2",
+			Code:      criterion.code,
+			Title:     criterion.title,
+		})
+		if err != nil {
+			log.Fatal("Failed to create objective:", err)
+		}
+
+		for _, questionID := range questions[criterion.code] {
+			if err := linkObjective(db, questionID, objectiveID); err != nil {
+				log.Fatal("Failed to link objective:", err)
+			}
+		}
+	}
 }
@@ -0,0 +1,208 @@
+// Package analytics computes classical item statistics from running totals.
+// A tally only ever grows by what one submitted attempt adds to it, so the
+// repositories keep tallies up to date as attempts are submitted instead of
+// reading every attempt each time statistics are asked for.
+package analytics
+
+import (
+	"math"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// Outcome is how one submitted attempt did on one of its questions.
+type Outcome struct {
+	Answered bool
+	// Correct is whether the response earned full marks before any hint
+	// penalty.
+	Correct bool
+	// Points is what the question earned after hint penalties, and Score
+	// and MaxScore are what the whole attempt earned and could have.
+	Points   float64
+	Score    float64
+	MaxScore float64
+	// TimeSpentMs is how long the player reported spending on the
+	// question, if they did.
+	TimeSpentMs *int64
+}
+
+// Tally holds the running totals for one question. The tally of two sets of
+// attempts is the sum of their tallies.
+type Tally struct {
+	Attempts  int
+	Responses int
+	Correct   int
+
+	// Paired counts the attempts that had other questions too. Only those
+	// have a rest score, the share of the other questions' points earned,
+	// to correlate with. The sums below are over them.
+	Paired        int
+	PairedCorrect int
+	RestSum       float64
+	RestSquares   float64
+	CorrectRest   float64
+
+	// Timed counts the responses with a reported time spent, which add up
+	// to TimeMs.
+	Timed  int
+	TimeMs int64
+}
+
+// Observe returns the tally of a single outcome.
+func Observe(outcome Outcome) Tally {
+	t := Tally{Attempts: 1}
+	if outcome.Answered {
+		t.Responses = 1
+	}
+	if outcome.Correct {
+		t.Correct = 1
+	}
+
+	if outcome.MaxScore > 1 {
+		rest := (outcome.Score - outcome.Points) / (outcome.MaxScore - 1)
+		t.Paired = 1
+		t.RestSum = rest
+		t.RestSquares = rest * rest
+		if outcome.Correct {
+			t.PairedCorrect = 1
+			t.CorrectRest = rest
+		}
+	}
+
+	if outcome.TimeSpentMs != nil {
+		t.Timed = 1
+		t.TimeMs = *outcome.TimeSpentMs
+	}
+
+	return t
+}
+
+// Add returns the tally of the attempts in both t and u.
+func (t Tally) Add(u Tally) Tally {
+	return Tally{
+		Attempts:      t.Attempts + u.Attempts,
+		Responses:     t.Responses + u.Responses,
+		Correct:       t.Correct + u.Correct,
+		Paired:        t.Paired + u.Paired,
+		PairedCorrect: t.PairedCorrect + u.PairedCorrect,
+		RestSum:       t.RestSum + u.RestSum,
+		RestSquares:   t.RestSquares + u.RestSquares,
+		CorrectRest:   t.CorrectRest + u.CorrectRest,
+		Timed:         t.Timed + u.Timed,
+		TimeMs:        t.TimeMs + u.TimeMs,
+	}
+}
+
+// PValue is the share of attempts that answered the question correctly. It
+// is not defined until an attempt has been given the question.
+func (t Tally) PValue() (float64, bool) {
+	if t.Attempts == 0 {
+		return 0, false
+	}
+	return float64(t.Correct) / float64(t.Attempts), true
+}
+
+// PointBiserial is the correlation between answering the question correctly
+// and the rest score. It is not defined until some paired attempts got it
+// right and some wrong, and their rest scores differ.
+func (t Tally) PointBiserial() (float64, bool) {
+	n := float64(t.Paired)
+	correct := float64(t.PairedCorrect)
+	covariance := n*t.CorrectRest - correct*t.RestSum
+	varianceCorrect := n*correct - correct*correct
+	varianceRest := n*t.RestSquares - t.RestSum*t.RestSum
+	// Rounding can leave a tiny variance where there is none
+	if varianceCorrect <= 0 || varianceRest <= 1e-9 {
+		return 0, false
+	}
+
+	r := covariance / math.Sqrt(varianceCorrect*varianceRest)
+	return math.Max(-1, math.Min(1, r)), true
+}
+
+// AverageTimeMs is the mean time spent over the responses that reported it.
+func (t Tally) AverageTimeMs() (float64, bool) {
+	if t.Timed == 0 {
+		return 0, false
+	}
+	return float64(t.TimeMs) / float64(t.Timed), true
+}
+
+// Difficulty labels a p-value.
+func Difficulty(pValue float64) string {
+	switch {
+	case pValue >= models.EasyFrom:
+		return models.DifficultyEasy
+	case pValue >= models.MediumFrom:
+		return models.DifficultyMedium
+	default:
+		return models.DifficultyHard
+	}
+}
+
+// Describe fills in the counts and statistics of stats from t, and the rate
+// of each of its answers from their selections.
+func (t Tally) Describe(stats *models.ItemStats) {
+	stats.Attempts, stats.Responses, stats.Correct = t.Attempts, t.Responses, t.Correct
+	stats.PValue, stats.Difficulty = nil, nil
+	if p, ok := t.PValue(); ok {
+		difficulty := Difficulty(p)
+		stats.PValue, stats.Difficulty = &p, &difficulty
+	}
+	stats.PointBiserial = optional(t.PointBiserial())
+	stats.AverageTimeMs = optional(t.AverageTimeMs())
+
+	for i := range stats.Answers {
+		stats.Answers[i].Rate = nil
+		if t.Attempts > 0 {
+			rate := float64(stats.Answers[i].Selections) / float64(t.Attempts)
+			stats.Answers[i].Rate = &rate
+		}
+	}
+}
+
+// CountsSelections reports whether the answers selected for a question type
+// are counted. Only those responding by selecting have distractors; ordering
+// and matching responses use every answer.
+func CountsSelections(questionType string) bool {
+	t, ok := models.LookupQuestionType(questionType)
+	return ok && t.Respond == models.RespondBySelecting
+}
+
+// QuizTally holds the running totals for the submitted attempts at a quiz.
+type QuizTally struct {
+	Attempts int
+	// ScoreShares adds up the share of its maximum score each attempt
+	// earned.
+	ScoreShares float64
+}
+
+// ObserveQuiz returns the tally of a single submitted attempt.
+func ObserveQuiz(score float64, maxScore float64) QuizTally {
+	t := QuizTally{Attempts: 1}
+	if maxScore > 0 {
+		t.ScoreShares = score / maxScore
+	}
+	return t
+}
+
+// Add returns the tally of the attempts in both t and u.
+func (t QuizTally) Add(u QuizTally) QuizTally {
+	return QuizTally{Attempts: t.Attempts + u.Attempts, ScoreShares: t.ScoreShares + u.ScoreShares}
+}
+
+// Describe fills in the attempt count and average score of stats from t.
+func (t QuizTally) Describe(stats *models.QuizStats) {
+	stats.Attempts, stats.AverageScore = t.Attempts, nil
+	if t.Attempts > 0 {
+		average := t.ScoreShares / float64(t.Attempts)
+		stats.AverageScore = &average
+	}
+}
+
+func optional(value float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	return &value
+}
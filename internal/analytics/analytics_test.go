@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestTallyMatchesDirectComputation(t *testing.T) {
+	// Each attempt has four questions; the first is the one analysed
+	attempts := []struct {
+		correct bool
+		score   float64
+	}{
+		{true, 4}, {true, 3}, {false, 1}, {true, 2.5}, {false, 2}, {false, 0},
+	}
+
+	var tally Tally
+	var xs, ys []float64
+	for _, attempt := range attempts {
+		points, x := 0.0, 0.0
+		if attempt.correct {
+			points, x = 1, 1
+		}
+		tally = tally.Add(Observe(Outcome{Answered: true, Correct: attempt.correct, Points: points, Score: attempt.score, MaxScore: 4}))
+		xs = append(xs, x)
+		ys = append(ys, (attempt.score-points)/3)
+	}
+
+	if p, ok := tally.PValue(); !ok || p != 0.5 {
+		t.Errorf("p-value = %v, %v; want 0.5", p, ok)
+	}
+
+	want := pearson(xs, ys)
+	got, ok := tally.PointBiserial()
+	if !ok || math.Abs(got-want) > 1e-9 {
+		t.Errorf("point-biserial = %v, %v; want %v", got, ok, want)
+	}
+	if got <= 0 {
+		t.Errorf("point-biserial = %v, want positive when stronger players get it right", got)
+	}
+}
+
+func TestTallyUndefinedStatistics(t *testing.T) {
+	var empty Tally
+	if _, ok := empty.PValue(); ok {
+		t.Error("p-value defined without attempts")
+	}
+
+	// Everyone right leaves nothing to correlate
+	allRight := Observe(Outcome{Answered: true, Correct: true, Points: 1, Score: 2, MaxScore: 3}).
+		Add(Observe(Outcome{Answered: true, Correct: true, Points: 1, Score: 1, MaxScore: 3}))
+	if _, ok := allRight.PointBiserial(); ok {
+		t.Error("point-biserial defined when every attempt was correct")
+	}
+
+	// Single-question attempts have no rest score
+	alone := Observe(Outcome{Answered: true, Correct: true, Points: 1, Score: 1, MaxScore: 1}).
+		Add(Observe(Outcome{Correct: false, MaxScore: 1}))
+	if alone.Paired != 0 {
+		t.Errorf("paired = %d, want 0 for single-question attempts", alone.Paired)
+	}
+	if _, ok := alone.AverageTimeMs(); ok {
+		t.Error("average time defined without reported times")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	spent := int64(3000)
+	tally := Observe(Outcome{Answered: true, Correct: true, Points: 1, Score: 1, MaxScore: 1, TimeSpentMs: &spent}).
+		Add(Observe(Outcome{MaxScore: 1}))
+
+	stats := models.ItemStats{Answers: []models.AnswerStats{{Selections: 1}, {Selections: 0}}}
+	tally.Describe(&stats)
+
+	if stats.Attempts != 2 || stats.Responses != 1 || stats.Correct != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/1/1", stats.Attempts, stats.Responses, stats.Correct)
+	}
+	if stats.Difficulty == nil || *stats.Difficulty != models.DifficultyMedium {
+		t.Errorf("difficulty = %v, want medium", stats.Difficulty)
+	}
+	if stats.AverageTimeMs == nil || *stats.AverageTimeMs != 3000 {
+		t.Errorf("average time = %v, want 3000", stats.AverageTimeMs)
+	}
+	if stats.Answers[0].Rate == nil || *stats.Answers[0].Rate != 0.5 || *stats.Answers[1].Rate != 0 {
+		t.Errorf("answer rates = %+v, want 0.5 and 0", stats.Answers)
+	}
+}
+
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i] / n
+		my += ys[i] / n
+	}
+	var cov, vx, vy float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+		vx += (xs[i] - mx) * (xs[i] - mx)
+		vy += (ys[i] - my) * (ys[i] - my)
+	}
+	return cov / math.Sqrt(vx*vy)
+}
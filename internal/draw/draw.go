@@ -0,0 +1,68 @@
+// Package draw assembles the questions of an attempt from a quiz's own
+// questions and its draw rules. It holds no storage code, so every
+// repository implementation draws the same questions for the same seed.
+package draw
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// Assemble returns the question IDs of an attempt: fixed, in order, then for
+// each rule in turn Count questions picked at random from pools at the same
+// index. A pool holds the IDs of the bank questions that match its rule, in
+// any order. The picks depend only on seed and the IDs given, so the same
+// seed always reproduces the same attempt. No question is picked twice, even
+// when pools overlap.
+func Assemble(seed int64, fixed []int, rules []models.DrawRule, pools [][]int) ([]int, error) {
+	random := rand.New(rand.NewSource(seed))
+	questions := make([]int, 0, len(fixed))
+	picked := make(map[int]bool, len(fixed))
+	for _, id := range fixed {
+		questions = append(questions, id)
+		picked[id] = true
+	}
+
+	for i, rule := range rules {
+		var available []int
+		for _, id := range pools[i] {
+			if !picked[id] {
+				available = append(available, id)
+			}
+		}
+		if len(available) < rule.Count {
+			return nil, fmt.Errorf("draw rule %d needs %d questions but bank %d has only %d to draw from",
+				i+1, rule.Count, rule.BankID, len(available))
+		}
+
+		sort.Ints(available)
+		random.Shuffle(len(available), func(a, b int) { available[a], available[b] = available[b], available[a] })
+		for _, id := range available[:rule.Count] {
+			questions = append(questions, id)
+			picked[id] = true
+		}
+	}
+
+	return questions, nil
+}
+
+// Pool returns every question an attempt could be given: fixed and all of
+// pools, each once and in ID order. Adaptive attempts ask from it rather than
+// drawing.
+func Pool(fixed []int, pools [][]int) []int {
+	seen := make(map[int]bool, len(fixed))
+	var questions []int
+	for _, ids := range append([][]int{fixed}, pools...) {
+		for _, id := range ids {
+			if !seen[id] {
+				questions = append(questions, id)
+				seen[id] = true
+			}
+		}
+	}
+	sort.Ints(questions)
+	return questions
+}
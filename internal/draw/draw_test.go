@@ -0,0 +1,62 @@
+package draw
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestAssembleIsReproducible(t *testing.T) {
+	rules := []models.DrawRule{{BankID: 1, Count: 3}, {BankID: 2, Count: 2}}
+	pools := [][]int{{10, 11, 12, 13, 14, 15}, {20, 21, 22, 12}}
+
+	first, err := Assemble(42, []int{1, 2}, rules, pools)
+	if err != nil {
+		t.Fatalf("Assemble returned error: %v", err)
+	}
+	if len(first) != 7 || first[0] != 1 || first[1] != 2 {
+		t.Fatalf("questions = %v, want the two fixed questions then five drawn", first)
+	}
+	seen := make(map[int]bool)
+	for _, id := range first {
+		if seen[id] {
+			t.Fatalf("question %d drawn twice in %v", id, first)
+		}
+		seen[id] = true
+	}
+
+	// Pool order does not matter, only the seed and the IDs
+	reversed := [][]int{{15, 14, 13, 12, 11, 10}, {12, 22, 21, 20}}
+	again, err := Assemble(42, []int{1, 2}, rules, reversed)
+	if err != nil {
+		t.Fatalf("Assemble returned error: %v", err)
+	}
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed drew %v, then %v", first, again)
+	}
+
+	differs := false
+	for seed := int64(1); seed <= 20 && !differs; seed++ {
+		other, _ := Assemble(seed, []int{1, 2}, rules, pools)
+		differs = !reflect.DeepEqual(first, other)
+	}
+	if !differs {
+		t.Error("every seed drew the same questions")
+	}
+}
+
+func TestAssembleShortPool(t *testing.T) {
+	rules := []models.DrawRule{{BankID: 1, Count: 2}, {BankID: 1, Count: 2}}
+	_, err := Assemble(1, nil, rules, [][]int{{1, 2, 3}, {1, 2, 3}})
+	if err == nil {
+		t.Fatal("expected an error when a rule cannot be filled")
+	}
+}
+
+func TestPool(t *testing.T) {
+	got := Pool([]int{5, 2}, [][]int{{7, 2}, {1, 7}})
+	if want := []int{1, 2, 5, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pool = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,292 @@
+// Package irt holds the item response theory behind adaptive attempts: the
+// two-parameter logistic model, estimating a player's ability from their
+// responses, picking the question that tells the most about it, and
+// calibrating questions from past responses. A one-parameter model is the
+// same with every discrimination fixed at 1. It holds no storage code, so
+// every repository implementation adapts the same way.
+package irt
+
+import (
+	"math"
+	"sort"
+)
+
+// Abilities and difficulties share a scale on which the players calibrated
+// against have a mean of 0 and a standard deviation of 1. Estimates are kept
+// within MinAbility and MaxAbility.
+const (
+	MinAbility = -4.0
+	MaxAbility = 4.0
+)
+
+// Item is a calibrated question.
+type Item struct {
+	ID             int
+	Difficulty     float64
+	Discrimination float64
+}
+
+// Uncalibrated is the item assumed for a question with no calibration: of
+// average difficulty, and as discriminating as the one-parameter model
+// assumes every question is.
+func Uncalibrated(id int) Item {
+	return Item{ID: id, Difficulty: 0, Discrimination: 1}
+}
+
+// Probability is the chance a player of ability theta answers the item
+// correctly.
+func (i Item) Probability(theta float64) float64 {
+	return 1 / (1 + math.Exp(-i.Discrimination*(theta-i.Difficulty)))
+}
+
+// Information is how much a response to the item tells about an ability of
+// theta. It peaks where theta is the item's difficulty.
+func (i Item) Information(theta float64) float64 {
+	p := i.Probability(theta)
+	return i.Discrimination * i.Discrimination * p * (1 - p)
+}
+
+// Response is whether a player answered an item correctly. Unanswered
+// counts as wrong.
+type Response struct {
+	Item    Item
+	Correct bool
+}
+
+// quadrature is the grid of abilities Estimate integrates over.
+var quadrature = grid(160)
+
+// grid spreads points+1 abilities evenly from MinAbility to MaxAbility.
+func grid(points int) []float64 {
+	abilities := make([]float64, points+1)
+	for i := range abilities {
+		abilities[i] = MinAbility + (MaxAbility-MinAbility)*float64(i)/float64(points)
+	}
+	return abilities
+}
+
+// Estimate returns the expected a posteriori ability given the responses,
+// with a standard normal prior, and its standard error. The prior keeps the
+// estimate finite when every response is right or every one is wrong, and
+// with no responses the estimate is the prior's: 0 with an error of about 1.
+func Estimate(responses []Response) (theta float64, standardError float64) {
+	weights := posterior(quadrature, responses)
+	for i, w := range weights {
+		theta += quadrature[i] * w
+	}
+
+	variance := 0.0
+	for i, w := range weights {
+		variance += (quadrature[i] - theta) * (quadrature[i] - theta) * w
+	}
+	return theta, math.Sqrt(variance)
+}
+
+// posterior returns how likely each of abilities is given the responses
+// and a standard normal prior, scaled to add up to 1.
+func posterior(abilities []float64, responses []Response) []float64 {
+	weights := make([]float64, len(abilities))
+	peak := math.Inf(-1)
+	for i, theta := range abilities {
+		// Work in logs so long attempts do not underflow
+		logWeight := -theta * theta / 2
+		for _, response := range responses {
+			p := response.Item.Probability(theta)
+			if response.Correct {
+				logWeight += math.Log(math.Max(p, 1e-300))
+			} else {
+				logWeight += math.Log(math.Max(1-p, 1e-300))
+			}
+		}
+		weights[i] = logWeight
+		peak = math.Max(peak, logWeight)
+	}
+
+	total := 0.0
+	for i, w := range weights {
+		weights[i] = math.Exp(w - peak)
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// MostInformative returns the item that tells the most about an ability of
+// theta, the lowest ID among equals. It reports false when there are no
+// items.
+func MostInformative(theta float64, items []Item) (Item, bool) {
+	var best Item
+	bestInformation := -1.0
+	for _, item := range items {
+		information := item.Information(theta)
+		if information > bestInformation || information == bestInformation && item.ID < best.ID {
+			best, bestInformation = item, information
+		}
+	}
+	return best, bestInformation >= 0
+}
+
+// StopRule says when an adaptive attempt has asked enough: after MaxItems
+// questions, or once the standard error is down to TargetSE and at least
+// MinItems have been asked.
+type StopRule struct {
+	MinItems int
+	MaxItems int
+	TargetSE float64
+}
+
+// Step is where an adaptive attempt stands: the ability estimate so far and
+// the item to ask next, or none when the attempt is done.
+type Step struct {
+	Ability       float64
+	StandardError float64
+	Next          *Item
+}
+
+// Next estimates the ability shown by the responses so far and, unless rule
+// says to stop or nothing is left to ask, picks the next item among
+// remaining.
+func Next(rule StopRule, responses []Response, remaining []Item) Step {
+	ability, standardError := Estimate(responses)
+	step := Step{Ability: ability, StandardError: standardError}
+
+	asked := len(responses)
+	if asked >= rule.MaxItems || asked >= rule.MinItems && standardError <= rule.TargetSE {
+		return step
+	}
+	if item, ok := MostInformative(ability, remaining); ok {
+		step.Next = &item
+	}
+	return step
+}
+
+// Observation is one past response: whether the player in Person, one
+// sitting of a quiz, answered Item correctly.
+type Observation struct {
+	Person  int
+	Item    int
+	Correct bool
+}
+
+// Calibration is an item's parameters fitted from Responses observations.
+type Calibration struct {
+	Item
+	Responses int
+}
+
+// Priors keep calibration stable on the small samples it usually has:
+// difficulties are drawn towards 0 and discriminations towards 1, and
+// discriminations stay between MinDiscrimination and MaxDiscrimination.
+const (
+	difficultyPriorVariance     = 4.0
+	discriminationPriorVariance = 0.25
+	MinDiscrimination           = 0.2
+	MaxDiscrimination           = 4.0
+)
+
+// Calibrate fits the difficulty of every observed item, and its
+// discrimination too when twoParameter is set. It maximises the marginal
+// likelihood with the EM algorithm: rather than estimating each person's
+// ability, which a few responses cannot pin down, it integrates over a
+// standard normal distribution of abilities. Results are ordered by item ID.
+func Calibrate(observations []Observation, twoParameter bool) []Calibration {
+	byPerson := make(map[int][]Observation)
+	counts := make(map[int]int)
+	for _, o := range observations {
+		byPerson[o.Person] = append(byPerson[o.Person], o)
+		counts[o.Item]++
+	}
+	persons := sortedKeys(byPerson)
+	itemIDs := sortedKeys(counts)
+
+	// A coarser grid than Estimate's is plenty here
+	abilities := grid(40)
+
+	items := make(map[int]Item, len(itemIDs))
+	for _, id := range itemIDs {
+		items[id] = Uncalibrated(id)
+	}
+
+	for iteration := 0; iteration < 200; iteration++ {
+		// E step: how many people at each ability saw each item, and how
+		// many of them answered it correctly
+		seen := make(map[int][]float64, len(itemIDs))
+		right := make(map[int][]float64, len(itemIDs))
+		for _, id := range itemIDs {
+			seen[id] = make([]float64, len(abilities))
+			right[id] = make([]float64, len(abilities))
+		}
+
+		for _, person := range persons {
+			responses := make([]Response, 0, len(byPerson[person]))
+			for _, o := range byPerson[person] {
+				responses = append(responses, Response{Item: items[o.Item], Correct: o.Correct})
+			}
+			weights := posterior(abilities, responses)
+			for _, o := range byPerson[person] {
+				for k, w := range weights {
+					seen[o.Item][k] += w
+					if o.Correct {
+						right[o.Item][k] += w
+					}
+				}
+			}
+		}
+
+		// M step: Newton's method on each item's parameters, with priors
+		change := 0.0
+		for _, id := range itemIDs {
+			item := items[id]
+			for step := 0; step < 10; step++ {
+				gradient, curvature := -item.Difficulty/difficultyPriorVariance, -1/difficultyPriorVariance
+				for k, theta := range abilities {
+					p := item.Probability(theta)
+					gradient -= item.Discrimination * (right[id][k] - seen[id][k]*p)
+					curvature -= item.Discrimination * item.Discrimination * seen[id][k] * p * (1 - p)
+				}
+				item.Difficulty = clamp(item.Difficulty-gradient/curvature, MinAbility, MaxAbility)
+
+				if !twoParameter {
+					continue
+				}
+				gradient = -(item.Discrimination - 1) / discriminationPriorVariance
+				curvature = -1 / discriminationPriorVariance
+				for k, theta := range abilities {
+					distance := theta - item.Difficulty
+					p := item.Probability(theta)
+					gradient += distance * (right[id][k] - seen[id][k]*p)
+					curvature -= distance * distance * seen[id][k] * p * (1 - p)
+				}
+				item.Discrimination = clamp(item.Discrimination-gradient/curvature, MinDiscrimination, MaxDiscrimination)
+			}
+			change = math.Max(change, math.Abs(item.Difficulty-items[id].Difficulty))
+			change = math.Max(change, math.Abs(item.Discrimination-items[id].Discrimination))
+			items[id] = item
+		}
+
+		if change < 1e-5 {
+			break
+		}
+	}
+
+	calibrations := make([]Calibration, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		calibrations = append(calibrations, Calibration{Item: items[id], Responses: counts[id]})
+	}
+	return calibrations
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,175 @@
+package irt
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	theta, se := Estimate(nil)
+	if math.Abs(theta) > 1e-9 || math.Abs(se-1) > 0.01 {
+		t.Errorf("with no responses got %v ± %v, want the prior 0 ± 1", theta, se)
+	}
+
+	item := Uncalibrated(1)
+	right, rightSE := Estimate([]Response{{item, true}})
+	wrong, _ := Estimate([]Response{{item, false}})
+	if right <= 0 || wrong >= 0 || math.Abs(right+wrong) > 1e-9 {
+		t.Errorf("one right gave %v, one wrong %v; want opposite signs of equal size", right, wrong)
+	}
+	if rightSE >= se {
+		t.Errorf("standard error %v after a response, want less than %v", rightSE, se)
+	}
+
+	// Every response right still gives a finite estimate
+	var allRight []Response
+	for i := 0; i < 30; i++ {
+		allRight = append(allRight, Response{Item{ID: i, Difficulty: 1, Discrimination: 1.5}, true})
+	}
+	if theta, _ := Estimate(allRight); theta <= 1 || theta > MaxAbility {
+		t.Errorf("all right gave %v, want above the items' difficulty and within range", theta)
+	}
+}
+
+func TestMostInformative(t *testing.T) {
+	items := []Item{
+		{ID: 1, Difficulty: -2, Discrimination: 1},
+		{ID: 2, Difficulty: 0.8, Discrimination: 1},
+		{ID: 3, Difficulty: 3, Discrimination: 1},
+		{ID: 4, Difficulty: 2.5, Discrimination: 0.3},
+	}
+	if item, ok := MostInformative(1, items); !ok || item.ID != 2 {
+		t.Errorf("picked %+v, want the item closest in difficulty", item)
+	}
+
+	// Equally informative items go by ID
+	if item, _ := MostInformative(0, []Item{Uncalibrated(9), Uncalibrated(4)}); item.ID != 4 {
+		t.Errorf("picked %d among equals, want 4", item.ID)
+	}
+	if _, ok := MostInformative(0, nil); ok {
+		t.Error("picked an item from none")
+	}
+}
+
+func TestNextStops(t *testing.T) {
+	rule := StopRule{MinItems: 2, MaxItems: 3, TargetSE: 0.9}
+	pool := []Item{Uncalibrated(1), Uncalibrated(2), Uncalibrated(3), Uncalibrated(4)}
+
+	if step := Next(rule, nil, pool); step.Next == nil || step.Next.ID != 1 {
+		t.Fatalf("first step = %+v, want item 1", step)
+	}
+
+	// The error is below target after one response, but two are required
+	sharp := Item{ID: 1, Difficulty: 0, Discrimination: 4}
+	if step := Next(rule, []Response{{sharp, true}}, pool[1:]); step.StandardError > 0.9 || step.Next == nil {
+		t.Errorf("step after one response = %+v, want another item", step)
+	}
+	if step := Next(rule, []Response{{sharp, true}, {sharp, false}}, pool[2:]); step.Next != nil {
+		t.Errorf("step = %+v, want to stop once the error is on target", step)
+	}
+
+	responses := []Response{{pool[0], true}, {pool[1], false}, {pool[2], true}}
+	if step := Next(StopRule{MaxItems: 3, TargetSE: 0.01}, responses, pool[3:]); step.Next != nil {
+		t.Errorf("step = %+v, want to stop at the maximum length", step)
+	}
+	if step := Next(StopRule{MaxItems: 10, TargetSE: 0.01}, responses, nil); step.Next != nil {
+		t.Errorf("step = %+v, want to stop with nothing left to ask", step)
+	}
+}
+
+// simulate answers items as players of the given abilities would, with a
+// fixed seed so the test is repeatable.
+func simulate(abilities []float64, items []Item) []Observation {
+	random := rand.New(rand.NewSource(7))
+	var observations []Observation
+	for person, theta := range abilities {
+		for _, item := range items {
+			observations = append(observations, Observation{
+				Person:  person,
+				Item:    item.ID,
+				Correct: random.Float64() < item.Probability(theta),
+			})
+		}
+	}
+	return observations
+}
+
+func TestCalibrateRecoversDifficulty(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	abilities := make([]float64, 400)
+	for i := range abilities {
+		abilities[i] = random.NormFloat64()
+	}
+	items := []Item{
+		{ID: 1, Difficulty: -1.5, Discrimination: 1},
+		{ID: 2, Difficulty: 0, Discrimination: 1},
+		{ID: 3, Difficulty: 1.5, Discrimination: 1},
+	}
+
+	calibrations := Calibrate(simulate(abilities, items), false)
+	if len(calibrations) != 3 {
+		t.Fatalf("got %d calibrations, want 3", len(calibrations))
+	}
+	for i, c := range calibrations {
+		if c.ID != items[i].ID || c.Responses != len(abilities) || c.Discrimination != 1 {
+			t.Errorf("calibration %+v, want item %d from %d responses with discrimination 1", c, items[i].ID, len(abilities))
+		}
+		if math.Abs(c.Difficulty-items[i].Difficulty) > 0.4 {
+			t.Errorf("item %d difficulty = %v, want about %v", c.ID, c.Difficulty, items[i].Difficulty)
+		}
+	}
+}
+
+func TestCalibrateTwoParameter(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	abilities := make([]float64, 600)
+	for i := range abilities {
+		abilities[i] = random.NormFloat64()
+	}
+	items := []Item{
+		{ID: 1, Difficulty: 0, Discrimination: 0.5},
+		{ID: 2, Difficulty: 0, Discrimination: 2},
+		{ID: 3, Difficulty: 0.5, Discrimination: 1},
+	}
+
+	calibrations := Calibrate(simulate(abilities, items), true)
+	if calibrations[0].Discrimination >= calibrations[2].Discrimination ||
+		calibrations[2].Discrimination >= calibrations[1].Discrimination {
+		t.Errorf("discriminations = %v, %v, %v; want them in the order simulated",
+			calibrations[0].Discrimination, calibrations[1].Discrimination, calibrations[2].Discrimination)
+	}
+}
+
+func TestAdaptiveSessionConverges(t *testing.T) {
+	var pool []Item
+	for i := 0; i < 60; i++ {
+		pool = append(pool, Item{ID: i + 1, Difficulty: -3 + 6*float64(i)/59, Discrimination: 1.5})
+	}
+
+	random := rand.New(rand.NewSource(3))
+	rule := StopRule{MinItems: 5, MaxItems: 40, TargetSE: 0.35}
+	for _, truth := range []float64{-1.5, 0.5, 2} {
+		remaining := append([]Item{}, pool...)
+		var responses []Response
+		step := Next(rule, responses, remaining)
+		for step.Next != nil {
+			item := *step.Next
+			responses = append(responses, Response{item, random.Float64() < item.Probability(truth)})
+			for i := range remaining {
+				if remaining[i].ID == item.ID {
+					remaining = append(remaining[:i], remaining[i+1:]...)
+					break
+				}
+			}
+			step = Next(rule, responses, remaining)
+		}
+
+		if len(responses) > rule.MaxItems || step.StandardError > rule.TargetSE && len(responses) < rule.MaxItems {
+			t.Errorf("ability %v: stopped after %d items at error %v", truth, len(responses), step.StandardError)
+		}
+		if math.Abs(step.Ability-truth) > 3*step.StandardError {
+			t.Errorf("ability %v: estimated %v ± %v", truth, step.Ability, step.StandardError)
+		}
+	}
+}
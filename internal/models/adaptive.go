@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Item response theory models an adaptive quiz can calibrate its questions
+// with. The one-parameter model fits only difficulty; the two-parameter one
+// also fits how sharply each question tells players apart.
+const (
+	AdaptiveModel1PL = "1pl"
+	AdaptiveModel2PL = "2pl"
+)
+
+// AdaptiveSettings make a quiz adaptive. Rather than being given every
+// question up front, each attempt is asked one question at a time, always
+// the one that tells the most about the player's ability estimate so far,
+// until the estimate's standard error is down to TargetSE with at least
+// MinQuestions asked, or MaxQuestions have been asked. Questions come from
+// the quiz's own and from every question its draw rules could pick.
+type AdaptiveSettings struct {
+	Model        string  `json:"model" db:"model" binding:"required,oneof=1pl 2pl"`
+	MinQuestions int     `json:"min_questions" db:"min_questions" binding:"omitempty,min=1"`
+	MaxQuestions int     `json:"max_questions" db:"max_questions" binding:"required,min=1"`
+	TargetSE     float64 `json:"target_standard_error" db:"target_se" binding:"required,gt=0"`
+}
+
+// Calibration is where a question sits on the ability scale: players of
+// ability Difficulty answer it correctly half the time, and Discrimination
+// is how quickly that chance rises with ability. It is fitted from
+// Responses past responses. Questions never calibrated have a difficulty of
+// 0, a discrimination of 1 and no CalibratedAt.
+type Calibration struct {
+	QuestionID     int        `json:"question_id" db:"question_id"`
+	Difficulty     float64    `json:"difficulty" db:"difficulty"`
+	Discrimination float64    `json:"discrimination" db:"discrimination"`
+	Responses      int        `json:"responses" db:"responses"`
+	CalibratedAt   *time.Time `json:"calibrated_at" db:"calibrated_at"`
+}
+
+// AdaptiveStep is where an adaptive attempt stands after the responses so
+// far: the ability estimate, on the scale questions are calibrated on, and
+// the question to answer next. Once Done there is no question and the
+// attempt can be submitted.
+type AdaptiveStep struct {
+	Done          bool            `json:"done"`
+	Answered      int             `json:"answered"`
+	Ability       float64         `json:"ability"`
+	StandardError float64         `json:"standard_error"`
+	Question      *PlayerQuestion `json:"question,omitempty"`
+}
@@ -1,8 +1,21 @@
 package models
 
+// Answer is one option of a question. Position is its place in the correct
+// order of an ordering question, and Match is what it pairs with in a
+// matching question; both are nil for other types. Rationale says why the
+// answer is right or wrong, and is only shown once an attempt is submitted.
 type Answer struct {
-	ID         int    `json:"id"`
-	QuestionID int    `json:"question_id"`
-	Answer     string `json:"answer"`
-	Correct    bool   `json:"is_correct"`
+	ID         int     `json:"id" db:"id"`
+	QuestionID int     `json:"question_id" db:"question_id"`
+	Answer     string  `json:"answer" db:"answer"`
+	Correct    bool    `json:"is_correct" db:"is_correct"`
+	Position   *int    `json:"position,omitempty" db:"position"`
+	Match      *string `json:"match,omitempty" db:"match_text"`
+	Rationale  *string `json:"rationale,omitempty" db:"rationale"`
+}
+
+// ToDraft returns the parts of an answer the rules of its question's type
+// look at.
+func (a Answer) ToDraft() AnswerDraft {
+	return AnswerDraft{Answer: a.Answer, Correct: a.Correct, Position: a.Position, Match: a.Match}
 }
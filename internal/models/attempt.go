@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+const (
+	AttemptInProgress = "in_progress"
+	AttemptSubmitted  = "submitted"
+)
+
+// Attempt is one sitting of a quiz. QuestionIDs are the questions it was
+// given, in order: the quiz's own questions, then those drawn from banks with
+// Seed. Adaptive attempts are instead given questions one at a time, and on
+// submit report the player's Ability and its StandardError. Listings leave
+// QuestionIDs and Responses out.
+type Attempt struct {
+	ID            int               `json:"id"`
+	QuizID        int               `json:"quiz_id"`
+	UserID        string            `json:"user_id"`
+	Status        string            `json:"status"`
+	Seed          int64             `json:"seed"`
+	Adaptive      bool              `json:"adaptive"`
+	Score         *float64          `json:"score"`
+	MaxScore      *float64          `json:"max_score"`
+	Ability       *float64          `json:"ability"`
+	StandardError *float64          `json:"standard_error"`
+	StartedAt     time.Time         `json:"started_at"`
+	SubmittedAt   *time.Time        `json:"submitted_at"`
+	QuestionIDs   []int             `json:"question_ids,omitempty"`
+	Responses     []AttemptResponse `json:"responses,omitempty"`
+}
+
+// AttemptStart is the body accepted when starting an attempt. Players cannot
+// pick the seed, or they could start again until they drew the questions
+// they wanted: the server always chooses one at random.
+type AttemptStart struct {
+	UserID string `json:"user_id"`
+}
+
+// AttemptResponse holds the answers a player selected for one question, or
+// what they typed for a text question: one value per blank for
+// fill_in_blank, otherwise a single value. IsCorrect and Points stay nil
+// until the attempt is submitted and graded; Points has the penalty for
+// HintsUsed taken off, IsCorrect does not. TimeSpentMs is how long the
+// player reported spending on the question, added up over every time they
+// responded to it. Feedback is only filled in once the attempt is submitted.
+type AttemptResponse struct {
+	ID          int       `json:"id"`
+	AttemptID   int       `json:"attempt_id"`
+	QuestionID  int       `json:"question_id"`
+	AnswerIDs   []int64   `json:"answer_ids"`
+	Text        []string  `json:"text,omitempty"`
+	IsCorrect   *bool     `json:"is_correct"`
+	Points      *float64  `json:"points"`
+	TimeSpentMs *int64    `json:"time_spent_ms"`
+	HintsUsed   int       `json:"hints_used"`
+	Feedback    *Feedback `json:"feedback,omitempty"`
+	RespondedAt time.Time `json:"responded_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// Bank is a pool of questions that quizzes draw from instead of owning them.
+type Bank struct {
+	ID          int     `json:"id" db:"id"`
+	Title       string  `json:"title" db:"title"`
+	Description *string `json:"description" db:"description"`
+}
+
+// BankInput is the body accepted when creating a bank or replacing one with
+// PUT. A missing description is stored as null.
+type BankInput struct {
+	Title       string  `json:"title" binding:"required"`
+	Description *string `json:"description"`
+}
+
+// DrawRule adds Count questions picked at random from a bank to every
+// attempt at a quiz. With a Tag, only the bank's questions with that tag, or
+// a tag beneath it, are picked from. A quiz's rules are applied in Position
+// order, after its own questions.
+type DrawRule struct {
+	ID       int     `json:"id" db:"id"`
+	QuizID   int     `json:"quiz_id" db:"quiz_id"`
+	BankID   int     `json:"bank_id" db:"bank_id"`
+	Position int     `json:"position" db:"position"`
+	Count    int     `json:"count" db:"count"`
+	Tag      *string `json:"tag,omitempty" db:"tag"`
+}
+
+// DrawRuleDraft is a draw rule as written by an author. A quiz's rules are
+// always given as a whole list, whose order sets their positions.
+type DrawRuleDraft struct {
+	BankID int     `json:"bank_id" binding:"required,min=1"`
+	Count  int     `json:"count" binding:"required,min=1"`
+	Tag    *string `json:"tag"`
+}
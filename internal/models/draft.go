@@ -0,0 +1,47 @@
+package models
+
+// QuizDraft describes a complete quiz to be created in one go: the quiz, its
+// questions and their answers.
+type QuizDraft struct {
+	Title       string          `json:"title" binding:"required"`
+	Description *string         `json:"description"`
+	Questions   []QuestionDraft `json:"questions" binding:"dive"`
+}
+
+type QuestionDraft struct {
+	Question    string          `json:"question" binding:"required"`
+	Type        string          `json:"type" binding:"required,question_type"`
+	Scoring     string          `json:"scoring" binding:"omitempty,scoring_strategy"`
+	Order       int             `json:"order_num"`
+	Config      *QuestionConfig `json:"config"`
+	Explanation *string         `json:"explanation"`
+	Answers     []AnswerDraft   `json:"answers" binding:"dive"`
+	Hints       []HintDraft     `json:"hints" binding:"dive"`
+	Tags        []string        `json:"tags" binding:"dive,required,max=100"`
+}
+
+// ToQuestion returns the question the draft describes, without its answers
+// and hints.
+func (d QuestionDraft) ToQuestion() Question {
+	return Question{Question: d.Question, Type: d.Type, Scoring: d.Scoring, Order: d.Order, Config: d.Config, Explanation: d.Explanation}
+}
+
+type AnswerDraft struct {
+	Answer    string  `json:"answer" binding:"required"`
+	Correct   bool    `json:"is_correct"`
+	Position  *int    `json:"position" binding:"omitempty,min=1"`
+	Match     *string `json:"match"`
+	Rationale *string `json:"rationale"`
+}
+
+// CreatedQuiz holds the IDs generated when a QuizDraft is stored. Questions
+// and answer IDs are in the same order as in the draft.
+type CreatedQuiz struct {
+	ID        int64             `json:"id"`
+	Questions []CreatedQuestion `json:"questions"`
+}
+
+type CreatedQuestion struct {
+	ID        int64   `json:"id"`
+	AnswerIDs []int64 `json:"answer_ids"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// FieldError describes a single invalid field in a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// FullQuiz is the admin view of a quiz with every question and answer
+// attached, and the hints and tags of each question. Questions are ordered
+// by order_num, hints by tier and tags by name.
+type FullQuiz struct {
+	Quiz
+	Questions []FullQuestion `json:"questions"`
+}
+
+type FullQuestion struct {
+	Question
+	Answers []Answer `json:"answers"`
+	Hints   []Hint   `json:"hints"`
+	Tags    []string `json:"tags"`
+}
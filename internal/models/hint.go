@@ -0,0 +1,42 @@
+package models
+
+// Hint is one tier of help for a question. Tiers start at 1 and are revealed
+// in order; each one revealed during an attempt takes Penalty points off what
+// the question earns, down to zero.
+type Hint struct {
+	ID         int     `json:"id" db:"id"`
+	QuestionID int     `json:"question_id" db:"question_id"`
+	Tier       int     `json:"tier" db:"tier"`
+	Hint       string  `json:"hint" db:"hint"`
+	Penalty    float64 `json:"penalty" db:"penalty"`
+}
+
+// HintDraft is a hint as written by an author. A question's hints are always
+// given as a whole list, whose order sets their tiers.
+type HintDraft struct {
+	Hint    string  `json:"hint" binding:"required"`
+	Penalty float64 `json:"penalty" binding:"min=0,max=1"`
+}
+
+// RevealedHint is what a player gets when they ask for the next hint to a
+// question. Remaining is how many tiers are still hidden.
+type RevealedHint struct {
+	QuestionID int     `json:"question_id"`
+	Tier       int     `json:"tier"`
+	Hint       string  `json:"hint"`
+	Penalty    float64 `json:"penalty"`
+	Remaining  int     `json:"remaining"`
+}
+
+// Feedback explains a question to a player once their attempt is submitted.
+// Answers lists every answer option, not just the ones they picked.
+type Feedback struct {
+	Explanation *string          `json:"explanation,omitempty"`
+	Answers     []AnswerFeedback `json:"answers"`
+}
+
+type AnswerFeedback struct {
+	ID        int     `json:"id"`
+	Correct   bool    `json:"is_correct"`
+	Rationale *string `json:"rationale,omitempty"`
+}
@@ -0,0 +1,127 @@
+package models
+
+// QuizInput is the body accepted when creating a quiz or replacing one with
+// PUT. A missing description is stored as null.
+type QuizInput struct {
+	Title       string  `json:"title" binding:"required"`
+	Description *string `json:"description"`
+}
+
+// QuizPatch is a JSON Merge Patch for a quiz. Only description may be cleared
+// with null.
+type QuizPatch struct {
+	Title       Optional[string] `json:"title"`
+	Description Optional[string] `json:"description"`
+}
+
+func (p QuizPatch) Validate() []FieldError {
+	var errs []FieldError
+	if p.Title.Null {
+		errs = append(errs, FieldError{Field: "title", Message: "cannot be null"})
+	} else if p.Title.Set && p.Title.Value == "" {
+		errs = append(errs, FieldError{Field: "title", Message: "must not be empty"})
+	}
+
+	return errs
+}
+
+// QuestionInput is the body accepted when creating a question or replacing
+// one with PUT. QuizID comes from the URL path rather than the body. An empty
+// Scoring selects the type's default strategy. Config is required for text
+// question types. Hints are edited on their own, so they are not part of it.
+type QuestionInput struct {
+	QuizID      int             `json:"-"`
+	Question    string          `json:"question" binding:"required"`
+	Type        string          `json:"type" binding:"required,question_type"`
+	Scoring     string          `json:"scoring" binding:"omitempty,scoring_strategy"`
+	Order       int             `json:"order_num" binding:"min=0"`
+	Config      *QuestionConfig `json:"config"`
+	Explanation *string         `json:"explanation"`
+}
+
+// ToQuestion returns the question the input describes.
+func (i QuestionInput) ToQuestion() Question {
+	quizID := i.QuizID
+	return Question{QuizID: &quizID, Question: i.Question, Type: i.Type, Scoring: i.Scoring, Order: i.Order, Config: i.Config, Explanation: i.Explanation}
+}
+
+// QuestionPatch is a JSON Merge Patch for a question. Config and
+// explanation are nullable; clearing config is how a question changes to a
+// type that has answers.
+type QuestionPatch struct {
+	Question    Optional[string]         `json:"question"`
+	Type        Optional[string]         `json:"type"`
+	Scoring     Optional[string]         `json:"scoring"`
+	Order       Optional[int]            `json:"order_num"`
+	Config      Optional[QuestionConfig] `json:"config"`
+	Explanation Optional[string]         `json:"explanation"`
+}
+
+func (p QuestionPatch) Validate() []FieldError {
+	var errs []FieldError
+	if p.Question.Null {
+		errs = append(errs, FieldError{Field: "question", Message: "cannot be null"})
+	} else if p.Question.Set && p.Question.Value == "" {
+		errs = append(errs, FieldError{Field: "question", Message: "must not be empty"})
+	}
+
+	if p.Type.Null {
+		errs = append(errs, FieldError{Field: "type", Message: "cannot be null"})
+	} else if p.Type.Set && !IsKnownQuestionType(p.Type.Value) {
+		errs = append(errs, FieldError{Field: "type", Message: "must be a known question type"})
+	}
+
+	if p.Scoring.Null {
+		errs = append(errs, FieldError{Field: "scoring", Message: "cannot be null"})
+	} else if p.Scoring.Set && !IsKnownScoringStrategy(p.Scoring.Value) {
+		errs = append(errs, FieldError{Field: "scoring", Message: "must be a known scoring strategy"})
+	}
+
+	if p.Order.Null {
+		errs = append(errs, FieldError{Field: "order_num", Message: "cannot be null"})
+	} else if p.Order.Set && p.Order.Value < 0 {
+		errs = append(errs, FieldError{Field: "order_num", Message: "must be at least 0"})
+	}
+
+	return errs
+}
+
+// AnswerInput is the body accepted when creating an answer or replacing one
+// with PUT. QuestionID comes from the URL path rather than the body.
+type AnswerInput struct {
+	QuestionID int     `json:"-"`
+	Answer     string  `json:"answer" binding:"required"`
+	Correct    bool    `json:"is_correct"`
+	Position   *int    `json:"position" binding:"omitempty,min=1"`
+	Match      *string `json:"match"`
+	Rationale  *string `json:"rationale"`
+}
+
+// AnswerPatch is a JSON Merge Patch for an answer. Position, match and
+// rationale are nullable.
+type AnswerPatch struct {
+	Answer    Optional[string] `json:"answer"`
+	Correct   Optional[bool]   `json:"is_correct"`
+	Position  Optional[int]    `json:"position"`
+	Match     Optional[string] `json:"match"`
+	Rationale Optional[string] `json:"rationale"`
+}
+
+func (p AnswerPatch) Validate() []FieldError {
+	var errs []FieldError
+	if p.Answer.Null {
+		errs = append(errs, FieldError{Field: "answer", Message: "cannot be null"})
+	} else if p.Answer.Set && p.Answer.Value == "" {
+		errs = append(errs, FieldError{Field: "answer", Message: "must not be empty"})
+	}
+
+	if p.Correct.Null {
+		errs = append(errs, FieldError{Field: "is_correct", Message: "cannot be null"})
+	}
+
+	if p.Position.IsValue() && p.Position.Value < 1 {
+		errs = append(errs, FieldError{Field: "position", Message: "must be at least 1"})
+	}
+
+	return errs
+}
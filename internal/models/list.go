@@ -0,0 +1,37 @@
+package models
+
+// Page size limits for listings.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// QuizQuery selects a page of quizzes. Sort is a field name, optionally
+// prefixed with "-" for descending order. Cursor continues a previous listing
+// and must be used with the same sort and filters. Tags is a tag expression
+// such as "wcag,level-a|level-aa,!draft": every comma-separated group must
+// match, "|" separates alternatives, and "!" matches what lacks a tag.
+type QuizQuery struct {
+	Limit        int    `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor       string `json:"cursor" form:"cursor"`
+	Sort         string `json:"sort" form:"sort" binding:"omitempty,oneof=id -id title -title"`
+	Title        string `json:"title" form:"title"`
+	Description  string `json:"description" form:"description"`
+	Tags         string `json:"tags" form:"tags"`
+	IncludeTotal bool   `json:"include_total" form:"include_total"`
+}
+
+// QuestionQuery filters a listing of questions. Tags is a tag expression, as
+// for QuizQuery.
+type QuestionQuery struct {
+	Tags string `json:"tags" form:"tags"`
+}
+
+// QuizPage is one page of a quiz listing. NextCursor is null on the last
+// page. Total counts every quiz matching the filters and is only set when
+// requested.
+type QuizPage struct {
+	Data       []Quiz  `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	Total      *int    `json:"total,omitempty"`
+}
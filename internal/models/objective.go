@@ -0,0 +1,98 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Objective is something a question assesses, such as success criterion
+// 1.4.13 of WCAG 2.2. Code is unique within Framework.
+type Objective struct {
+	ID          int     `json:"id" db:"id"`
+	Framework   string  `json:"framework" db:"framework"`
+	Code        string  `json:"code" db:"code"`
+	Title       string  `json:"title" db:"title"`
+	Description *string `json:"description" db:"description"`
+}
+
+// ObjectiveInput is the body accepted when creating an objective or
+// replacing one with PUT. A missing description is stored as null.
+type ObjectiveInput struct {
+	Framework   string  `json:"framework" binding:"required,max=100"`
+	Code        string  `json:"code" binding:"required,max=50"`
+	Title       string  `json:"title" binding:"required,max=255"`
+	Description *string `json:"description"`
+}
+
+// Mastery levels, from a learner's share of the points available on an
+// objective's questions.
+const (
+	MasteryWeak       = "weak"
+	MasteryDeveloping = "developing"
+	MasteryMastered   = "mastered"
+
+	// DevelopingFrom and MasteredFrom are the least shares of the points
+	// that reach each level.
+	DevelopingFrom = 0.5
+	MasteredFrom   = 0.8
+)
+
+// Mastery is how well a learner has done on one objective across their
+// submitted attempts. Every time a question assessing the objective was in
+// an attempt it adds a point to MaxPoints, answered or not, and what the
+// response earned to Points.
+type Mastery struct {
+	Objective
+	Questions int     `json:"questions" db:"questions"`
+	Points    float64 `json:"points" db:"points"`
+	MaxPoints float64 `json:"max_points" db:"max_points"`
+	Score     float64 `json:"score" db:"-"`
+	Level     string  `json:"level" db:"-"`
+}
+
+// Rate fills in Score and Level from Points and MaxPoints.
+func (m *Mastery) Rate() {
+	if m.MaxPoints > 0 {
+		m.Score = m.Points / m.MaxPoints
+	}
+
+	switch {
+	case m.Score >= MasteredFrom:
+		m.Level = MasteryMastered
+	case m.Score >= DevelopingFrom:
+		m.Level = MasteryDeveloping
+	default:
+		m.Level = MasteryWeak
+	}
+}
+
+// MasteryQuery narrows a mastery report to one framework, one quiz, or both.
+type MasteryQuery struct {
+	Framework string `json:"framework" form:"framework"`
+	QuizID    int    `json:"quiz_id" form:"quiz_id" binding:"omitempty,min=1"`
+}
+
+// CompareObjectives orders objectives by framework, then by code with
+// numeric parts compared as numbers, so 1.4.2 comes before 1.4.10.
+func CompareObjectives(a, b Objective) int {
+	if a.Framework != b.Framework {
+		return strings.Compare(a.Framework, b.Framework)
+	}
+
+	aParts, bParts := strings.Split(a.Code, "."), strings.Split(b.Code, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
+		}
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(aParts[i], bParts[i])
+	}
+	return len(aParts) - len(bParts)
+}
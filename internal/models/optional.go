@@ -0,0 +1,34 @@
+package models
+
+import "encoding/json"
+
+// Optional is a patch field that tells apart a key missing from the JSON body,
+// a key set to null, and a key set to a value, as JSON Merge Patch
+// (RFC 7396) requires.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// Some returns an Optional holding value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Set: true, Value: value}
+}
+
+// UnmarshalJSON is only called when the key is present, so any call marks
+// the field as set.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
+
+// IsValue reports whether the field was set to a non-null value.
+func (o Optional[T]) IsValue() bool {
+	return o.Set && !o.Null
+}
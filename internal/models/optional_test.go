@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuizPatchUnmarshal(t *testing.T) {
+	tests := []struct {
+		body        string
+		title       Optional[string]
+		description Optional[string]
+	}{
+		{`{}`, Optional[string]{}, Optional[string]{}},
+		{`{"title": "T"}`, Some("T"), Optional[string]{}},
+		{`{"description": null}`, Optional[string]{}, Optional[string]{Set: true, Null: true}},
+		{`{"title": "T", "description": "D"}`, Some("T"), Some("D")},
+		{`{"title": null, "description": ""}`, Optional[string]{Set: true, Null: true}, Some("")},
+	}
+
+	for _, tt := range tests {
+		var patch QuizPatch
+		if err := json.Unmarshal([]byte(tt.body), &patch); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.body, err)
+		}
+		if patch.Title != tt.title {
+			t.Errorf("%s: title = %+v, want %+v", tt.body, patch.Title, tt.title)
+		}
+		if patch.Description != tt.description {
+			t.Errorf("%s: description = %+v, want %+v", tt.body, patch.Description, tt.description)
+		}
+	}
+}
+
+func TestQuizPatchValidate(t *testing.T) {
+	if errs := (QuizPatch{Description: Optional[string]{Set: true, Null: true}}).Validate(); len(errs) != 0 {
+		t.Errorf("clearing description should be valid, got %v", errs)
+	}
+	if errs := (QuizPatch{Title: Optional[string]{Set: true, Null: true}}).Validate(); len(errs) != 1 {
+		t.Errorf("clearing title should be rejected, got %v", errs)
+	}
+}
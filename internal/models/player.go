@@ -0,0 +1,91 @@
+package models
+
+import "math/rand"
+
+// PlayerQuiz is the representation of a quiz served to people taking it. It
+// mirrors the admin view but never carries correctness or explanation data,
+// so it is safe to hand to any client.
+type PlayerQuiz struct {
+	ID          int              `json:"id"`
+	Title       string           `json:"title"`
+	Description *string          `json:"description"`
+	Questions   []PlayerQuestion `json:"questions"`
+}
+
+// PlayerQuestion carries what a player needs to respond without revealing
+// the question's config: how many blanks to fill in, the unit a number is
+// expected in, the matches to pair answers with, and how many hints can be
+// revealed. Answers to order and matches are shuffled so their order gives
+// nothing away.
+type PlayerQuestion struct {
+	ID       int            `json:"id"`
+	Question string         `json:"question"`
+	Type     string         `json:"type"`
+	Scoring  string         `json:"scoring"`
+	Order    int            `json:"order_num"`
+	Blanks   int            `json:"blanks,omitempty"`
+	Unit     string         `json:"unit,omitempty"`
+	Hints    int            `json:"hints"`
+	Answers  []PlayerAnswer `json:"answers"`
+	Matches  []string       `json:"matches,omitempty"`
+}
+
+type PlayerAnswer struct {
+	ID     int    `json:"id"`
+	Answer string `json:"answer"`
+}
+
+// NewPlayerQuiz builds the player view of a fully loaded quiz.
+func NewPlayerQuiz(quiz FullQuiz) PlayerQuiz {
+	player := PlayerQuiz{
+		ID:          quiz.ID,
+		Title:       quiz.Title,
+		Description: quiz.Description,
+		Questions:   make([]PlayerQuestion, 0, len(quiz.Questions)),
+	}
+
+	for _, question := range quiz.Questions {
+		player.Questions = append(player.Questions, NewPlayerQuestion(question))
+	}
+
+	return player
+}
+
+func NewPlayerQuestion(question FullQuestion) PlayerQuestion {
+	player := PlayerQuestion{
+		ID:       question.ID,
+		Question: question.Question.Question,
+		Type:     question.Type,
+		Scoring:  question.Scoring,
+		Order:    question.Order,
+		Hints:    len(question.Hints),
+		Answers:  make([]PlayerAnswer, 0, len(question.Answers)),
+	}
+	if config := question.Config; config != nil {
+		player.Blanks = len(config.Blanks)
+		player.Unit = config.Unit
+	}
+
+	for _, answer := range question.Answers {
+		player.Answers = append(player.Answers, PlayerAnswer{
+			ID:     answer.ID,
+			Answer: answer.Answer,
+		})
+		if answer.Match != nil {
+			player.Matches = append(player.Matches, *answer.Match)
+		}
+	}
+
+	switch question.Type {
+	case QuestionTypeOrdering:
+		rand.Shuffle(len(player.Answers), func(i, j int) {
+			player.Answers[i], player.Answers[j] = player.Answers[j], player.Answers[i]
+		})
+	case QuestionTypeMatching:
+		rand.Shuffle(len(player.Matches), func(i, j int) {
+			player.Matches[i], player.Matches[j] = player.Matches[j], player.Matches[i]
+		})
+	}
+
+	return player
+}
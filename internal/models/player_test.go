@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewPlayerQuizHidesGrading(t *testing.T) {
+	explanation := "Paris has been the capital since 987"
+	rationale := "Lyon is the third largest city"
+	quiz := FullQuiz{
+		Quiz: Quiz{ID: 1, Title: "Capitals"},
+		Questions: []FullQuestion{
+			{
+				Question: Question{ID: 1, Question: "Capital of France?", Type: QuestionTypeMultipleChoice, Scoring: ScoringAllOrNothing, Order: 1, Explanation: &explanation},
+				Answers: []Answer{
+					{ID: 1, QuestionID: 1, Answer: "Paris", Correct: true},
+					{ID: 2, QuestionID: 1, Answer: "Lyon", Rationale: &rationale},
+				},
+				Hints: []Hint{{ID: 1, QuestionID: 1, Tier: 1, Hint: "On the Seine", Penalty: 0.5}},
+			},
+			{
+				Question: Question{ID: 2, Question: "The capital of Spain is ___", Type: QuestionTypeFillInBlank, Order: 2, Config: &QuestionConfig{
+					Blanks: []TextAnswer{{Accepted: []string{"Madrid"}, Pattern: "^Madr"}},
+				}},
+			},
+		},
+	}
+
+	player := NewPlayerQuiz(quiz)
+	if len(player.Questions) != 2 || player.Questions[0].Hints != 1 || player.Questions[1].Blanks != 1 {
+		t.Fatalf("player view = %+v, want both questions with their hint and blank counts", player)
+	}
+	if answers := player.Questions[0].Answers; len(answers) != 2 || answers[0].ID != 1 || answers[1].Answer != "Lyon" {
+		t.Errorf("answers = %+v, want both options in order", answers)
+	}
+
+	payload, err := json.Marshal(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"is_correct", "explanation", explanation, "rationale", rationale, "On the Seine", "penalty", "Madrid", "^Madr"} {
+		if strings.Contains(string(payload), secret) {
+			t.Errorf("player view %s reveals %q", payload, secret)
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package models
 
+// Question belongs to either a quiz or a bank, so exactly one of QuizID and
+// BankID is set.
 type Question struct {
-	ID       int    `json:"id"`
-	QuizID   int    `json:"quiz_id"`
-	Question string `json:"question"`
-	Type     string `json:"type"`
-	Order    int    `json:"order_num"`
+	ID       int    `json:"id" db:"id"`
+	QuizID   *int   `json:"quiz_id" db:"quiz_id"`
+	BankID   *int   `json:"bank_id,omitempty" db:"bank_id"`
+	Question string `json:"question" db:"question"`
+	Type     string `json:"type" db:"type"`
+	Scoring  string `json:"scoring" db:"scoring"`
+	Order    int    `json:"order_num" db:"order_num"`
+	// Config grades text question types and is nil for the others
+	Config *QuestionConfig `json:"config,omitempty" db:"config"`
+	// Explanation is shown to players once their attempt is submitted
+	Explanation *string `json:"explanation,omitempty" db:"explanation"`
 }
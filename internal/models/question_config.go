@@ -0,0 +1,157 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// QuestionConfig holds what is needed to grade a question that is answered
+// with text rather than by picking answers. Only the fields for the
+// question's type are used.
+type QuestionConfig struct {
+	// short_answer
+	TextAnswer
+
+	// numeric: either Number with an optional Tolerance, or a Min and Max
+	// range. Unit, when set, may follow the number in a response.
+	Number    *float64 `json:"value,omitempty"`
+	Tolerance float64  `json:"tolerance,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Unit      string   `json:"unit,omitempty"`
+
+	// fill_in_blank: one entry per blank, in the order the blanks appear in
+	// the question text
+	Blanks []TextAnswer `json:"blanks,omitempty"`
+}
+
+// TextAnswer is how a typed response is matched. Responses and accepted
+// answers are compared after trimming, collapsing runs of whitespace and,
+// unless CaseSensitive is set, ignoring case. A response Pattern matches in
+// full is accepted too.
+type TextAnswer struct {
+	Accepted      []string `json:"accepted,omitempty"`
+	CaseSensitive bool     `json:"case_sensitive,omitempty"`
+	Pattern       string   `json:"pattern,omitempty"`
+}
+
+// BlankMarker finds the blanks in the text of a fill_in_blank question: three
+// or more underscores.
+var BlankMarker = regexp.MustCompile(`_{3,}`)
+
+// Value stores the config as JSON.
+func (c QuestionConfig) Value() (driver.Value, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// Scan reads a config stored as JSON.
+func (c *QuestionConfig) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, c)
+	case string:
+		return json.Unmarshal([]byte(src), c)
+	default:
+		return errors.New("question config must be JSON")
+	}
+}
+
+// CompilePattern compiles Pattern to match a whole normalized response,
+// ignoring case unless CaseSensitive is set. It compiles afresh on every
+// call, so patterns that are rejected or edited away are not kept in memory.
+func (a TextAnswer) CompilePattern() (*regexp.Regexp, error) {
+	// A pattern such as "a)|(b" is invalid alone but would escape the anchors
+	// once wrapped, so it is checked by itself first.
+	if _, err := regexp.Compile(a.Pattern); err != nil {
+		return nil, err
+	}
+
+	expr := "^(?:" + a.Pattern + ")$"
+	if !a.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+func (a TextAnswer) check() string {
+	if len(a.Accepted) == 0 && a.Pattern == "" {
+		return "needs accepted answers or a pattern"
+	}
+	if a.Pattern != "" {
+		if _, err := a.CompilePattern(); err != nil {
+			return "has a pattern that is not a valid regular expression"
+		}
+	}
+	return ""
+}
+
+func (c QuestionConfig) checkNumeric() string {
+	hasRange := c.Min != nil || c.Max != nil
+	switch {
+	case c.Number == nil && !hasRange:
+		return "needs a value or a min and max"
+	case c.Number != nil && hasRange:
+		return "cannot have both a value and a range"
+	case hasRange && (c.Min == nil || c.Max == nil):
+		return "needs both min and max"
+	case hasRange && *c.Min > *c.Max:
+		return "has a min greater than its max"
+	case c.Tolerance < 0:
+		return "has a negative tolerance"
+	}
+	return ""
+}
+
+// CheckConfig describes the first problem with a question's config, given
+// the question text, or returns "".
+func (t QuestionType) CheckConfig(question string, config *QuestionConfig) string {
+	if t.Respond != RespondByTyping {
+		if config != nil {
+			return fmt.Sprintf("is not used by %s questions", t.Name)
+		}
+		return ""
+	}
+	if config == nil {
+		return fmt.Sprintf("is required for %s questions", t.Name)
+	}
+
+	switch t.Name {
+	case QuestionTypeShortAnswer:
+		return config.TextAnswer.check()
+	case QuestionTypeNumeric:
+		return config.checkNumeric()
+	case QuestionTypeFillInBlank:
+		if len(config.Blanks) == 0 {
+			return "needs at least one blank"
+		}
+		if markers := len(BlankMarker.FindAllString(question, -1)); markers != len(config.Blanks) {
+			return fmt.Sprintf("has %s but the question text has %d", plural(len(config.Blanks), "blank"), markers)
+		}
+		for i, blank := range config.Blanks {
+			if message := blank.check(); message != "" {
+				return fmt.Sprintf("blank %d %s", i+1, message)
+			}
+		}
+	}
+	return ""
+}
+
+// ResponseCount is how many text values a response to a question of this
+// type carries. It is zero for types answered by picking answers.
+func (t QuestionType) ResponseCount(config *QuestionConfig) int {
+	switch {
+	case t.Respond != RespondByTyping:
+		return 0
+	case t.Name == QuestionTypeFillInBlank && config != nil:
+		return len(config.Blanks)
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,262 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeTrueFalse      = "true_false"
+	QuestionTypeMultipleSelect = "multiple_select"
+	QuestionTypeShortAnswer    = "short_answer"
+	QuestionTypeNumeric        = "numeric"
+	QuestionTypeFillInBlank    = "fill_in_blank"
+	QuestionTypeOrdering       = "ordering"
+	QuestionTypeMatching       = "matching"
+)
+
+// How players respond to a question.
+const (
+	// RespondBySelecting picks answers, some of which are correct.
+	RespondBySelecting = "select"
+	// RespondByTyping types text, graded against the question's config.
+	// These questions have no answers.
+	RespondByTyping = "text"
+	// RespondByOrdering puts every answer in order. Each answer holds its
+	// correct position.
+	RespondByOrdering = "order"
+	// RespondByMatching pairs every answer with a match. Each answer holds
+	// its correct match.
+	RespondByMatching = "match"
+)
+
+// Scoring strategies decide how many points a response earns. Every question
+// is worth at most one point.
+const (
+	// ScoringAllOrNothing awards the point only for exactly the correct
+	// selection.
+	ScoringAllOrNothing = "all_or_nothing"
+	// ScoringPartial judges each part on its own: the score is the share of
+	// options that were correctly picked or correctly left alone, of blanks
+	// filled in correctly, of items put in their correct position, or of
+	// pairs matched correctly.
+	ScoringPartial = "partial"
+	// ScoringPenalty awards a share of the point for each correct pick and
+	// takes one away for each wrong pick, never going below zero.
+	ScoringPenalty = "penalty"
+)
+
+// ScoringStrategies lists every scoring strategy.
+var ScoringStrategies = []string{ScoringAllOrNothing, ScoringPartial, ScoringPenalty}
+
+// QuestionType holds the rules for one kind of question.
+type QuestionType struct {
+	Name string
+	// Respond is how players respond, one of the Respond constants.
+	Respond string
+	// Options is the exact number of answers a question must offer. Zero
+	// means at least two.
+	Options int
+	// Correct is the exact number of correct answers for types answered by
+	// selecting. Zero means at least one.
+	Correct int
+	// Strategies are the scoring strategies the type allows. The first is
+	// the default.
+	Strategies []string
+}
+
+// questionTypes is the registry, in the order types are presented to users.
+var questionTypes = []QuestionType{
+	{Name: QuestionTypeMultipleChoice, Respond: RespondBySelecting, Correct: 1, Strategies: []string{ScoringAllOrNothing}},
+	{Name: QuestionTypeTrueFalse, Respond: RespondBySelecting, Options: 2, Correct: 1, Strategies: []string{ScoringAllOrNothing}},
+	{Name: QuestionTypeMultipleSelect, Respond: RespondBySelecting, Strategies: ScoringStrategies},
+	{Name: QuestionTypeShortAnswer, Respond: RespondByTyping, Strategies: []string{ScoringAllOrNothing}},
+	{Name: QuestionTypeNumeric, Respond: RespondByTyping, Strategies: []string{ScoringAllOrNothing}},
+	{Name: QuestionTypeFillInBlank, Respond: RespondByTyping, Strategies: []string{ScoringAllOrNothing, ScoringPartial}},
+	{Name: QuestionTypeOrdering, Respond: RespondByOrdering, Strategies: []string{ScoringAllOrNothing, ScoringPartial}},
+	{Name: QuestionTypeMatching, Respond: RespondByMatching, Strategies: []string{ScoringAllOrNothing, ScoringPartial}},
+}
+
+// QuestionTypes lists the name of every registered question type.
+var QuestionTypes = func() []string {
+	names := make([]string, len(questionTypes))
+	for i, t := range questionTypes {
+		names[i] = t.Name
+	}
+	return names
+}()
+
+// LookupQuestionType returns the registered type called name.
+func LookupQuestionType(name string) (QuestionType, bool) {
+	for _, t := range questionTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return QuestionType{}, false
+}
+
+// IsKnownQuestionType reports whether t is a registered question type.
+func IsKnownQuestionType(t string) bool {
+	_, ok := LookupQuestionType(t)
+	return ok
+}
+
+// IsKnownScoringStrategy reports whether s is one of ScoringStrategies.
+func IsKnownScoringStrategy(s string) bool {
+	for _, known := range ScoringStrategies {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ScoringStrategy resolves the strategy for a question of this type. An
+// empty request selects the default.
+func (t QuestionType) ScoringStrategy(requested string) (string, error) {
+	if requested == "" {
+		return t.Strategies[0], nil
+	}
+
+	for _, s := range t.Strategies {
+		if s == requested {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("must be one of: %s for %s questions", strings.Join(t.Strategies, ", "), t.Name)
+}
+
+// CheckAnswers checks the answers of a question of this type and describes
+// the first rule they break. A question that is still being built one answer
+// at a time is not complete; only rules that adding or editing answers could
+// never fix are applied to it.
+func (t QuestionType) CheckAnswers(answers []AnswerDraft, complete bool) string {
+	if t.Respond == RespondByTyping {
+		if len(answers) > 0 {
+			return fmt.Sprintf("are not used by %s questions", t.Name)
+		}
+		return ""
+	}
+
+	for _, answer := range answers {
+		if answer.Position != nil && t.Respond != RespondByOrdering {
+			return "must not have a position unless the question is ordering"
+		}
+		if answer.Match != nil && t.Respond != RespondByMatching {
+			return "must not have a match unless the question is matching"
+		}
+		if answer.Correct && t.Respond != RespondBySelecting {
+			return fmt.Sprintf("must not be marked correct for %s questions", t.Name)
+		}
+	}
+
+	switch t.Respond {
+	case RespondByOrdering:
+		return checkOrdering(answers, complete)
+	case RespondByMatching:
+		return checkMatching(answers, complete)
+	}
+
+	correct := make([]bool, len(answers))
+	for i, answer := range answers {
+		correct[i] = answer.Correct
+	}
+	return t.checkSelecting(correct, complete)
+}
+
+func (t QuestionType) checkSelecting(correct []bool, complete bool) string {
+	correctCount := 0
+	for _, c := range correct {
+		if c {
+			correctCount++
+		}
+	}
+
+	if t.Options > 0 && len(correct) > t.Options {
+		return fmt.Sprintf("must have exactly %s for %s questions", plural(t.Options, "answer"), t.Name)
+	}
+	if t.Correct > 0 && correctCount > t.Correct {
+		return fmt.Sprintf("must have exactly %s for %s questions", plural(t.Correct, "correct answer"), t.Name)
+	}
+	if !complete {
+		return ""
+	}
+
+	switch {
+	case t.Options > 0 && len(correct) != t.Options:
+		return fmt.Sprintf("must have exactly %s for %s questions", plural(t.Options, "answer"), t.Name)
+	case t.Options == 0 && len(correct) < 2:
+		return "must have at least two answers"
+	case t.Correct > 0 && correctCount != t.Correct:
+		return fmt.Sprintf("must have exactly %s for %s questions", plural(t.Correct, "correct answer"), t.Name)
+	case correctCount == 0:
+		return "must have at least one correct answer"
+	}
+	return ""
+}
+
+// checkOrdering requires every answer to have a position. A complete
+// question numbers its answers 1 to n. Positions may repeat while a question
+// is being edited, so that two answers can swap places one at a time.
+func checkOrdering(answers []AnswerDraft, complete bool) string {
+	for _, answer := range answers {
+		if answer.Position == nil || *answer.Position < 1 {
+			return "must each have a position of at least 1"
+		}
+	}
+	if !complete {
+		return ""
+	}
+
+	if len(answers) < 2 {
+		return "must have at least two answers"
+	}
+	seen := make(map[int]bool, len(answers))
+	for _, answer := range answers {
+		if *answer.Position > len(answers) || seen[*answer.Position] {
+			return fmt.Sprintf("must have positions 1 to %d, each used once", len(answers))
+		}
+		seen[*answer.Position] = true
+	}
+	return ""
+}
+
+// checkMatching requires every answer to have a match. In a complete
+// question no two answers share a match, so players can tell them apart.
+func checkMatching(answers []AnswerDraft, complete bool) string {
+	for _, answer := range answers {
+		if answer.Match == nil || strings.TrimSpace(*answer.Match) == "" {
+			return "must each have a match"
+		}
+	}
+	if !complete {
+		return ""
+	}
+
+	if len(answers) < 2 {
+		return "must have at least two answers"
+	}
+	seen := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		match := strings.ToLower(strings.TrimSpace(*answer.Match))
+		if seen[match] {
+			return "must not share a match"
+		}
+		seen[match] = true
+	}
+	return ""
+}
+
+func plural(n int, noun string) string {
+	words := []string{"zero", "one", "two", "three", "four", "five"}
+	count := fmt.Sprint(n)
+	if n < len(words) {
+		count = words[n]
+	}
+	if n == 1 {
+		return count + " " + noun
+	}
+	return count + " " + noun + "s"
+}
@@ -0,0 +1,89 @@
+package models
+
+import "testing"
+
+func TestQuestionTypeCheckAnswers(t *testing.T) {
+	tests := []struct {
+		questionType string
+		correct      []bool
+		complete     bool
+		valid        bool
+	}{
+		{QuestionTypeMultipleChoice, []bool{true, false, false}, true, true},
+		{QuestionTypeMultipleChoice, []bool{true, true, false}, true, false},
+		{QuestionTypeMultipleChoice, []bool{true, true}, false, false},
+		{QuestionTypeMultipleChoice, []bool{false, false}, true, false},
+		{QuestionTypeMultipleChoice, []bool{false}, false, true},
+		{QuestionTypeTrueFalse, []bool{true, false}, true, true},
+		{QuestionTypeTrueFalse, []bool{true}, true, false},
+		{QuestionTypeTrueFalse, []bool{true}, false, true},
+		{QuestionTypeTrueFalse, []bool{true, false, false}, false, false},
+		{QuestionTypeMultipleSelect, []bool{true, true, false}, true, true},
+		{QuestionTypeMultipleSelect, []bool{true}, true, false},
+		{QuestionTypeMultipleSelect, []bool{true, true, true}, false, true},
+	}
+
+	for _, tt := range tests {
+		questionType, ok := LookupQuestionType(tt.questionType)
+		if !ok {
+			t.Fatalf("%s is not registered", tt.questionType)
+		}
+		answers := make([]AnswerDraft, len(tt.correct))
+		for i, correct := range tt.correct {
+			answers[i] = AnswerDraft{Answer: "Option", Correct: correct}
+		}
+		message := questionType.CheckAnswers(answers, tt.complete)
+		if (message == "") != tt.valid {
+			t.Errorf("%s %v complete=%v: got %q, want valid=%v", tt.questionType, tt.correct, tt.complete, message, tt.valid)
+		}
+	}
+}
+
+func TestQuestionTypeScoringStrategy(t *testing.T) {
+	multipleChoice, _ := LookupQuestionType(QuestionTypeMultipleChoice)
+	if s, err := multipleChoice.ScoringStrategy(""); err != nil || s != ScoringAllOrNothing {
+		t.Errorf("default strategy = %q, %v, want %q", s, err, ScoringAllOrNothing)
+	}
+	if _, err := multipleChoice.ScoringStrategy(ScoringPartial); err == nil {
+		t.Error("multiple_choice accepted partial scoring")
+	}
+
+	multipleSelect, _ := LookupQuestionType(QuestionTypeMultipleSelect)
+	for _, strategy := range ScoringStrategies {
+		if _, err := multipleSelect.ScoringStrategy(strategy); err != nil {
+			t.Errorf("multiple_select rejected %s: %v", strategy, err)
+		}
+	}
+}
+
+func TestOrderingAndMatchingCheckAnswers(t *testing.T) {
+	position := func(n int) *int { return &n }
+	match := func(s string) *string { return &s }
+
+	tests := []struct {
+		name         string
+		questionType string
+		answers      []AnswerDraft
+		complete     bool
+		valid        bool
+	}{
+		{"ordered", QuestionTypeOrdering, []AnswerDraft{{Position: position(2)}, {Position: position(1)}}, true, true},
+		{"gap in positions", QuestionTypeOrdering, []AnswerDraft{{Position: position(1)}, {Position: position(3)}}, true, false},
+		{"repeated position while editing", QuestionTypeOrdering, []AnswerDraft{{Position: position(1)}, {Position: position(1)}}, false, true},
+		{"repeated position", QuestionTypeOrdering, []AnswerDraft{{Position: position(1)}, {Position: position(1)}}, true, false},
+		{"missing position", QuestionTypeOrdering, []AnswerDraft{{Position: position(1)}, {}}, false, false},
+		{"marked correct", QuestionTypeOrdering, []AnswerDraft{{Position: position(1), Correct: true}}, false, false},
+		{"matched", QuestionTypeMatching, []AnswerDraft{{Match: match("a")}, {Match: match("b")}}, true, true},
+		{"shared match", QuestionTypeMatching, []AnswerDraft{{Match: match("a")}, {Match: match(" A ")}}, true, false},
+		{"missing match", QuestionTypeMatching, []AnswerDraft{{Match: match("a")}, {}}, false, false},
+		{"match on multiple_choice", QuestionTypeMultipleChoice, []AnswerDraft{{Correct: true, Match: match("a")}}, false, false},
+	}
+
+	for _, tt := range tests {
+		questionType, _ := LookupQuestionType(tt.questionType)
+		message := questionType.CheckAnswers(tt.answers, tt.complete)
+		if (message == "") != tt.valid {
+			t.Errorf("%s: got %q, want valid=%v", tt.name, message, tt.valid)
+		}
+	}
+}
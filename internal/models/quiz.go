@@ -1,7 +1,7 @@
 package models
 
 type Quiz struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID          int     `json:"id" db:"id"`
+	Title       string  `json:"title" db:"title"`
+	Description *string `json:"description" db:"description"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// ReviewCard is when a learner should next review a question, kept with the
+// SM-2 algorithm from every submitted attempt they were given it in.
+// Repetitions counts the reviews passed in a row since the last lapse, and
+// IntervalDays is the gap before the next one. Ease is how quickly the
+// interval grows, lowered by poor reviews. A learner's first review of a
+// question creates its card.
+type ReviewCard struct {
+	UserID         string    `json:"user_id" db:"user_id"`
+	QuestionID     int       `json:"question_id" db:"question_id"`
+	Repetitions    int       `json:"repetitions" db:"repetitions"`
+	IntervalDays   int       `json:"interval_days" db:"interval_days"`
+	Ease           float64   `json:"ease" db:"ease"`
+	Lapses         int       `json:"lapses" db:"lapses"`
+	Reviews        int       `json:"reviews" db:"reviews"`
+	LastQuality    int       `json:"last_quality" db:"last_quality"`
+	LastReviewedAt time.Time `json:"last_reviewed_at" db:"last_reviewed_at"`
+	DueAt          time.Time `json:"due_at" db:"due_at"`
+}
+
+// ReviewQuery limits how many due reviews are returned.
+type ReviewQuery struct {
+	Limit int `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// DueReview is a question due for review with its card. The question is in
+// its player form, with QuizID the quiz it belongs to, or null for a bank
+// question.
+type DueReview struct {
+	ReviewCard
+	QuizID   *int           `json:"quiz_id"`
+	Question PlayerQuestion `json:"question"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Kinds of search hit.
+const (
+	SearchHitQuiz     = "quiz"
+	SearchHitQuestion = "question"
+	SearchHitAnswer   = "answer"
+)
+
+// SearchQuery is a full-text search. Q uses web search syntax: quoted
+// phrases, "or" and a leading "-" to exclude a word.
+type SearchQuery struct {
+	Q     string `json:"q" form:"q" binding:"required"`
+	Limit int    `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// SearchHit is one matching quiz, question or answer, with the quiz or bank
+// that owns it: QuizID and QuizTitle are set for quizzes and what is in
+// them, BankID for bank questions and their answers. QuestionID is set for
+// questions and answers. Snippet is HTML: the text is escaped and matched
+// words are wrapped in <mark> tags.
+type SearchHit struct {
+	Kind       string  `json:"kind" db:"kind"`
+	ID         int     `json:"id" db:"id"`
+	QuizID     *int    `json:"quiz_id" db:"quiz_id"`
+	QuizTitle  *string `json:"quiz_title" db:"quiz_title"`
+	BankID     *int    `json:"bank_id,omitempty" db:"bank_id"`
+	QuestionID *int    `json:"question_id,omitempty" db:"question_id"`
+	Snippet    string  `json:"snippet" db:"snippet"`
+	Rank       float64 `json:"rank" db:"rank"`
+}
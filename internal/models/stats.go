@@ -0,0 +1,57 @@
+package models
+
+// Difficulty levels, from the share of attempts that answered a question
+// correctly.
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+
+	// MediumFrom and EasyFrom are the least p-values that reach each level.
+	MediumFrom = 0.3
+	EasyFrom   = 0.8
+)
+
+// ItemStats is the classical item analysis of a question, over every
+// submitted attempt it was in, or only the attempts at one quiz. Statistics
+// that need more data than there is are null.
+//
+// PValue is the share of attempts that answered the question correctly,
+// with unanswered counting as wrong. PointBiserial correlates answering it
+// correctly with the share of the rest of the attempt's points earned: near
+// zero or negative means it does not tell stronger players from weaker ones.
+// AverageTimeMs only covers responses whose time spent was reported.
+type ItemStats struct {
+	QuestionID    int           `json:"question_id"`
+	Question      string        `json:"question"`
+	Type          string        `json:"type"`
+	Attempts      int           `json:"attempts"`
+	Responses     int           `json:"responses"`
+	Correct       int           `json:"correct"`
+	PValue        *float64      `json:"p_value"`
+	Difficulty    *string       `json:"difficulty"`
+	PointBiserial *float64      `json:"point_biserial"`
+	AverageTimeMs *float64      `json:"average_time_ms"`
+	Answers       []AnswerStats `json:"answers"`
+}
+
+// AnswerStats is how often an answer was selected. Rate is the share of the
+// question's attempts that selected it, so a distractor nobody picks has a
+// rate of zero.
+type AnswerStats struct {
+	AnswerID   int      `json:"answer_id"`
+	Answer     string   `json:"answer"`
+	Correct    bool     `json:"is_correct"`
+	Selections int      `json:"selections"`
+	Rate       *float64 `json:"rate"`
+}
+
+// QuizStats is the item analysis of every question a quiz's submitted
+// attempts were given, its own first in order, then those drawn from banks.
+// AverageScore is the mean share of the maximum score attempts earned.
+type QuizStats struct {
+	QuizID       int         `json:"quiz_id"`
+	Attempts     int         `json:"attempts"`
+	AverageScore *float64    `json:"average_score"`
+	Questions    []ItemStats `json:"questions"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Tag labels quizzes and questions. Through ParentID tags form a hierarchy
+// of categories, and filtering by a tag also matches anything tagged with a
+// tag beneath it.
+type Tag struct {
+	ID       int    `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	ParentID *int   `json:"parent_id" db:"parent_id"`
+}
+
+// TagUsage is a tag with the number of quizzes and questions tagged with it
+// directly.
+type TagUsage struct {
+	Tag
+	Quizzes   int `json:"quizzes" db:"quizzes"`
+	Questions int `json:"questions" db:"questions"`
+}
+
+// TagInput is the body accepted when creating a tag or replacing one with
+// PUT. A missing parent makes it a top-level tag.
+type TagInput struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	ParentID *int   `json:"parent_id" binding:"omitempty,min=1"`
+}
+
+// TagRequest tags a quiz or question by name, creating the tag if there is
+// none by that name yet.
+type TagRequest struct {
+	Tag string `json:"tag" binding:"required,max=100"`
+}
@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/draw"
+	"github.com/changangus/go-quiz-backend/internal/irt"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const calibrationColumns = "question_id, difficulty, discrimination, responses, calibrated_at"
+
+// CheckAdaptiveSettings checks the settings an author gave an adaptive quiz.
+// Binding checks most of this for API requests; this covers the other
+// callers.
+func CheckAdaptiveSettings(settings models.AdaptiveSettings) error {
+	if settings.Model != models.AdaptiveModel1PL && settings.Model != models.AdaptiveModel2PL {
+		return Invalid("model", "must be 1pl or 2pl")
+	}
+	if settings.MaxQuestions < 1 {
+		return Invalid("max_questions", "must be at least 1")
+	}
+	if settings.MinQuestions < 0 || settings.MinQuestions > settings.MaxQuestions {
+		return Invalid("min_questions", "must be between 0 and max_questions")
+	}
+	if settings.TargetSE <= 0 {
+		return Invalid("target_standard_error", "must be more than 0")
+	}
+	return nil
+}
+
+// PlanAdaptive works out the next step of an adaptive attempt. administered
+// are the questions it has been given, in order, and correct says for each
+// one answered whether it was answered correctly. The next question comes
+// from pool, the quiz's draw.Pool, with the parameters in calibrations. While
+// the last question given is unanswered it stays the next one, so asking
+// again does not skip it.
+func PlanAdaptive(settings models.AdaptiveSettings, administered []int, correct map[int]bool, pool []int, calibrations map[int]models.Calibration) irt.Step {
+	calibrations = modelCalibrations(settings.Model, calibrations)
+	if n := len(administered); n > 0 {
+		if _, answered := correct[administered[n-1]]; !answered {
+			ability, standardError := irt.Estimate(adaptiveResponses(administered[:n-1], correct, calibrations))
+			item := calibratedItem(administered[n-1], calibrations)
+			return irt.Step{Ability: ability, StandardError: standardError, Next: &item}
+		}
+	}
+
+	given := make(map[int]bool, len(administered))
+	for _, id := range administered {
+		given[id] = true
+	}
+	var remaining []irt.Item
+	for _, id := range pool {
+		if !given[id] {
+			remaining = append(remaining, calibratedItem(id, calibrations))
+		}
+	}
+
+	rule := irt.StopRule{
+		MinItems: settings.MinQuestions,
+		MaxItems: settings.MaxQuestions,
+		TargetSE: settings.TargetSE,
+	}
+	return irt.Next(rule, adaptiveResponses(administered, correct, calibrations), remaining)
+}
+
+// AdaptiveAbility estimates the ability shown by an adaptive attempt under
+// model, with administered and correct as for PlanAdaptive. Questions given
+// but never answered count as wrong.
+func AdaptiveAbility(model string, administered []int, correct map[int]bool, calibrations map[int]models.Calibration) (float64, float64) {
+	return irt.Estimate(adaptiveResponses(administered, correct, modelCalibrations(model, calibrations)))
+}
+
+func adaptiveResponses(administered []int, correct map[int]bool, calibrations map[int]models.Calibration) []irt.Response {
+	responses := make([]irt.Response, 0, len(administered))
+	for _, id := range administered {
+		responses = append(responses, irt.Response{Item: calibratedItem(id, calibrations), Correct: correct[id]})
+	}
+	return responses
+}
+
+func calibratedItem(questionID int, calibrations map[int]models.Calibration) irt.Item {
+	calibration, ok := calibrations[questionID]
+	if !ok {
+		return irt.Uncalibrated(questionID)
+	}
+	return irt.Item{ID: questionID, Difficulty: calibration.Difficulty, Discrimination: calibration.Discrimination}
+}
+
+// CalibrationsOf lists the calibration of every question in pool, in its
+// order, as model uses it, with the defaults for those never calibrated.
+// model is empty for a quiz that is not adaptive.
+func CalibrationsOf(model string, pool []int, calibrations map[int]models.Calibration) []models.Calibration {
+	calibrations = modelCalibrations(model, calibrations)
+	list := make([]models.Calibration, 0, len(pool))
+	for _, id := range pool {
+		calibration, ok := calibrations[id]
+		if !ok {
+			item := irt.Uncalibrated(id)
+			calibration = models.Calibration{QuestionID: id, Difficulty: item.Difficulty, Discrimination: item.Discrimination}
+		}
+		list = append(list, calibration)
+	}
+	return list
+}
+
+// modelCalibrations returns calibrations as model uses them. Calibrations
+// are shared by every quiz a question is in, so one fitted by a 2PL quiz
+// can carry a discrimination the 1PL model has no place for; 1PL treats
+// every question as discriminating equally.
+func modelCalibrations(model string, calibrations map[int]models.Calibration) map[int]models.Calibration {
+	if model != models.AdaptiveModel1PL {
+		return calibrations
+	}
+
+	fixed := make(map[int]models.Calibration, len(calibrations))
+	for id, calibration := range calibrations {
+		calibration.Discrimination = 1
+		fixed[id] = calibration
+	}
+	return fixed
+}
+
+// AdaptiveRepository manages which quizzes are adaptive and the calibration
+// of the questions they ask. AttemptRepository delivers adaptive attempts.
+type AdaptiveRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewAdaptiveRepository(db *sqlx.DB, opts ...Option) *AdaptiveRepository {
+	return &AdaptiveRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+func (r *AdaptiveRepository) GetSettings(ctx context.Context, quizID string) (*models.AdaptiveSettings, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := requireQuiz(ctx, r.db, quizID); err != nil {
+		return nil, err
+	}
+
+	return loadAdaptiveSettings(ctx, r.db, quizID)
+}
+
+// ReplaceSettings makes a quiz adaptive, or changes how. Attempts already
+// started are delivered as they began.
+func (r *AdaptiveRepository) ReplaceSettings(ctx context.Context, quizID string, settings models.AdaptiveSettings) (*models.AdaptiveSettings, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := CheckAdaptiveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO quiz_adaptive_settings (quiz_id, model, min_questions, max_questions, target_se)
+		SELECT id, $2, $3, $4, $5 FROM quizzes WHERE id = $1
+		ON CONFLICT (quiz_id) DO UPDATE SET model = EXCLUDED.model, min_questions = EXCLUDED.min_questions,
+			max_questions = EXCLUDED.max_questions, target_se = EXCLUDED.target_se`,
+		quizID, settings.Model, settings.MinQuestions, settings.MaxQuestions, settings.TargetSE,
+	)
+	if err := requireRow(result, err, "quiz"); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// DeleteSettings makes a quiz deliver every question up front again.
+func (r *AdaptiveRepository) DeleteSettings(ctx context.Context, quizID string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM quiz_adaptive_settings WHERE quiz_id = $1", quizID)
+	return requireRow(result, err, "adaptive settings")
+}
+
+// GetCalibrations returns the calibration of every question an attempt at
+// the quiz could be asked, in ID order, as the quiz's model uses it.
+func (r *AdaptiveRepository) GetCalibrations(ctx context.Context, quizID string) ([]models.Calibration, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := requireQuiz(ctx, r.db, quizID); err != nil {
+		return nil, err
+	}
+
+	pool, err := adaptivePool(ctx, r.db, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	calibrations, err := loadCalibrations(ctx, r.db, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := adaptiveModel(ctx, r.db, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return CalibrationsOf(model, pool, calibrations), nil
+}
+
+// Calibrate refits every question an attempt at an adaptive quiz could be
+// asked, with the quiz's model, from the responses of every submitted
+// attempt it was given in, whatever the quiz. Each attempt counts as a
+// separate player. Questions no attempt has been given keep their
+// calibration.
+func (r *AdaptiveRepository) Calibrate(ctx context.Context, quizID string) ([]models.Calibration, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireQuiz(ctx, tx, quizID); err != nil {
+		return nil, err
+	}
+	settings, err := loadAdaptiveSettings(ctx, tx, quizID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, Conflict("quiz is not adaptive")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := adaptivePool(ctx, tx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT aq.attempt_id, aq.question_id, COALESCE(r.is_correct, false)
+		FROM attempt_questions aq
+		JOIN attempts a ON a.id = aq.attempt_id
+		LEFT JOIN attempt_responses r ON r.attempt_id = aq.attempt_id AND r.question_id = aq.question_id
+		WHERE a.status = $1 AND aq.question_id = ANY($2)`,
+		models.AttemptSubmitted, pq.Array(pool),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var observations []irt.Observation
+	for rows.Next() {
+		var o irt.Observation
+		if err := rows.Scan(&o.Person, &o.Item, &o.Correct); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		observations = append(observations, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, c := range irt.Calibrate(observations, settings.Model == models.AdaptiveModel2PL) {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO question_calibrations (question_id, difficulty, discrimination, responses)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (question_id) DO UPDATE SET difficulty = EXCLUDED.difficulty,
+				discrimination = EXCLUDED.discrimination, responses = EXCLUDED.responses, calibrated_at = NOW()`,
+			c.ID, c.Difficulty, c.Discrimination, c.Responses,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	calibrations, err := loadCalibrations(ctx, tx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return CalibrationsOf(settings.Model, pool, calibrations), nil
+}
+
+func requireQuiz(ctx context.Context, q sqlx.QueryerContext, quizID string) error {
+	var exists bool
+	err := q.QueryRowxContext(ctx, "SELECT EXISTS (SELECT 1 FROM quizzes WHERE id = $1)", quizID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return NotFound("quiz")
+	}
+	return nil
+}
+
+// loadAdaptiveSettings returns how a quiz is adaptive. A quiz that is not is
+// reported as not found.
+func loadAdaptiveSettings(ctx context.Context, q sqlx.QueryerContext, quizID string) (*models.AdaptiveSettings, error) {
+	settings := &models.AdaptiveSettings{}
+	err := sqlx.GetContext(ctx, q, settings,
+		"SELECT model, min_questions, max_questions, target_se FROM quiz_adaptive_settings WHERE quiz_id = $1",
+		quizID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, NotFound("adaptive settings")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// adaptiveModel returns the model of an adaptive quiz, or "" for a quiz that
+// is not adaptive.
+func adaptiveModel(ctx context.Context, q sqlx.QueryerContext, quizID string) (string, error) {
+	settings, err := loadAdaptiveSettings(ctx, q, quizID)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return settings.Model, nil
+}
+
+// adaptivePool returns every question an attempt at a quiz could be asked.
+// See draw.Pool.
+func adaptivePool(ctx context.Context, q sqlx.QueryerContext, quizID string) ([]int, error) {
+	fixed, _, pools, err := drawSources(ctx, q, quizID)
+	if err != nil {
+		return nil, err
+	}
+	return draw.Pool(fixed, pools), nil
+}
+
+// loadCalibrations returns the calibrations of those of questionIDs that
+// have one, keyed by question ID.
+func loadCalibrations(ctx context.Context, q sqlx.QueryerContext, questionIDs []int) (map[int]models.Calibration, error) {
+	var list []models.Calibration
+	err := sqlx.SelectContext(ctx, q, &list,
+		"SELECT "+calibrationColumns+" FROM question_calibrations WHERE question_id = ANY($1)",
+		pq.Array(questionIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	calibrations := make(map[int]models.Calibration, len(list))
+	for _, c := range list {
+		calibrations[c.QuestionID] = c
+	}
+	return calibrations, nil
+}
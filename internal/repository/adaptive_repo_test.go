@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestPlanAdaptive(t *testing.T) {
+	settings := models.AdaptiveSettings{Model: models.AdaptiveModel2PL, MaxQuestions: 3, TargetSE: 0.1}
+	calibrations := map[int]models.Calibration{
+		1: {QuestionID: 1, Difficulty: -1, Discrimination: 1},
+		2: {QuestionID: 2, Difficulty: 0.2, Discrimination: 1},
+		3: {QuestionID: 3, Difficulty: 1.5, Discrimination: 1},
+	}
+	pool := []int{1, 2, 3, 4}
+
+	// Uncalibrated question 4 is of average difficulty, as is the prior
+	if step := PlanAdaptive(settings, nil, nil, pool, calibrations); step.Next == nil || step.Next.ID != 4 {
+		t.Fatalf("first step = %+v, want question 4", step)
+	}
+
+	// The last question given is asked again until it is answered
+	step := PlanAdaptive(settings, []int{4}, map[int]bool{}, pool, calibrations)
+	if step.Next == nil || step.Next.ID != 4 || step.StandardError < 0.99 {
+		t.Errorf("step = %+v, want question 4 again with the prior's error", step)
+	}
+
+	// Right on the average question and wrong on the hard one lands just
+	// above average
+	step = PlanAdaptive(settings, []int{4, 3}, map[int]bool{4: true, 3: false}, pool, calibrations)
+	if step.Next == nil || step.Next.ID != 2 || step.Ability <= 0 {
+		t.Errorf("step = %+v, want question 2", step)
+	}
+
+	if step := PlanAdaptive(settings, []int{4, 3, 2}, map[int]bool{4: true, 3: false, 2: true}, pool, calibrations); step.Next != nil {
+		t.Errorf("step = %+v, want to stop after max_questions", step)
+	}
+}
+
+// Calibrations are shared between quizzes, so a 1PL quiz must ignore a
+// discrimination fitted for a 2PL one.
+func TestAdaptive1PLIgnoresDiscrimination(t *testing.T) {
+	fitted := map[int]models.Calibration{2: {QuestionID: 2, Difficulty: 0.5, Discrimination: 3}}
+	equal := map[int]models.Calibration{2: {QuestionID: 2, Difficulty: 0.5, Discrimination: 1}}
+	administered, correct := []int{2}, map[int]bool{2: true}
+
+	ability, se := AdaptiveAbility(models.AdaptiveModel1PL, administered, correct, fitted)
+	wantAbility, wantSE := AdaptiveAbility(models.AdaptiveModel2PL, administered, correct, equal)
+	if ability != wantAbility || se != wantSE {
+		t.Errorf("1pl ability = %v ± %v, want %v ± %v as if discrimination were 1", ability, se, wantAbility, wantSE)
+	}
+	if twoPL, _ := AdaptiveAbility(models.AdaptiveModel2PL, administered, correct, fitted); twoPL == ability {
+		t.Error("2pl ability ignores discrimination too")
+	}
+
+	settings := models.AdaptiveSettings{Model: models.AdaptiveModel1PL, MaxQuestions: 3, TargetSE: 0.1}
+	if step := PlanAdaptive(settings, nil, nil, []int{2}, fitted); step.Next == nil || step.Next.Discrimination != 1 {
+		t.Errorf("1pl step = %+v, want question 2 with discrimination 1", step)
+	}
+
+	if list := CalibrationsOf(models.AdaptiveModel1PL, []int{2}, fitted); list[0].Discrimination != 1 {
+		t.Errorf("1pl calibrations = %+v, want discrimination 1", list)
+	}
+	if list := CalibrationsOf(models.AdaptiveModel2PL, []int{2}, fitted); list[0].Discrimination != 3 {
+		t.Errorf("2pl calibrations = %+v, want the fitted discrimination", list)
+	}
+	if fitted[2].Discrimination != 3 {
+		t.Error("1pl changed the calibrations it was given")
+	}
+}
+
+// Only a quiz with no adaptive settings is a conflict; failing to read them
+// is reported as it is.
+func TestAdaptiveRepositoryCalibrateSettingsError(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM quizzes WHERE id = $1)")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM quiz_adaptive_settings WHERE quiz_id = $1")).
+		WithArgs("1").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := NewAdaptiveRepository(db).Calibrate(context.Background(), "1")
+	if err == nil || errors.Is(err, ErrConflict) || err.Error() != "connection reset" {
+		t.Fatalf("expected the database error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Making a missing quiz adaptive inserts nothing and reports the quiz.
+func TestAdaptiveRepositoryReplaceSettingsMissingQuiz(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO quiz_adaptive_settings")).
+		WithArgs("9", "1pl", 0, 10, 0.3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	settings := models.AdaptiveSettings{Model: models.AdaptiveModel1PL, MaxQuestions: 10, TargetSE: 0.3}
+	_, err := NewAdaptiveRepository(db).ReplaceSettings(context.Background(), "9", settings)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,34 +1,42 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"time"
 
 	"github.com/changangus/go-quiz-backend/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
 type AnswerRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	timeout time.Duration
 }
 
-func NewAnswerRepository(db *sqlx.DB) *AnswerRepository {
-	return &AnswerRepository{db: db}
+func NewAnswerRepository(db *sqlx.DB, opts ...Option) *AnswerRepository {
+	return &AnswerRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
-func (r *AnswerRepository) GetByID(id string) (*models.Answer, error) {
+func (r *AnswerRepository) GetByID(ctx context.Context, id string) (*models.Answer, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	answer := &models.Answer{}
-	err := r.db.Get(answer, "SELECT id, question_id, answer, is_correct FROM answers WHERE id = $1", id)
+	err := r.db.GetContext(ctx, answer, "SELECT id, question_id, answer, is_correct, position, match_text, rationale FROM answers WHERE id = $1", id)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "answer")
 	}
 
 	return answer, nil
 }
 
-func (r *AnswerRepository) GetByQuestionID(questionID string) ([]models.Answer, error) {
+func (r *AnswerRepository) GetByQuestionID(ctx context.Context, questionID string) ([]models.Answer, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	var answers []models.Answer
-	err := r.db.Select(&answers, 
-		"SELECT id, question_id, answer, is_correct FROM answers WHERE question_id = $1",
+	err := r.db.SelectContext(ctx, &answers,
+		"SELECT id, question_id, answer, is_correct, position, match_text, rationale FROM answers WHERE question_id = $1",
 		questionID)
 	if err != nil {
 		return nil, err
@@ -37,77 +45,142 @@ func (r *AnswerRepository) GetByQuestionID(questionID string) ([]models.Answer,
 	return answers, nil
 }
 
-func (r *AnswerRepository) Create(data map[string]interface{}) (int64, error) {
-	questionID, ok := data["question_id"].(float64)
-	if !ok {
-		questionIDStr, ok := data["question_id"].(string)
-		if !ok {
-			return 0, errors.New("question_id is required")
-		}
-		// Convert string to int if needed
-		// This would require proper conversion logic
-		_ = questionIDStr // Placeholder to avoid unused variable warning
+func (r *AnswerRepository) Create(ctx context.Context, input models.AnswerInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.QuestionID == 0 {
+		return 0, Invalid("question_id", "is required")
+	}
+	if input.Answer == "" {
+		return 0, Invalid("answer", "is required")
 	}
 
-	answerText, ok := data["answer"].(string)
-	if !ok || answerText == "" {
-		return 0, errors.New("answer text is required")
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	isCorrect, _ := data["is_correct"].(bool)
+	questionID, err := lockQuestion(ctx, tx, lockQuestionByID, input.QuestionID, InvalidReference("answer"))
+	if err != nil {
+		return 0, err
+	}
 
 	var answerID int64
-	err := r.db.QueryRow(
-		"INSERT INTO answers (question_id, answer, is_correct) VALUES ($1, $2, $3) RETURNING id",
-		int(questionID), answerText, isCorrect,
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO answers (question_id, answer, is_correct, position, match_text, rationale) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		questionID, input.Answer, input.Correct, input.Position, input.Match, input.Rationale,
 	).Scan(&answerID)
 	if err != nil {
+		return 0, dbError(err, "answer")
+	}
+
+	if err := checkStoredQuestion(ctx, tx, questionID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return 0, err
 	}
 
 	return answerID, nil
 }
 
-func (r *AnswerRepository) Update(id string, data map[string]interface{}) error {
-	// Build query dynamically based on which fields are provided
-	query := "UPDATE answers SET "
-	params := []interface{}{}
-	paramCount := 1
-	first := true
+// Replace overwrites every editable column of an answer, as PUT requires.
+// The answer stays with its question, which must still suit its type.
+func (r *AnswerRepository) Replace(ctx context.Context, id string, input models.AnswerInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.Answer == "" {
+		return Invalid("answer", "is required")
+	}
+
+	query, params, err := newUpdateBuilder("answers").
+		Set("answer", input.Answer).
+		Set("is_correct", input.Correct).
+		Set("position", input.Position).
+		Set("match_text", input.Match).
+		Set("rationale", input.Rationale).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	return r.updateChecked(ctx, id, query, params)
+}
+
+// Patch applies a JSON Merge Patch to an answer, changing only the fields
+// that are present. Changing is_correct, position or match re-checks the
+// question against the rules of its type.
+func (r *AnswerRepository) Patch(ctx context.Context, id string, patch models.AnswerPatch) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	// Check for each possible field
-	if answerText, ok := data["answer"].(string); ok {
-		if !first {
-			query += ", "
-		}
-		query += "answer = $" + string(paramCount+'0')
-		params = append(params, answerText)
-		paramCount++
-		first = false
+	if patch.Answer.IsValue() && patch.Answer.Value == "" {
+		return Invalid("answer", "must not be empty")
 	}
 
-	if isCorrect, ok := data["is_correct"].(bool); ok {
-		if !first {
-			query += ", "
-		}
-		query += "is_correct = $" + string(paramCount+'0')
-		params = append(params, isCorrect)
-		paramCount++
-		first = false
+	builder := newUpdateBuilder("answers")
+	if err := setOptional(builder, "answer", patch.Answer, false); err != nil {
+		return err
+	}
+	if err := setOptional(builder, "is_correct", patch.Correct, false); err != nil {
+		return err
+	}
+	if err := setOptional(builder, "position", patch.Position, true); err != nil {
+		return err
+	}
+	if err := setOptional(builder, "match_text", patch.Match, true); err != nil {
+		return err
+	}
+	if err := setOptional(builder, "rationale", patch.Rationale, true); err != nil {
+		return err
 	}
 
-	if first {
-		return errors.New("no valid fields to update")
+	query, params, err := builder.Build(id)
+	if err != nil {
+		return err
 	}
 
-	query += " WHERE id = $" + string(paramCount+'0')
-	params = append(params, id)
+	if !patch.Correct.Set && !patch.Position.Set && !patch.Match.Set {
+		result, err := r.db.ExecContext(ctx, query, params...)
+		return requireRow(result, err, "answer")
+	}
 
-	_, err := r.db.Exec(query, params...)
-	return err
+	return r.updateChecked(ctx, id, query, params)
 }
 
-func (r *AnswerRepository) Delete(id string) error {
-	_, err := r.db.Exec("DELETE FROM answers WHERE id = $1", id)
-	return err
+// updateChecked runs an UPDATE of answer id and checks its question against
+// the rules of the question's type before committing.
+func (r *AnswerRepository) updateChecked(ctx context.Context, id string, query string, params []interface{}) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	questionID, err := lockQuestion(ctx, tx, lockQuestionByAnswer, id, NotFound("answer"))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, params...); err != nil {
+		return dbError(err, "answer")
+	}
+
+	if err := checkStoredQuestion(ctx, tx, questionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AnswerRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM answers WHERE id = $1", id)
+	return requireRow(result, err, "answer")
 }
@@ -0,0 +1,783 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/review"
+	"github.com/changangus/go-quiz-backend/internal/scoring"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type AttemptRepository struct {
+	db        *sqlx.DB
+	timeout   time.Duration
+	scheduler review.Scheduler
+}
+
+func NewAttemptRepository(db *sqlx.DB, opts ...Option) *AttemptRepository {
+	o := newOptions(opts)
+	return &AttemptRepository{db: db, timeout: o.queryTimeout, scheduler: review.NewScheduler(o.clock)}
+}
+
+const attemptColumns = "id, quiz_id, user_id, status, seed, adaptive, score, max_score, ability, ability_se, started_at, submitted_at"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAttempt(row rowScanner) (*models.Attempt, error) {
+	attempt := &models.Attempt{}
+	var score, maxScore, ability, standardError sql.NullFloat64
+	var submittedAt sql.NullTime
+	err := row.Scan(&attempt.ID, &attempt.QuizID, &attempt.UserID, &attempt.Status, &attempt.Seed, &attempt.Adaptive,
+		&score, &maxScore, &ability, &standardError, &attempt.StartedAt, &submittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if score.Valid {
+		attempt.Score = &score.Float64
+	}
+	if maxScore.Valid {
+		attempt.MaxScore = &maxScore.Float64
+	}
+	if ability.Valid {
+		attempt.Ability = &ability.Float64
+	}
+	if standardError.Valid {
+		attempt.StandardError = &standardError.Float64
+	}
+	if submittedAt.Valid {
+		attempt.SubmittedAt = &submittedAt.Time
+	}
+
+	return attempt, nil
+}
+
+// Start opens an attempt at a quiz and gives it its questions: the quiz's
+// own, then those its draw rules pick with the attempt's seed. An attempt at
+// an adaptive quiz starts with none and is given them one at a time by Next.
+func (r *AttemptRepository) Start(ctx context.Context, quizID string, start models.AttemptStart) (*models.Attempt, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	seed := rand.Int63()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireQuiz(ctx, tx, quizID); err != nil {
+		return nil, err
+	}
+
+	var adaptive bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM quiz_adaptive_settings WHERE quiz_id = $1)", quizID,
+	).Scan(&adaptive)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := []int{}
+	if !adaptive {
+		questions, err = drawQuestions(ctx, tx, quizID, seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	row := tx.QueryRowContext(ctx,
+		"INSERT INTO attempts (quiz_id, user_id, seed, adaptive) VALUES ($1, $2, $3, $4) RETURNING "+attemptColumns,
+		quizID, start.UserID, seed, adaptive,
+	)
+	attempt, err := scanAttempt(row)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, questionID := range questions {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO attempt_questions (attempt_id, question_id, position) VALUES ($1, $2, $3)",
+			attempt.ID, questionID, i+1,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	attempt.QuestionIDs = questions
+	return attempt, nil
+}
+
+// GetByID returns an attempt with its responses. Once the attempt is
+// submitted each response carries feedback on its question.
+func (r *AttemptRepository) GetByID(ctx context.Context, quizID string, attemptID string) (*models.Attempt, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+attemptColumns+" FROM attempts WHERE id = $1 AND quiz_id = $2",
+		attemptID, quizID,
+	)
+	attempt, err := scanAttempt(row)
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+
+	err = r.db.SelectContext(ctx, &attempt.QuestionIDs,
+		"SELECT question_id FROM attempt_questions WHERE attempt_id = $1 ORDER BY position",
+		attempt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := r.getResponses(ctx, r.db, attempt.ID)
+	if err != nil {
+		return nil, err
+	}
+	attempt.Responses = responses
+
+	if attempt.Status == models.AttemptSubmitted {
+		feedback, err := loadFeedback(ctx, r.db, attempt.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attempt.Responses {
+			attempt.Responses[i].Feedback = feedback[attempt.Responses[i].QuestionID]
+		}
+	}
+
+	return attempt, nil
+}
+
+// GetQuestions returns the questions of an attempt, in the order it was
+// given them.
+func (r *AttemptRepository) GetQuestions(ctx context.Context, quizID string, attemptID string) ([]models.FullQuestion, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, readSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx,
+		"SELECT id FROM attempts WHERE id = $1 AND quiz_id = $2",
+		attemptID, quizID,
+	).Scan(&id)
+	if err != nil {
+		return nil, dbError(err, "attempt")
+	}
+
+	questions, err := loadFullQuestions(ctx, tx, attemptQuestions, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+func (r *AttemptRepository) GetByQuizID(ctx context.Context, quizID string, userID string) ([]models.Attempt, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := "SELECT " + attemptColumns + " FROM attempts WHERE quiz_id = $1"
+	params := []interface{}{quizID}
+	if userID != "" {
+		query += " AND user_id = $2"
+		params = append(params, userID)
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []models.Attempt{}
+	for rows.Next() {
+		attempt, err := scanAttempt(rows)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, *attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// RecordResponse stores the answers selected, or the text typed, for a
+// question, replacing any earlier response to the same question. Responses
+// can only be changed while the attempt is in progress.
+func (r *AttemptRepository) RecordResponse(ctx context.Context, quizID string, attemptID string, response models.AttemptResponse) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	id, err := lockOpenAttempt(ctx, tx, quizID, attemptID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordResponse(ctx, tx, id, response); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Submit grades every recorded response against the answers table, using
+// each question's scoring strategy, takes off the penalties for hints the
+// player revealed, and closes the attempt. Questions of the attempt without a
+// response count towards the maximum score. The attempt is added to the item
+// analysis totals and reschedules the player's reviews of its questions in
+// the same transaction. An adaptive attempt also gets its final ability
+// estimate.
+func (r *AttemptRepository) Submit(ctx context.Context, quizID string, attemptID string, responses []models.AttemptResponse) (*models.Attempt, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := lockOpenAttempt(ctx, tx, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adaptive bool
+	var userID string
+	err = tx.QueryRowContext(ctx, "SELECT adaptive, user_id FROM attempts WHERE id = $1", id).Scan(&adaptive, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, response := range responses {
+		if err := recordResponse(ctx, tx, id, response); err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := loadAnswerKeys(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	penalties, err := loadHintPenalties(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := r.getResponses(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	score := 0.0
+	maxScore := float64(len(keys))
+	correct := make(map[int]bool, len(recorded))
+	outcomes := make(map[int]analytics.Outcome, len(keys))
+	for questionID := range keys {
+		outcomes[questionID] = analytics.Outcome{MaxScore: maxScore}
+	}
+	var selected []int64
+	for _, response := range recorded {
+		key := keys[response.QuestionID]
+		points := key.Score(response.AnswerIDs, response.Text)
+		isCorrect := points == 1
+		correct[response.QuestionID] = isCorrect
+		points = scoring.WithHints(points, penalties[response.QuestionID])
+		score += points
+
+		_, err := tx.ExecContext(ctx,
+			"UPDATE attempt_responses SET is_correct = $1, points = $2 WHERE id = $3",
+			isCorrect, points, response.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		outcomes[response.QuestionID] = analytics.Outcome{
+			Answered:    true,
+			Correct:     isCorrect,
+			Points:      points,
+			MaxScore:    maxScore,
+			TimeSpentMs: response.TimeSpentMs,
+		}
+		if analytics.CountsSelections(key.Type) {
+			selected = append(selected, response.AnswerIDs...)
+		}
+	}
+
+	var ability, standardError *float64
+	if adaptive {
+		administered := make([]int, 0, len(keys))
+		for questionID := range keys {
+			administered = append(administered, questionID)
+		}
+		calibrations, err := loadCalibrations(ctx, tx, administered)
+		if err != nil {
+			return nil, err
+		}
+		// A quiz no longer adaptive has no model, so stored calibrations
+		// are used as they are
+		model, err := adaptiveModel(ctx, tx, quizID)
+		if err != nil {
+			return nil, err
+		}
+		theta, se := AdaptiveAbility(model, administered, correct, calibrations)
+		ability, standardError = &theta, &se
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE attempts SET status = $1, score = $2, max_score = $3, ability = $4, ability_se = $5, submitted_at = NOW()
+		WHERE id = $6`,
+		models.AttemptSubmitted, score, maxScore, ability, standardError, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for questionID, outcome := range outcomes {
+		outcome.Score = score
+		outcomes[questionID] = outcome
+	}
+	if err := recordStats(ctx, tx, quizID, analytics.ObserveQuiz(score, maxScore), outcomes, selected); err != nil {
+		return nil, err
+	}
+	if err := recordReviews(ctx, tx, r.scheduler, userID, outcomes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, quizID, attemptID)
+}
+
+// Next returns where an adaptive attempt stands and gives it its next
+// question, the one that tells the most about the player's ability so far,
+// unless it has been asked enough. Until that question is answered, Next
+// returns it again.
+func (r *AttemptRepository) Next(ctx context.Context, quizID string, attemptID string) (*models.AdaptiveStep, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := lockOpenAttempt(ctx, tx, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adaptive bool
+	if err := tx.QueryRowContext(ctx, "SELECT adaptive FROM attempts WHERE id = $1", id).Scan(&adaptive); err != nil {
+		return nil, err
+	}
+	if !adaptive {
+		return nil, Conflict("attempt is not adaptive")
+	}
+
+	settings, err := loadAdaptiveSettings(ctx, tx, quizID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, Conflict("quiz is no longer adaptive")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var administered []int
+	err = tx.SelectContext(ctx, &administered,
+		"SELECT question_id FROM attempt_questions WHERE attempt_id = $1 ORDER BY position", id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := loadAnswerKeys(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := r.getResponses(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	correct := make(map[int]bool, len(recorded))
+	for _, response := range recorded {
+		correct[response.QuestionID] = keys[response.QuestionID].Score(response.AnswerIDs, response.Text) == 1
+	}
+
+	pool, err := adaptivePool(ctx, tx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	calibrations, err := loadCalibrations(ctx, tx, append(pool, administered...))
+	if err != nil {
+		return nil, err
+	}
+
+	step := PlanAdaptive(*settings, administered, correct, pool, calibrations)
+	result := &models.AdaptiveStep{
+		Done:          step.Next == nil,
+		Answered:      len(recorded),
+		Ability:       step.Ability,
+		StandardError: step.StandardError,
+	}
+	if step.Next == nil {
+		return result, tx.Commit()
+	}
+
+	if n := len(administered); n == 0 || administered[n-1] != step.Next.ID {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO attempt_questions (attempt_id, question_id, position) VALUES ($1, $2, $3)",
+			id, step.Next.ID, n+1,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	questions, err := loadFullQuestions(ctx, tx, questionsByID, pq.Array([]int{step.Next.ID}))
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, NotFound("question")
+	}
+	question := models.NewPlayerQuestion(questions[0])
+	result.Question = &question
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RevealHint shows the player the next hidden tier of a question's hints and
+// records it against the attempt, so its penalty applies when the attempt is
+// graded. Hints can only be revealed while the attempt is in progress.
+func (r *AttemptRepository) RevealHint(ctx context.Context, quizID string, attemptID string, questionID int) (*models.RevealedHint, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := lockOpenAttempt(ctx, tx, quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM attempt_questions WHERE attempt_id = $1 AND question_id = $2)",
+		id, questionID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NotFound("question")
+	}
+
+	var hints []models.Hint
+	err = tx.SelectContext(ctx, &hints,
+		"SELECT id, question_id, tier, hint, penalty FROM question_hints WHERE question_id = $1 ORDER BY tier",
+		questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revealed int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM attempt_hints WHERE attempt_id = $1 AND question_id = $2",
+		id, questionID,
+	).Scan(&revealed)
+	if err != nil {
+		return nil, err
+	}
+	if revealed >= len(hints) {
+		return nil, Conflict("question has no more hints")
+	}
+
+	hint := hints[revealed]
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO attempt_hints (attempt_id, question_id, tier) VALUES ($1, $2, $3)",
+		id, questionID, hint.Tier,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.RevealedHint{
+		QuestionID: questionID,
+		Tier:       hint.Tier,
+		Hint:       hint.Hint,
+		Penalty:    hint.Penalty,
+		Remaining:  len(hints) - revealed - 1,
+	}, nil
+}
+
+func lockOpenAttempt(ctx context.Context, tx *sqlx.Tx, quizID string, attemptID string) (int, error) {
+	var id int
+	var status string
+	err := tx.QueryRowContext(ctx,
+		"SELECT id, status FROM attempts WHERE id = $1 AND quiz_id = $2 FOR UPDATE",
+		attemptID, quizID,
+	).Scan(&id, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, NotFound("attempt")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if status != models.AttemptInProgress {
+		return 0, Conflict("attempt has already been submitted")
+	}
+
+	return id, nil
+}
+
+func recordResponse(ctx context.Context, tx *sqlx.Tx, attemptID int, response models.AttemptResponse) error {
+	var question models.Question
+	err := tx.GetContext(ctx, &question,
+		`SELECT q.id, q.question, q.type, q.scoring, q.config
+		FROM questions q JOIN attempt_questions aq ON aq.question_id = q.id
+		WHERE q.id = $1 AND aq.attempt_id = $2`,
+		response.QuestionID, attemptID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Invalid("question_id", "is not one of this attempt's questions")
+	}
+	if err != nil {
+		return err
+	}
+	if err := CheckResponse(question, response); err != nil {
+		return err
+	}
+
+	answerIDs := scoring.UniqueIDs(response.AnswerIDs)
+	var matched int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM answers WHERE question_id = $1 AND id = ANY($2)",
+		response.QuestionID, pq.Array(answerIDs),
+	).Scan(&matched)
+	if err != nil {
+		return err
+	}
+	if matched != len(answerIDs) {
+		return Invalid("answer_ids", "must all belong to the question")
+	}
+
+	text := response.Text
+	if text == nil {
+		text = []string{}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO attempt_responses AS r (attempt_id, question_id, answer_ids, text_answers, time_spent_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (attempt_id, question_id)
+		DO UPDATE SET answer_ids = EXCLUDED.answer_ids, text_answers = EXCLUDED.text_answers, responded_at = NOW(),
+			time_spent_ms = COALESCE(r.time_spent_ms + EXCLUDED.time_spent_ms, r.time_spent_ms, EXCLUDED.time_spent_ms)`,
+		attemptID, response.QuestionID, pq.Array(answerIDs), pq.Array(text), response.TimeSpentMs,
+	)
+	return err
+}
+
+// loadAnswerKeys returns what is needed to grade every question of an
+// attempt, keyed by question ID. Questions without answers still get a key
+// so they count towards the maximum score.
+func loadAnswerKeys(ctx context.Context, tx *sqlx.Tx, attemptID int) (map[int]scoring.Key, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT q.id, q.type, q.scoring, q.config, a.id, COALESCE(a.is_correct, false), a.position, a.match_text
+		FROM attempt_questions aq
+		JOIN questions q ON q.id = aq.question_id
+		LEFT JOIN answers a ON a.question_id = q.id
+		WHERE aq.attempt_id = $1
+		ORDER BY q.id, a.id`,
+		attemptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[int]scoring.Key)
+	for rows.Next() {
+		var questionID int
+		var questionType, strategy string
+		var config *models.QuestionConfig
+		var answerID sql.NullInt64
+		var answer models.Answer
+		if err := rows.Scan(&questionID, &questionType, &strategy, &config, &answerID,
+			&answer.Correct, &answer.Position, &answer.Match); err != nil {
+			return nil, err
+		}
+
+		key := keys[questionID]
+		key.Type, key.Strategy, key.Config = questionType, strategy, config
+		if answerID.Valid {
+			answer.ID = int(answerID.Int64)
+			key.AddAnswer(answer)
+		}
+		keys[questionID] = key
+	}
+
+	return keys, rows.Err()
+}
+
+// loadHintPenalties returns the total penalty for the hints revealed during
+// an attempt, keyed by question ID.
+func loadHintPenalties(ctx context.Context, tx *sqlx.Tx, attemptID int) (map[int]float64, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT ah.question_id, SUM(h.penalty)
+		FROM attempt_hints ah
+		JOIN question_hints h ON h.question_id = ah.question_id AND h.tier = ah.tier
+		WHERE ah.attempt_id = $1
+		GROUP BY ah.question_id`,
+		attemptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	penalties := make(map[int]float64)
+	for rows.Next() {
+		var questionID int
+		var penalty float64
+		if err := rows.Scan(&questionID, &penalty); err != nil {
+			return nil, err
+		}
+		penalties[questionID] = penalty
+	}
+
+	return penalties, rows.Err()
+}
+
+// loadFeedback returns the feedback for every question of an attempt, keyed
+// by question ID.
+func loadFeedback(ctx context.Context, q sqlx.QueryerContext, attemptID int) (map[int]*models.Feedback, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT q.id, q.explanation, a.id, COALESCE(a.is_correct, false), a.rationale
+		FROM attempt_questions aq
+		JOIN questions q ON q.id = aq.question_id
+		LEFT JOIN answers a ON a.question_id = q.id
+		WHERE aq.attempt_id = $1
+		ORDER BY q.id, a.id`,
+		attemptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := make(map[int]*models.Feedback)
+	for rows.Next() {
+		var questionID int
+		var explanation *string
+		var answerID sql.NullInt64
+		var answer models.AnswerFeedback
+		if err := rows.Scan(&questionID, &explanation, &answerID, &answer.Correct, &answer.Rationale); err != nil {
+			return nil, err
+		}
+
+		question, ok := feedback[questionID]
+		if !ok {
+			question = &models.Feedback{Explanation: explanation, Answers: []models.AnswerFeedback{}}
+			feedback[questionID] = question
+		}
+		if answerID.Valid {
+			answer.ID = int(answerID.Int64)
+			question.Answers = append(question.Answers, answer)
+		}
+	}
+
+	return feedback, rows.Err()
+}
+
+func (r *AttemptRepository) getResponses(ctx context.Context, q sqlx.QueryerContext, attemptID int) ([]models.AttemptResponse, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT r.id, r.attempt_id, r.question_id, r.answer_ids, r.text_answers, r.is_correct, r.points, r.time_spent_ms,
+			(SELECT COUNT(*) FROM attempt_hints ah WHERE ah.attempt_id = r.attempt_id AND ah.question_id = r.question_id),
+			r.responded_at
+		FROM attempt_responses r WHERE r.attempt_id = $1 ORDER BY r.question_id`,
+		attemptID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := []models.AttemptResponse{}
+	for rows.Next() {
+		var response models.AttemptResponse
+		var isCorrect sql.NullBool
+		var points sql.NullFloat64
+		var timeSpent sql.NullInt64
+		err := rows.Scan(&response.ID, &response.AttemptID, &response.QuestionID,
+			pq.Array(&response.AnswerIDs), pq.Array(&response.Text), &isCorrect, &points, &timeSpent,
+			&response.HintsUsed, &response.RespondedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.AnswerIDs == nil {
+			response.AnswerIDs = []int64{}
+		}
+		if isCorrect.Valid {
+			response.IsCorrect = &isCorrect.Bool
+		}
+		if points.Valid {
+			response.Points = &points.Float64
+		}
+		if timeSpent.Valid {
+			response.TimeSpentMs = &timeSpent.Int64
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, rows.Err()
+}
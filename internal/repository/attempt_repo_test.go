@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// expectOpenAttempt registers the locking read of attempt 5 of quiz 1, which
+// reports the given status.
+func expectOpenAttempt(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, status FROM attempts WHERE id = $1 AND quiz_id = $2 FOR UPDATE")).
+		WithArgs("5", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(5, status))
+}
+
+// expectAttemptQuestion registers the lookup of question 7 through the
+// attempt's questions. found is false for a question the attempt was not
+// given.
+func expectAttemptQuestion(mock sqlmock.Sqlmock, found bool) {
+	rows := sqlmock.NewRows([]string{"id", "question", "type", "scoring", "config"})
+	if found {
+		rows.AddRow(7, "Capital of France?", models.QuestionTypeMultipleChoice, models.ScoringAllOrNothing, nil)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM questions q JOIN attempt_questions aq ON aq.question_id = q.id")).
+		WithArgs(7, 5).
+		WillReturnRows(rows)
+}
+
+// expectMatchedAnswers registers the check that answerIDs belong to question
+// 7, of which matched do.
+func expectMatchedAnswers(mock sqlmock.Sqlmock, answerIDs string, matched int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM answers WHERE question_id = $1 AND id = ANY($2)")).
+		WithArgs(7, answerIDs).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(matched))
+}
+
+func expectValidation(t *testing.T, err error, field string) {
+	t.Helper()
+	var domainErr *Error
+	if !errors.As(err, &domainErr) || !errors.Is(err, ErrValidation) || domainErr.Field != field {
+		t.Fatalf("expected a validation error on %s, got %v", field, err)
+	}
+}
+
+// Answer IDs are stored once each, however often the player sent them.
+func TestAttemptRepositoryRecordResponseDedupesAnswers(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectOpenAttempt(mock, models.AttemptInProgress)
+	expectAttemptQuestion(mock, true)
+	expectMatchedAnswers(mock, "{3}", 1)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO attempt_responses")).
+		WithArgs(5, 7, "{3}", "{}", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := NewAttemptRepository(db).RecordResponse(context.Background(), "1", "5", models.AttemptResponse{
+		QuestionID: 7,
+		AnswerIDs:  []int64{3, 3},
+	})
+	if err != nil {
+		t.Fatalf("RecordResponse returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Nothing is stored for a response the attempt cannot take. sqlmock fails the
+// call on the INSERT, which is not registered.
+func TestAttemptRepositoryRecordResponseRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		field  string
+	}{
+		{
+			name: "answer from another question",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAttemptQuestion(mock, true)
+				expectMatchedAnswers(mock, "{3,9}", 1)
+			},
+			field: "answer_ids",
+		},
+		{
+			name: "question outside the attempt",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAttemptQuestion(mock, false)
+			},
+			field: "question_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			expectOpenAttempt(mock, models.AttemptInProgress)
+			tt.expect(mock)
+			mock.ExpectRollback()
+
+			err := NewAttemptRepository(db).RecordResponse(context.Background(), "1", "5", models.AttemptResponse{
+				QuestionID: 7,
+				AnswerIDs:  []int64{3, 9},
+			})
+			expectValidation(t, err, tt.field)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAttemptRepositoryRecordResponseAfterSubmit(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectOpenAttempt(mock, models.AttemptSubmitted)
+	mock.ExpectRollback()
+
+	err := NewAttemptRepository(db).RecordResponse(context.Background(), "1", "5", models.AttemptResponse{
+		QuestionID: 7,
+		AnswerIDs:  []int64{3},
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Submit grades from the answers table, not from anything the player sent:
+// question 7 is answered correctly and question 8, never answered, still
+// counts towards the maximum score.
+func TestAttemptRepositorySubmitGradesFromAnswers(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectOpenAttempt(mock, models.AttemptInProgress)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT adaptive, user_id FROM attempts WHERE id = $1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"adaptive", "user_id"}).AddRow(false, ""))
+
+	expectAttemptQuestion(mock, true)
+	expectMatchedAnswers(mock, "{1}", 1)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO attempt_responses")).
+		WithArgs(5, 7, "{1}", "{}", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN answers a ON a.question_id = q.id")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "scoring", "config", "answer_id", "is_correct", "position", "match_text"}).
+			AddRow(7, models.QuestionTypeMultipleChoice, models.ScoringAllOrNothing, nil, 1, true, nil, nil).
+			AddRow(7, models.QuestionTypeMultipleChoice, models.ScoringAllOrNothing, nil, 2, false, nil, nil).
+			AddRow(8, models.QuestionTypeTrueFalse, models.ScoringAllOrNothing, nil, 3, true, nil, nil).
+			AddRow(8, models.QuestionTypeTrueFalse, models.ScoringAllOrNothing, nil, 4, false, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM attempt_hints ah")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"question_id", "penalty"}))
+
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	responseColumns := []string{"id", "attempt_id", "question_id", "answer_ids", "text_answers", "is_correct", "points",
+		"time_spent_ms", "hints_used", "responded_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM attempt_responses r WHERE r.attempt_id = $1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(responseColumns).AddRow(11, 5, 7, "{1}", "{}", nil, nil, nil, 0, now))
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE attempt_responses SET is_correct = $1, points = $2 WHERE id = $3")).
+		WithArgs(true, 1.0, 11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE attempts SET status = $1, score = $2, max_score = $3")).
+		WithArgs(models.AttemptSubmitted, 1.0, 2.0, nil, nil, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO quiz_stats")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO item_stats")).WithArgs("1", 7, sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO item_stats")).WithArgs("1", 8, sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO answer_stats")).
+		WithArgs("1", "{1}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM attempts WHERE id = $1 AND quiz_id = $2")).
+		WithArgs("5", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quiz_id", "user_id", "status", "seed", "adaptive", "score",
+			"max_score", "ability", "ability_se", "started_at", "submitted_at"}).
+			AddRow(5, 1, "", models.AttemptSubmitted, 42, false, 1.0, 2.0, nil, nil, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT question_id FROM attempt_questions WHERE attempt_id = $1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"question_id"}).AddRow(7).AddRow(8))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM attempt_responses r WHERE r.attempt_id = $1")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(responseColumns).AddRow(11, 5, 7, "{1}", "{}", true, 1.0, nil, 0, now))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT q.id, q.explanation")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "explanation", "answer_id", "is_correct", "rationale"}))
+
+	attempt, err := NewAttemptRepository(db).Submit(context.Background(), "1", "5", []models.AttemptResponse{
+		{QuestionID: 7, AnswerIDs: []int64{1, 1}},
+	})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempt.Status != models.AttemptSubmitted || *attempt.Score != 1 || *attempt.MaxScore != 2 {
+		t.Errorf("attempt = %+v, want submitted scoring 1 of 2", attempt)
+	}
+}
+
+// A failed submit grades nothing and leaves the attempt open. sqlmock fails
+// the call on any UPDATE, none of which are registered.
+func TestAttemptRepositorySubmitRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		expect func(mock sqlmock.Sqlmock)
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "already submitted",
+			status: models.AttemptSubmitted,
+			expect: func(mock sqlmock.Sqlmock) {},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrConflict) {
+					t.Fatalf("expected ErrConflict, got %v", err)
+				}
+			},
+		},
+		{
+			name:   "answer from another question",
+			status: models.AttemptInProgress,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAttemptQuestion(mock, true)
+				expectMatchedAnswers(mock, "{3,9}", 1)
+			},
+			check: func(t *testing.T, err error) { expectValidation(t, err, "answer_ids") },
+		},
+		{
+			name:   "question outside the attempt",
+			status: models.AttemptInProgress,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAttemptQuestion(mock, false)
+			},
+			check: func(t *testing.T, err error) { expectValidation(t, err, "question_id") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			expectOpenAttempt(mock, tt.status)
+			if tt.status == models.AttemptInProgress {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT adaptive, user_id FROM attempts WHERE id = $1")).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"adaptive", "user_id"}).AddRow(false, "ada"))
+			}
+			tt.expect(mock)
+			mock.ExpectRollback()
+
+			_, err := NewAttemptRepository(db).Submit(context.Background(), "1", "5", []models.AttemptResponse{
+				{QuestionID: 7, AnswerIDs: []int64{3, 9}},
+			})
+			tt.check(t, err)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type BankRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewBankRepository(db *sqlx.DB, opts ...Option) *BankRepository {
+	return &BankRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+func (r *BankRepository) GetAll(ctx context.Context) ([]models.Bank, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	banks := []models.Bank{}
+	err := r.db.SelectContext(ctx, &banks, "SELECT id, title, description FROM banks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	return banks, nil
+}
+
+func (r *BankRepository) GetByID(ctx context.Context, id string) (*models.Bank, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	bank := &models.Bank{}
+	err := r.db.GetContext(ctx, bank, "SELECT id, title, description FROM banks WHERE id = $1", id)
+	if err != nil {
+		return nil, dbError(err, "bank")
+	}
+
+	return bank, nil
+}
+
+// GetQuestions returns the questions in a bank that match the query, with
+// their answers, hints and tags, oldest first.
+func (r *BankRepository) GetQuestions(ctx context.Context, id string, query models.QuestionQuery) ([]models.FullQuestion, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter, err := ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	params := []interface{}{id}
+	set := bankQuestions.withTags(filter, &params)
+
+	tx, err := r.db.BeginTxx(ctx, readSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	questions, err := loadFullQuestions(ctx, tx, set, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+func (r *BankRepository) Create(ctx context.Context, input models.BankInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.Title == "" {
+		return 0, Invalid("title", "is required")
+	}
+
+	var bankID int64
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO banks (title, description) VALUES ($1, $2) RETURNING id",
+		input.Title, input.Description,
+	).Scan(&bankID)
+	if err != nil {
+		return 0, err
+	}
+
+	return bankID, nil
+}
+
+// AddQuestion inserts a question and its answers into a bank in one
+// transaction. Bank questions are numbered in the order they are added;
+// attempts draw them in a random order regardless.
+func (r *BankRepository) AddQuestion(ctx context.Context, id string, draft models.QuestionDraft) (*models.CreatedQuestion, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	bankID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, Invalid("bank_id", "must be an integer")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderNum := draft.Order
+	if orderNum == 0 {
+		err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(order_num), 0) + 1 FROM questions WHERE bank_id = $1", bankID).Scan(&orderNum)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := insertQuestionDraft(ctx, tx, questionOwner{"bank_id", bankID}, draft, orderNum)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// Replace overwrites every column of a bank, as PUT requires. A missing
+// description is stored as null.
+func (r *BankRepository) Replace(ctx context.Context, id string, input models.BankInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.Title == "" {
+		return Invalid("title", "is required")
+	}
+
+	query, params, err := newUpdateBuilder("banks").
+		Set("title", input.Title).
+		Set("description", input.Description).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, params...)
+	return requireRow(result, err, "bank")
+}
+
+// Delete removes a bank with its questions and every draw rule that picks
+// from it.
+func (r *BankRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM banks WHERE id = $1", id)
+	return requireRow(result, err, "bank")
+}
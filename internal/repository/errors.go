@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Kinds of domain error. Every error a store returns on purpose wraps one of
+// these, so callers can tell them apart with errors.Is. Anything else is an
+// unexpected failure whose text should not reach clients.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+	ErrReference  = errors.New("invalid reference")
+)
+
+// Error is a domain error. Its message is written for clients and never
+// contains SQL.
+type Error struct {
+	Kind    error
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return e.Field + " " + e.Message
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound reports that the named resource does not exist.
+func NotFound(resource string) error {
+	return &Error{Kind: ErrNotFound, Message: resource + " not found"}
+}
+
+// Invalid reports a field that breaks a rule the store enforces. field may be
+// empty when the problem is with the request as a whole.
+func Invalid(field, message string) error {
+	return &Error{Kind: ErrValidation, Field: field, Message: message}
+}
+
+// Conflict reports a request that clashes with the current state.
+func Conflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+// InvalidReference reports a resource that points at a parent which does not
+// exist.
+func InvalidReference(resource string) error {
+	return &Error{Kind: ErrReference, Message: resource + " references a record that does not exist"}
+}
+
+// Postgres error codes the stores translate.
+const (
+	pqForeignKeyViolation  = "23503"
+	pqUniqueViolation      = "23505"
+	pqCheckViolation       = "23514"
+	pqInvalidTextRepresent = "22P02"
+)
+
+// dbError turns a database error about resource into a domain error where
+// there is one. Other errors are returned unchanged.
+func dbError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return NotFound(resource)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqForeignKeyViolation:
+			return InvalidReference(resource)
+		case pqUniqueViolation:
+			return Conflict(resource + " already exists")
+		case pqCheckViolation:
+			return Invalid("", resource+" breaks a constraint")
+		case pqInvalidTextRepresent:
+			return Invalid("id", "must be an integer")
+		}
+	}
+
+	return err
+}
+
+// requireRow reports resource as not found when an UPDATE or DELETE matched
+// no rows.
+func requireRow(result sql.Result, err error, resource string) error {
+	if err != nil {
+		return dbError(err, resource)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return NotFound(resource)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestQuestionCreateMapsForeignKeyViolation(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO questions")).
+		WillReturnError(&pq.Error{
+			Code:    pqForeignKeyViolation,
+			Message: `insert or update on table "questions" violates foreign key constraint "questions_quiz_id_fkey"`,
+		})
+
+	_, err := NewQuestionRepository(db).Create(context.Background(), models.QuestionInput{
+		QuizID:   99,
+		Question: "Orphan",
+		Type:     models.QuestionTypeTrueFalse,
+	})
+	if !errors.Is(err, ErrReference) {
+		t.Fatalf("expected ErrReference, got %v", err)
+	}
+	if strings.Contains(err.Error(), "constraint") {
+		t.Errorf("error leaks SQL detail: %q", err)
+	}
+}
+
+func TestDeleteMissingRowIsNotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM quizzes WHERE id = $1")).
+		WithArgs("99").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := NewQuizRepository(db).Delete(context.Background(), "99")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReorderMissingQuizIsNotFound(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM quizzes WHERE id = $1 FOR UPDATE")).
+		WithArgs("99").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := NewQuestionRepository(db).Reorder(context.Background(), "99", []int64{1})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnexpectedErrorsPassThrough(t *testing.T) {
+	db, mock := newMockDB(t)
+	failure := errors.New("connection reset")
+	mock.ExpectQuery(regexp.QuoteMeta("FROM quizzes WHERE id = $1")).WillReturnError(failure)
+
+	_, err := NewQuizRepository(db).GetByID(context.Background(), "1")
+	var domainErr *Error
+	if errors.As(err, &domainErr) || !errors.Is(err, failure) {
+		t.Fatalf("expected the raw error, got %v", err)
+	}
+}
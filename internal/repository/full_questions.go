@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// questionSet picks the questions loadFullQuestions loads: a join onto
+// questions q, a condition on its parameters, and the order the questions
+// come back in.
+type questionSet struct {
+	join  string
+	where string
+	order string
+}
+
+var (
+	quizQuestions    = questionSet{where: "q.quiz_id = $1", order: "q.order_num, q.id"}
+	bankQuestions    = questionSet{where: "q.bank_id = $1", order: "q.id"}
+	questionsByID    = questionSet{where: "q.id = ANY($1)", order: "q.id"}
+	attemptQuestions = questionSet{
+		join:  " JOIN attempt_questions aq ON aq.question_id = q.id",
+		where: "aq.attempt_id = $1",
+		order: "aq.position",
+	}
+)
+
+// readSnapshot begins a transaction for loadFullQuestions and the reads
+// around it, so every query sees the database as of the same moment and
+// rows cannot be attached to questions they no longer belong to.
+var readSnapshot = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+const questionColumns = "q.id, q.quiz_id, q.bank_id, q.question, q.type, q.scoring, q.order_num, q.config, q.explanation"
+
+// withTags narrows a set to the questions matching a tag filter, adding the
+// filter's parameters to params.
+func (set questionSet) withTags(filter TagFilter, params *[]interface{}) questionSet {
+	if filter == nil {
+		return set
+	}
+
+	param := func(value interface{}) string {
+		*params = append(*params, value)
+		return "$" + strconv.Itoa(len(*params))
+	}
+	set.where += " AND " + filter.condition("question_tags", "question_id", "q.id", param)
+	return set
+}
+
+// loadFullQuestions loads a set of questions with their answers, hints and
+// tags. It always runs four queries, however many questions there are, so
+// outside a transaction callers begin one with readSnapshot.
+func loadFullQuestions(ctx context.Context, db sqlx.QueryerContext, set questionSet, params ...interface{}) ([]models.FullQuestion, error) {
+	from := " JOIN questions q ON q.id = "
+	filter := set.join + " WHERE " + set.where
+
+	var questions []models.Question
+	err := sqlx.SelectContext(ctx, db, &questions,
+		"SELECT "+questionColumns+" FROM questions q"+filter+" ORDER BY "+set.order, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var answers []models.Answer
+	err = sqlx.SelectContext(ctx, db, &answers,
+		"SELECT a.id, a.question_id, a.answer, a.is_correct, a.position, a.match_text, a.rationale FROM answers a"+
+			from+"a.question_id"+filter+" ORDER BY a.question_id, a.id", params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var hints []models.Hint
+	err = sqlx.SelectContext(ctx, db, &hints,
+		"SELECT h.id, h.question_id, h.tier, h.hint, h.penalty FROM question_hints h"+
+			from+"h.question_id"+filter+" ORDER BY h.question_id, h.tier", params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []struct {
+		QuestionID int    `db:"question_id"`
+		Name       string `db:"name"`
+	}
+	err = sqlx.SelectContext(ctx, db, &tags,
+		"SELECT qt.question_id, t.name FROM question_tags qt JOIN tags t ON t.id = qt.tag_id"+
+			from+"qt.question_id"+filter+" ORDER BY qt.question_id, t.name", params...)
+	if err != nil {
+		return nil, err
+	}
+
+	full := make([]models.FullQuestion, 0, len(questions))
+	index := make(map[int]int, len(questions))
+	for i, question := range questions {
+		index[question.ID] = i
+		full = append(full, models.FullQuestion{
+			Question: question,
+			Answers:  []models.Answer{},
+			Hints:    []models.Hint{},
+			Tags:     []string{},
+		})
+	}
+	for _, answer := range answers {
+		i, ok := index[answer.QuestionID]
+		if !ok {
+			continue
+		}
+		full[i].Answers = append(full[i].Answers, answer)
+	}
+	for _, hint := range hints {
+		i, ok := index[hint.QuestionID]
+		if !ok {
+			continue
+		}
+		full[i].Hints = append(full[i].Hints, hint)
+	}
+	for _, tag := range tags {
+		i, ok := index[tag.QuestionID]
+		if !ok {
+			continue
+		}
+		full[i].Tags = append(full[i].Tags, tag.Name)
+	}
+
+	return full, nil
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/irt"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+type AdaptiveRepository struct {
+	s *Store
+}
+
+func (r *AdaptiveRepository) GetSettings(ctx context.Context, quizID string) (*models.AdaptiveSettings, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+	settings, ok := r.s.adaptive[id]
+	if !ok {
+		return nil, repository.NotFound("adaptive settings")
+	}
+
+	return &settings, nil
+}
+
+func (r *AdaptiveRepository) ReplaceSettings(ctx context.Context, quizID string, settings models.AdaptiveSettings) (*models.AdaptiveSettings, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.CheckAdaptiveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+	r.s.adaptive[id] = settings
+
+	return &settings, nil
+}
+
+func (r *AdaptiveRepository) DeleteSettings(ctx context.Context, quizID string) error {
+	id, err := parseID(quizID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.adaptive[id]; !ok {
+		return repository.NotFound("adaptive settings")
+	}
+	delete(r.s.adaptive, id)
+
+	return nil
+}
+
+func (r *AdaptiveRepository) GetCalibrations(ctx context.Context, quizID string) ([]models.Calibration, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	model := r.s.adaptive[id].Model
+	return repository.CalibrationsOf(model, r.s.adaptivePool(id), r.s.calibrations), nil
+}
+
+func (r *AdaptiveRepository) Calibrate(ctx context.Context, quizID string) ([]models.Calibration, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+	settings, ok := r.s.adaptive[id]
+	if !ok {
+		return nil, repository.Conflict("quiz is not adaptive")
+	}
+
+	pool := r.s.adaptivePool(id)
+	inPool := make(map[int]bool, len(pool))
+	for _, questionID := range pool {
+		inPool[questionID] = true
+	}
+
+	// Every submitted attempt given a pool question, whatever its quiz
+	correct := make(map[hintUse]bool)
+	for _, response := range r.s.responses {
+		if response.IsCorrect != nil {
+			correct[hintUse{attemptID: response.AttemptID, questionID: response.QuestionID}] = *response.IsCorrect
+		}
+	}
+	attemptIDs := make([]int, 0, len(r.s.attempts))
+	for attemptID, attempt := range r.s.attempts {
+		if attempt.Status == models.AttemptSubmitted {
+			attemptIDs = append(attemptIDs, attemptID)
+		}
+	}
+	sort.Ints(attemptIDs)
+
+	var observations []irt.Observation
+	for _, attemptID := range attemptIDs {
+		for _, questionID := range r.s.attemptQuestions[attemptID] {
+			if inPool[questionID] {
+				observations = append(observations, irt.Observation{
+					Person:  attemptID,
+					Item:    questionID,
+					Correct: correct[hintUse{attemptID: attemptID, questionID: questionID}],
+				})
+			}
+		}
+	}
+
+	calibratedAt := r.s.clock.Now()
+	for _, c := range irt.Calibrate(observations, settings.Model == models.AdaptiveModel2PL) {
+		r.s.calibrations[c.ID] = models.Calibration{
+			QuestionID:     c.ID,
+			Difficulty:     c.Difficulty,
+			Discrimination: c.Discrimination,
+			Responses:      c.Responses,
+			CalibratedAt:   &calibratedAt,
+		}
+	}
+
+	return repository.CalibrationsOf(settings.Model, pool, r.s.calibrations), nil
+}
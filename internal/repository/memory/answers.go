@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+type AnswerRepository struct {
+	s *Store
+}
+
+func (r *AnswerRepository) GetByID(ctx context.Context, id string) (*models.Answer, error) {
+	answerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	answer, ok := r.s.answers[answerID]
+	if !ok {
+		return nil, repository.NotFound("answer")
+	}
+
+	return &answer, nil
+}
+
+func (r *AnswerRepository) GetByQuestionID(ctx context.Context, questionID string) ([]models.Answer, error) {
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	return r.s.answersOf(id), nil
+}
+
+func (r *AnswerRepository) Create(ctx context.Context, input models.AnswerInput) (int64, error) {
+	if input.QuestionID == 0 {
+		return 0, repository.Invalid("question_id", "is required")
+	}
+	if input.Answer == "" {
+		return 0, repository.Invalid("answer", "is required")
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[input.QuestionID]; !ok {
+		return 0, repository.InvalidReference("answer")
+	}
+
+	id := r.s.nextID("answers")
+	r.s.answers[id] = models.Answer{
+		ID:         id,
+		QuestionID: input.QuestionID,
+		Answer:     input.Answer,
+		Correct:    input.Correct,
+		Position:   input.Position,
+		Match:      input.Match,
+		Rationale:  input.Rationale,
+	}
+	if err := r.s.checkQuestion(input.QuestionID); err != nil {
+		delete(r.s.answers, id)
+		return 0, err
+	}
+
+	return int64(id), nil
+}
+
+func (r *AnswerRepository) Replace(ctx context.Context, id string, input models.AnswerInput) error {
+	if input.Answer == "" {
+		return repository.Invalid("answer", "is required")
+	}
+
+	answerID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	answer, ok := r.s.answers[answerID]
+	if !ok {
+		return repository.NotFound("answer")
+	}
+
+	answer.Answer = input.Answer
+	answer.Correct = input.Correct
+	answer.Position = input.Position
+	answer.Match = input.Match
+	answer.Rationale = input.Rationale
+
+	return r.s.replaceAnswer(answer)
+}
+
+func (r *AnswerRepository) Patch(ctx context.Context, id string, patch models.AnswerPatch) error {
+	if patch.Answer.IsValue() && patch.Answer.Value == "" {
+		return repository.Invalid("answer", "must not be empty")
+	}
+
+	answerID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	answer := r.s.answers[answerID]
+	answerSet, err := applyOptional(patch.Answer, "answer", false, func(v *string) { answer.Answer = *v })
+	if err != nil {
+		return err
+	}
+	correctSet, err := applyOptional(patch.Correct, "is_correct", false, func(v *bool) { answer.Correct = *v })
+	if err != nil {
+		return err
+	}
+	positionSet, err := applyOptional(patch.Position, "position", true, func(v *int) { answer.Position = v })
+	if err != nil {
+		return err
+	}
+	matchSet, err := applyOptional(patch.Match, "match_text", true, func(v *string) { answer.Match = v })
+	if err != nil {
+		return err
+	}
+	rationaleSet, err := applyOptional(patch.Rationale, "rationale", true, func(v *string) { answer.Rationale = v })
+	if err != nil {
+		return err
+	}
+	if !answerSet && !correctSet && !positionSet && !matchSet && !rationaleSet {
+		return repository.Invalid("", "no valid fields to update")
+	}
+
+	if _, ok := r.s.answers[answerID]; !ok {
+		return repository.NotFound("answer")
+	}
+
+	return r.s.replaceAnswer(answer)
+}
+
+func (r *AnswerRepository) Delete(ctx context.Context, id string) error {
+	answerID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.answers[answerID]; !ok {
+		return repository.NotFound("answer")
+	}
+
+	delete(r.s.answers, answerID)
+	r.s.forgetSelections(answerID)
+	return nil
+}
+
+// replaceAnswer stores a changed answer if its question still suits its type.
+// The caller must hold the lock.
+func (s *Store) replaceAnswer(answer models.Answer) error {
+	previous := s.answers[answer.ID]
+	s.answers[answer.ID] = answer
+	if err := s.checkQuestion(answer.QuestionID); err != nil {
+		s.answers[answer.ID] = previous
+		return err
+	}
+
+	return nil
+}
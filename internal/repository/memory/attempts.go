@@ -0,0 +1,499 @@
+package memory
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/draw"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/changangus/go-quiz-backend/internal/scoring"
+)
+
+type AttemptRepository struct {
+	s *Store
+}
+
+func (r *AttemptRepository) Start(ctx context.Context, quizID string, start models.AttemptStart) (*models.Attempt, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := rand.Int63()
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	// Adaptive attempts are given their questions one at a time by Next
+	_, adaptive := r.s.adaptive[id]
+	questions := []int{}
+	if !adaptive {
+		questions, err = r.s.drawQuestions(id, seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attempt := models.Attempt{
+		ID:        r.s.nextID("attempts"),
+		QuizID:    id,
+		UserID:    start.UserID,
+		Status:    models.AttemptInProgress,
+		Seed:      seed,
+		Adaptive:  adaptive,
+		StartedAt: r.s.clock.Now(),
+	}
+	r.s.attempts[attempt.ID] = attempt
+	r.s.attemptQuestions[attempt.ID] = questions
+
+	attempt.QuestionIDs = append([]int{}, questions...)
+	return &attempt, nil
+}
+
+func (r *AttemptRepository) GetByID(ctx context.Context, quizID string, attemptID string) (*models.Attempt, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.attemptOf(quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.QuestionIDs = append([]int{}, r.s.attemptQuestions[attempt.ID]...)
+	attempt.Responses = r.s.responsesOf(attempt)
+	return &attempt, nil
+}
+
+func (r *AttemptRepository) GetQuestions(ctx context.Context, quizID string, attemptID string) ([]models.FullQuestion, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.attemptOf(quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := []models.FullQuestion{}
+	for _, questionID := range r.s.attemptQuestions[attempt.ID] {
+		questions = append(questions, r.s.fullQuestion(r.s.questions[questionID]))
+	}
+	return questions, nil
+}
+
+func (r *AttemptRepository) GetByQuizID(ctx context.Context, quizID string, userID string) ([]models.Attempt, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempts := []models.Attempt{}
+	for _, attempt := range r.s.attempts {
+		if attempt.QuizID == id && (userID == "" || attempt.UserID == userID) {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	sort.Slice(attempts, func(i, j int) bool { return attempts[i].ID < attempts[j].ID })
+	return attempts, nil
+}
+
+func (r *AttemptRepository) RecordResponse(ctx context.Context, quizID string, attemptID string, response models.AttemptResponse) error {
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.openAttempt(quizID, attemptID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.validateResponse(attempt.ID, response); err != nil {
+		return err
+	}
+	r.s.recordResponse(attempt.ID, response)
+
+	return nil
+}
+
+func (r *AttemptRepository) Submit(ctx context.Context, quizID string, attemptID string, responses []models.AttemptResponse) (*models.Attempt, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.openAttempt(quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate every bulk response before recording any, matching the
+	// rollback the Postgres repository does on failure.
+	for _, response := range responses {
+		if err := r.s.validateResponse(attempt.ID, response); err != nil {
+			return nil, err
+		}
+	}
+	for _, response := range responses {
+		r.s.recordResponse(attempt.ID, response)
+	}
+
+	keys := make(map[int]scoring.Key)
+	for _, questionID := range r.s.attemptQuestions[attempt.ID] {
+		keys[questionID] = r.s.answerKey(questionID)
+	}
+
+	score := 0.0
+	maxScore := float64(len(keys))
+	correct := make(map[int]bool)
+	outcomes := make(map[int]analytics.Outcome, len(keys))
+	for questionID := range keys {
+		outcomes[questionID] = analytics.Outcome{MaxScore: maxScore}
+	}
+	var selected []int64
+	for id, response := range r.s.responses {
+		if response.AttemptID != attempt.ID {
+			continue
+		}
+
+		key := keys[response.QuestionID]
+		points := key.Score(response.AnswerIDs, response.Text)
+		isCorrect := points == 1
+		correct[response.QuestionID] = isCorrect
+		points = scoring.WithHints(points, r.s.hintPenalty(attempt.ID, response.QuestionID))
+		score += points
+
+		response.IsCorrect = &isCorrect
+		response.Points = &points
+		r.s.responses[id] = response
+
+		outcomes[response.QuestionID] = analytics.Outcome{
+			Answered:    true,
+			Correct:     isCorrect,
+			Points:      points,
+			MaxScore:    maxScore,
+			TimeSpentMs: response.TimeSpentMs,
+		}
+		if analytics.CountsSelections(key.Type) {
+			selected = append(selected, response.AnswerIDs...)
+		}
+	}
+
+	for questionID, outcome := range outcomes {
+		outcome.Score = score
+		outcomes[questionID] = outcome
+	}
+	r.s.recordStats(attempt.QuizID, analytics.ObserveQuiz(score, maxScore), outcomes, selected)
+	r.s.recordReviews(attempt.UserID, outcomes)
+
+	if attempt.Adaptive {
+		administered := r.s.attemptQuestions[attempt.ID]
+		model := r.s.adaptive[attempt.QuizID].Model
+		ability, standardError := repository.AdaptiveAbility(model, administered, correct, r.s.calibrations)
+		attempt.Ability = &ability
+		attempt.StandardError = &standardError
+	}
+
+	submittedAt := r.s.clock.Now()
+	attempt.Status = models.AttemptSubmitted
+	attempt.Score = &score
+	attempt.MaxScore = &maxScore
+	attempt.SubmittedAt = &submittedAt
+	r.s.attempts[attempt.ID] = attempt
+
+	attempt.Responses = r.s.responsesOf(attempt)
+	return &attempt, nil
+}
+
+func (r *AttemptRepository) Next(ctx context.Context, quizID string, attemptID string) (*models.AdaptiveStep, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.openAttempt(quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	if !attempt.Adaptive {
+		return nil, repository.Conflict("attempt is not adaptive")
+	}
+	settings, ok := r.s.adaptive[attempt.QuizID]
+	if !ok {
+		return nil, repository.Conflict("quiz is no longer adaptive")
+	}
+
+	correct := make(map[int]bool)
+	answered := 0
+	for _, response := range r.s.responses {
+		if response.AttemptID == attempt.ID {
+			key := r.s.answerKey(response.QuestionID)
+			correct[response.QuestionID] = key.Score(response.AnswerIDs, response.Text) == 1
+			answered++
+		}
+	}
+
+	administered := r.s.attemptQuestions[attempt.ID]
+	step := repository.PlanAdaptive(settings, administered, correct, r.s.adaptivePool(attempt.QuizID), r.s.calibrations)
+	result := &models.AdaptiveStep{
+		Done:          step.Next == nil,
+		Answered:      answered,
+		Ability:       step.Ability,
+		StandardError: step.StandardError,
+	}
+	if step.Next == nil {
+		return result, nil
+	}
+
+	if n := len(administered); n == 0 || administered[n-1] != step.Next.ID {
+		r.s.attemptQuestions[attempt.ID] = append(administered[:n:n], step.Next.ID)
+	}
+	question := models.NewPlayerQuestion(r.s.fullQuestion(r.s.questions[step.Next.ID]))
+	result.Question = &question
+	return result, nil
+}
+
+func (r *AttemptRepository) RevealHint(ctx context.Context, quizID string, attemptID string, questionID int) (*models.RevealedHint, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	attempt, err := r.s.openAttempt(quizID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.s.givenQuestion(attempt.ID, questionID) {
+		return nil, repository.NotFound("question")
+	}
+
+	use := hintUse{attemptID: attempt.ID, questionID: questionID}
+	hints := r.s.hintsOf(questionID)
+	revealed := r.s.revealed[use]
+	if revealed >= len(hints) {
+		return nil, repository.Conflict("question has no more hints")
+	}
+	r.s.revealed[use] = revealed + 1
+
+	hint := hints[revealed]
+	return &models.RevealedHint{
+		QuestionID: questionID,
+		Tier:       hint.Tier,
+		Hint:       hint.Hint,
+		Penalty:    hint.Penalty,
+		Remaining:  len(hints) - revealed - 1,
+	}, nil
+}
+
+// attemptOf looks up an attempt that belongs to quizID. The caller must hold
+// the lock.
+func (s *Store) attemptOf(quizID string, attemptID string) (models.Attempt, error) {
+	quiz, err := parseID(quizID)
+	if err != nil {
+		return models.Attempt{}, err
+	}
+	id, err := parseID(attemptID)
+	if err != nil {
+		return models.Attempt{}, err
+	}
+
+	attempt, ok := s.attempts[id]
+	if !ok || attempt.QuizID != quiz {
+		return models.Attempt{}, repository.NotFound("attempt")
+	}
+
+	return attempt, nil
+}
+
+func (s *Store) openAttempt(quizID string, attemptID string) (models.Attempt, error) {
+	attempt, err := s.attemptOf(quizID, attemptID)
+	if err != nil {
+		return attempt, err
+	}
+
+	if attempt.Status != models.AttemptInProgress {
+		return attempt, repository.Conflict("attempt has already been submitted")
+	}
+
+	return attempt, nil
+}
+
+// drawQuestions picks the questions of a new attempt at a quiz with seed,
+// as the Postgres repository does. The caller must hold the lock.
+func (s *Store) drawQuestions(quizID int, seed int64) ([]int, error) {
+	fixed, rules, pools := s.drawSources(quizID)
+	questions, err := draw.Assemble(seed, fixed, rules, pools)
+	if err != nil {
+		return nil, repository.Conflict(err.Error())
+	}
+	return questions, nil
+}
+
+// adaptivePool returns every question an attempt at a quiz could be asked.
+// See draw.Pool. The caller must hold the lock.
+func (s *Store) adaptivePool(quizID int) []int {
+	fixed, _, pools := s.drawSources(quizID)
+	return draw.Pool(fixed, pools)
+}
+
+// drawSources returns a quiz's own questions, its draw rules, and for each
+// rule the questions of its bank that match it. The caller must hold the
+// lock.
+func (s *Store) drawSources(quizID int) ([]int, []models.DrawRule, [][]int) {
+	var fixed []int
+	for _, question := range s.questionsOf(quizID) {
+		fixed = append(fixed, question.ID)
+	}
+
+	rules := s.drawRulesOf(quizID)
+	pools := make([][]int, len(rules))
+	for i, rule := range rules {
+		for _, question := range s.bankQuestionsOf(rule.BankID) {
+			if s.matchesTags(s.questionTags, question.ID, repository.RuleFilter(rule)) {
+				pools[i] = append(pools[i], question.ID)
+			}
+		}
+	}
+	return fixed, rules, pools
+}
+
+// givenQuestion reports whether an attempt was given a question. The caller
+// must hold the lock.
+func (s *Store) givenQuestion(attemptID int, questionID int) bool {
+	for _, id := range s.attemptQuestions[attemptID] {
+		if id == questionID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) validateResponse(attemptID int, response models.AttemptResponse) error {
+	question, ok := s.questions[response.QuestionID]
+	if !ok || !s.givenQuestion(attemptID, response.QuestionID) {
+		return repository.Invalid("question_id", "is not one of this attempt's questions")
+	}
+	if err := repository.CheckResponse(question, response); err != nil {
+		return err
+	}
+
+	for _, answerID := range response.AnswerIDs {
+		answer, ok := s.answers[int(answerID)]
+		if !ok || answer.QuestionID != response.QuestionID {
+			return repository.Invalid("answer_ids", "must all belong to the question")
+		}
+	}
+
+	return nil
+}
+
+// recordResponse upserts the response to a question, as the Postgres
+// repository does with ON CONFLICT, adding up the time spent.
+func (s *Store) recordResponse(attemptID int, response models.AttemptResponse) {
+	answerIDs := scoring.UniqueIDs(response.AnswerIDs)
+	for id, existing := range s.responses {
+		if existing.AttemptID == attemptID && existing.QuestionID == response.QuestionID {
+			existing.AnswerIDs = answerIDs
+			existing.Text = response.Text
+			existing.RespondedAt = s.clock.Now()
+			if existing.TimeSpentMs == nil {
+				existing.TimeSpentMs = response.TimeSpentMs
+			} else if response.TimeSpentMs != nil {
+				total := *existing.TimeSpentMs + *response.TimeSpentMs
+				existing.TimeSpentMs = &total
+			}
+			s.responses[id] = existing
+			return
+		}
+	}
+
+	id := s.nextID("attempt_responses")
+	s.responses[id] = models.AttemptResponse{
+		ID:          id,
+		AttemptID:   attemptID,
+		QuestionID:  response.QuestionID,
+		AnswerIDs:   answerIDs,
+		Text:        response.Text,
+		TimeSpentMs: response.TimeSpentMs,
+		RespondedAt: s.clock.Now(),
+	}
+}
+
+// answerKey returns what is needed to grade a question. The caller must
+// hold the lock.
+func (s *Store) answerKey(questionID int) scoring.Key {
+	question := s.questions[questionID]
+	key := scoring.Key{Type: question.Type, Strategy: question.Scoring, Config: question.Config}
+	for _, answer := range s.answersOf(questionID) {
+		key.AddAnswer(answer)
+	}
+	return key
+}
+
+// hintPenalty is the total penalty for the hints revealed to a question in
+// an attempt, which are its lowest tiers. The caller must hold the lock.
+func (s *Store) hintPenalty(attemptID int, questionID int) float64 {
+	revealed := s.revealed[hintUse{attemptID: attemptID, questionID: questionID}]
+	penalty := 0.0
+	for _, hint := range s.hintsOf(questionID) {
+		if hint.Tier <= revealed {
+			penalty += hint.Penalty
+		}
+	}
+	return penalty
+}
+
+// feedbackOn explains a question to a player who has submitted an attempt.
+// The caller must hold the lock.
+func (s *Store) feedbackOn(questionID int) *models.Feedback {
+	feedback := &models.Feedback{Answers: []models.AnswerFeedback{}}
+	if question, ok := s.questions[questionID]; ok {
+		feedback.Explanation = question.Explanation
+	}
+	for _, answer := range s.answersOf(questionID) {
+		feedback.Answers = append(feedback.Answers, models.AnswerFeedback{
+			ID:        answer.ID,
+			Correct:   answer.Correct,
+			Rationale: answer.Rationale,
+		})
+	}
+	return feedback
+}
+
+// responsesOf returns an attempt's responses ordered by question_id, with
+// the hints used on each and, once the attempt is submitted, feedback.
+func (s *Store) responsesOf(attempt models.Attempt) []models.AttemptResponse {
+	responses := []models.AttemptResponse{}
+	for _, response := range s.responses {
+		if response.AttemptID != attempt.ID {
+			continue
+		}
+		response.HintsUsed = s.revealed[hintUse{attemptID: attempt.ID, questionID: response.QuestionID}]
+		if attempt.Status == models.AttemptSubmitted {
+			response.Feedback = s.feedbackOn(response.QuestionID)
+		}
+		responses = append(responses, response)
+	}
+
+	sort.Slice(responses, func(i, j int) bool { return responses[i].QuestionID < responses[j].QuestionID })
+	return responses
+}
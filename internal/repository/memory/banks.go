@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+type BankRepository struct {
+	s *Store
+}
+
+func (r *BankRepository) GetAll(ctx context.Context) ([]models.Bank, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	banks := []models.Bank{}
+	for _, bank := range r.s.banks {
+		banks = append(banks, bank)
+	}
+
+	sort.Slice(banks, func(i, j int) bool { return banks[i].ID < banks[j].ID })
+	return banks, nil
+}
+
+func (r *BankRepository) GetByID(ctx context.Context, id string) (*models.Bank, error) {
+	bankID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	bank, ok := r.s.banks[bankID]
+	if !ok {
+		return nil, repository.NotFound("bank")
+	}
+
+	return &bank, nil
+}
+
+func (r *BankRepository) GetQuestions(ctx context.Context, id string, query models.QuestionQuery) ([]models.FullQuestion, error) {
+	bankID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := repository.ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.banks[bankID]; !ok {
+		return nil, repository.NotFound("bank")
+	}
+
+	questions := []models.FullQuestion{}
+	for _, question := range r.s.bankQuestionsOf(bankID) {
+		if r.s.matchesTags(r.s.questionTags, question.ID, filter) {
+			questions = append(questions, r.s.fullQuestion(question))
+		}
+	}
+	return questions, nil
+}
+
+func (r *BankRepository) Create(ctx context.Context, input models.BankInput) (int64, error) {
+	if input.Title == "" {
+		return 0, repository.Invalid("title", "is required")
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	id := r.s.nextID("banks")
+	r.s.banks[id] = models.Bank{ID: id, Title: input.Title, Description: input.Description}
+
+	return int64(id), nil
+}
+
+func (r *BankRepository) AddQuestion(ctx context.Context, id string, draft models.QuestionDraft) (*models.CreatedQuestion, error) {
+	bankID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, repository.Invalid("bank_id", "must be an integer")
+	}
+	if err := validateQuestionDraft(draft); err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.banks[bankID]; !ok {
+		return nil, repository.InvalidReference("question")
+	}
+
+	orderNum := draft.Order
+	if orderNum == 0 {
+		orderNum = 1
+		for _, question := range r.s.bankQuestionsOf(bankID) {
+			if question.Order >= orderNum {
+				orderNum = question.Order + 1
+			}
+		}
+	}
+
+	created := r.s.insertQuestionDraft(nil, &bankID, draft, orderNum)
+	return &created, nil
+}
+
+func (r *BankRepository) Replace(ctx context.Context, id string, input models.BankInput) error {
+	if input.Title == "" {
+		return repository.Invalid("title", "is required")
+	}
+
+	bankID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.banks[bankID]; !ok {
+		return repository.NotFound("bank")
+	}
+	r.s.banks[bankID] = models.Bank{ID: bankID, Title: input.Title, Description: input.Description}
+
+	return nil
+}
+
+func (r *BankRepository) Delete(ctx context.Context, id string) error {
+	bankID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.banks[bankID]; !ok {
+		return repository.NotFound("bank")
+	}
+
+	r.s.deleteBank(bankID)
+	return nil
+}
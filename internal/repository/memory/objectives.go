@@ -0,0 +1,274 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+// objectiveLink records that a question assesses an objective.
+type objectiveLink struct {
+	questionID  int
+	objectiveID int
+}
+
+type ObjectiveRepository struct {
+	s *Store
+}
+
+func (r *ObjectiveRepository) GetAll(ctx context.Context, framework string) ([]models.Objective, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	objectives := []models.Objective{}
+	for _, objective := range r.s.objectives {
+		if framework == "" || objective.Framework == framework {
+			objectives = append(objectives, objective)
+		}
+	}
+
+	sortObjectives(objectives)
+	return objectives, nil
+}
+
+func (r *ObjectiveRepository) GetByID(ctx context.Context, id string) (*models.Objective, error) {
+	objectiveID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	objective, ok := r.s.objectives[objectiveID]
+	if !ok {
+		return nil, repository.NotFound("objective")
+	}
+
+	return &objective, nil
+}
+
+func (r *ObjectiveRepository) Create(ctx context.Context, input models.ObjectiveInput) (int64, error) {
+	if err := repository.CheckObjective(input); err != nil {
+		return 0, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	if r.s.objectiveTaken(input, 0) {
+		return 0, repository.Conflict("objective already exists")
+	}
+
+	id := r.s.nextID("objectives")
+	r.s.objectives[id] = models.Objective{
+		ID:          id,
+		Framework:   input.Framework,
+		Code:        input.Code,
+		Title:       input.Title,
+		Description: input.Description,
+	}
+
+	return int64(id), nil
+}
+
+func (r *ObjectiveRepository) Replace(ctx context.Context, id string, input models.ObjectiveInput) error {
+	if err := repository.CheckObjective(input); err != nil {
+		return err
+	}
+
+	objectiveID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.objectives[objectiveID]; !ok {
+		return repository.NotFound("objective")
+	}
+	if r.s.objectiveTaken(input, objectiveID) {
+		return repository.Conflict("objective already exists")
+	}
+
+	r.s.objectives[objectiveID] = models.Objective{
+		ID:          objectiveID,
+		Framework:   input.Framework,
+		Code:        input.Code,
+		Title:       input.Title,
+		Description: input.Description,
+	}
+
+	return nil
+}
+
+func (r *ObjectiveRepository) Delete(ctx context.Context, id string) error {
+	objectiveID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.objectives[objectiveID]; !ok {
+		return repository.NotFound("objective")
+	}
+
+	delete(r.s.objectives, objectiveID)
+	for link := range r.s.assesses {
+		if link.objectiveID == objectiveID {
+			delete(r.s.assesses, link)
+		}
+	}
+
+	return nil
+}
+
+func (r *ObjectiveRepository) GetQuestionObjectives(ctx context.Context, questionID string) ([]models.Objective, error) {
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[id]; !ok {
+		return nil, repository.NotFound("question")
+	}
+
+	return r.s.objectivesOf(id), nil
+}
+
+func (r *ObjectiveRepository) ReplaceQuestionObjectives(ctx context.Context, questionID string, objectiveIDs []int) ([]models.Objective, error) {
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[id]; !ok {
+		return nil, repository.NotFound("question")
+	}
+	for _, objectiveID := range objectiveIDs {
+		if _, ok := r.s.objectives[objectiveID]; !ok {
+			return nil, repository.InvalidReference("question objective")
+		}
+	}
+
+	for link := range r.s.assesses {
+		if link.questionID == id {
+			delete(r.s.assesses, link)
+		}
+	}
+	for _, objectiveID := range objectiveIDs {
+		r.s.assesses[objectiveLink{questionID: id, objectiveID: objectiveID}] = true
+	}
+
+	return r.s.objectivesOf(id), nil
+}
+
+func (r *ObjectiveRepository) Mastery(ctx context.Context, userID string, query models.MasteryQuery) ([]models.Mastery, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	byObjective := make(map[int]*models.Mastery)
+	seen := make(map[objectiveLink]bool)
+	for _, attempt := range r.s.attempts {
+		if attempt.UserID != userID || attempt.Status != models.AttemptSubmitted ||
+			query.QuizID != 0 && attempt.QuizID != query.QuizID {
+			continue
+		}
+
+		for _, questionID := range r.s.attemptQuestions[attempt.ID] {
+			points := r.s.pointsFor(attempt.ID, questionID)
+			for _, objective := range r.s.objectivesOf(questionID) {
+				if query.Framework != "" && objective.Framework != query.Framework {
+					continue
+				}
+
+				mastery, ok := byObjective[objective.ID]
+				if !ok {
+					mastery = &models.Mastery{Objective: objective}
+					byObjective[objective.ID] = mastery
+				}
+				if link := (objectiveLink{questionID: questionID, objectiveID: objective.ID}); !seen[link] {
+					seen[link] = true
+					mastery.Questions++
+				}
+				mastery.Points += points
+				mastery.MaxPoints++
+			}
+		}
+	}
+
+	report := []models.Mastery{}
+	for _, mastery := range byObjective {
+		mastery.Rate()
+		report = append(report, *mastery)
+	}
+
+	sort.Slice(report, func(i, j int) bool { return models.CompareObjectives(report[i].Objective, report[j].Objective) < 0 })
+	return report, nil
+}
+
+// objectiveTaken reports whether an objective other than exceptID already
+// has the framework and code of input. The caller must hold the lock.
+func (s *Store) objectiveTaken(input models.ObjectiveInput, exceptID int) bool {
+	for _, objective := range s.objectives {
+		if objective.ID != exceptID && objective.Framework == input.Framework && objective.Code == input.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// objectivesOf returns the objectives a question assesses, ordered by code.
+func (s *Store) objectivesOf(questionID int) []models.Objective {
+	objectives := []models.Objective{}
+	for link := range s.assesses {
+		if link.questionID == questionID {
+			objectives = append(objectives, s.objectives[link.objectiveID])
+		}
+	}
+
+	sortObjectives(objectives)
+	return objectives
+}
+
+// pointsFor returns what the response to a question earned in a submitted
+// attempt, or zero when it went unanswered.
+func (s *Store) pointsFor(attemptID int, questionID int) float64 {
+	for _, response := range s.responses {
+		if response.AttemptID == attemptID && response.QuestionID == questionID && response.Points != nil {
+			return *response.Points
+		}
+	}
+	return 0
+}
+
+func sortObjectives(objectives []models.Objective) {
+	sort.Slice(objectives, func(i, j int) bool { return models.CompareObjectives(objectives[i], objectives[j]) < 0 })
+}
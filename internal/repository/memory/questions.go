@@ -0,0 +1,443 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/changangus/go-quiz-backend/internal/scoring"
+)
+
+type QuestionRepository struct {
+	s *Store
+}
+
+func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*models.Question, error) {
+	questionID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	question, ok := r.s.questions[questionID]
+	if !ok {
+		return nil, repository.NotFound("question")
+	}
+
+	return &question, nil
+}
+
+func (r *QuestionRepository) GetByQuizID(ctx context.Context, quizID string, query models.QuestionQuery) ([]models.Question, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := repository.ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	var questions []models.Question
+	for _, question := range r.s.questionsOf(id) {
+		if r.s.matchesTags(r.s.questionTags, question.ID, filter) {
+			questions = append(questions, question)
+		}
+	}
+	return questions, nil
+}
+
+func (r *QuestionRepository) Create(ctx context.Context, input models.QuestionInput) (int64, error) {
+	if input.QuizID == 0 {
+		return 0, repository.Invalid("quiz_id", "is required")
+	}
+	if input.Question == "" {
+		return 0, repository.Invalid("question", "is required")
+	}
+	if input.Type == "" {
+		return 0, repository.Invalid("type", "is required")
+	}
+	strategy, err := repository.CheckQuestion(input.ToQuestion(), nil, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[input.QuizID]; !ok {
+		return 0, repository.InvalidReference("question")
+	}
+
+	id := r.s.nextID("questions")
+	quizID := input.QuizID
+	r.s.questions[id] = models.Question{
+		ID:          id,
+		QuizID:      &quizID,
+		Question:    input.Question,
+		Type:        input.Type,
+		Scoring:     strategy,
+		Order:       input.Order,
+		Config:      input.Config,
+		Explanation: input.Explanation,
+	}
+
+	return int64(id), nil
+}
+
+func (r *QuestionRepository) CreateWithAnswers(ctx context.Context, quizID string, draft models.QuestionDraft) (*models.CreatedQuestion, error) {
+	id, err := strconv.Atoi(quizID)
+	if err != nil {
+		return nil, repository.Invalid("quiz_id", "must be an integer")
+	}
+	if err := validateQuestionDraft(draft); err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.InvalidReference("question")
+	}
+
+	orderNum := draft.Order
+	if orderNum == 0 {
+		orderNum = 1
+		for _, question := range r.s.questionsOf(id) {
+			if question.Order >= orderNum {
+				orderNum = question.Order + 1
+			}
+		}
+	}
+
+	created := r.s.insertQuestionDraft(&id, nil, draft, orderNum)
+	return &created, nil
+}
+
+func (r *QuestionRepository) Reorder(ctx context.Context, quizID string, questionIDs []int64) error {
+	id, err := parseID(quizID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return repository.NotFound("quiz")
+	}
+
+	existing := r.s.questionsOf(id)
+	if len(scoring.UniqueIDs(questionIDs)) != len(questionIDs) {
+		return repository.Invalid("question_ids", "must not contain duplicates")
+	}
+	if len(questionIDs) != len(existing) {
+		return repository.Invalid("question_ids", "must list every question in the quiz")
+	}
+	for _, questionID := range questionIDs {
+		question, ok := r.s.questions[int(questionID)]
+		if !ok || !inQuiz(question, id) {
+			return repository.Invalid("question_ids", "contains question "+strconv.FormatInt(questionID, 10)+", which is not in this quiz")
+		}
+	}
+
+	for i, questionID := range questionIDs {
+		question := r.s.questions[int(questionID)]
+		question.Order = i + 1
+		r.s.questions[int(questionID)] = question
+	}
+
+	return nil
+}
+
+func (r *QuestionRepository) Replace(ctx context.Context, id string, input models.QuestionInput) error {
+	if input.Question == "" {
+		return repository.Invalid("question", "is required")
+	}
+	if input.Type == "" {
+		return repository.Invalid("type", "is required")
+	}
+	strategy, err := repository.CheckQuestion(input.ToQuestion(), nil, false)
+	if err != nil {
+		return err
+	}
+
+	questionID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	question, ok := r.s.questions[questionID]
+	if !ok {
+		return repository.NotFound("question")
+	}
+
+	question.Question = input.Question
+	question.Type = input.Type
+	question.Scoring = strategy
+	question.Order = input.Order
+	question.Config = input.Config
+	question.Explanation = input.Explanation
+
+	return r.s.replaceQuestion(question)
+}
+
+func (r *QuestionRepository) Patch(ctx context.Context, id string, patch models.QuestionPatch) error {
+	return r.PatchWithHints(ctx, id, patch, nil)
+}
+
+func (r *QuestionRepository) PatchWithHints(ctx context.Context, id string, patch models.QuestionPatch, hints []models.HintDraft) error {
+	if patch.Question.IsValue() && patch.Question.Value == "" {
+		return repository.Invalid("question", "must not be empty")
+	}
+	if hints != nil {
+		if err := repository.CheckHints(hints); err != nil {
+			return err
+		}
+	}
+
+	questionID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	question := r.s.questions[questionID]
+	questionSet, err := applyOptional(patch.Question, "question", false, func(v *string) { question.Question = *v })
+	if err != nil {
+		return err
+	}
+	typeSet, err := applyOptional(patch.Type, "type", false, func(v *string) { question.Type = *v })
+	if err != nil {
+		return err
+	}
+	scoringSet, err := applyOptional(patch.Scoring, "scoring", false, func(v *string) { question.Scoring = *v })
+	if err != nil {
+		return err
+	}
+	orderSet, err := applyOptional(patch.Order, "order_num", false, func(v *int) { question.Order = *v })
+	if err != nil {
+		return err
+	}
+	configSet, err := applyOptional(patch.Config, "config", true, func(v *models.QuestionConfig) { question.Config = v })
+	if err != nil {
+		return err
+	}
+	explanationSet, err := applyOptional(patch.Explanation, "explanation", true, func(v *string) { question.Explanation = v })
+	if err != nil {
+		return err
+	}
+	changed := questionSet || typeSet || scoringSet || orderSet || configSet || explanationSet
+	if !changed && hints == nil {
+		return repository.Invalid("", "no valid fields to update")
+	}
+
+	if _, ok := r.s.questions[questionID]; !ok {
+		return repository.NotFound("question")
+	}
+
+	if changed {
+		if err := r.s.replaceQuestion(question); err != nil {
+			return err
+		}
+	}
+	if hints != nil {
+		for hintID, hint := range r.s.hints {
+			if hint.QuestionID == questionID {
+				delete(r.s.hints, hintID)
+			}
+		}
+		r.s.insertHints(questionID, hints)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepository) GetHints(ctx context.Context, questionID string) ([]models.Hint, error) {
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[id]; !ok {
+		return nil, repository.NotFound("question")
+	}
+
+	return r.s.hintsOf(id), nil
+}
+
+func (r *QuestionRepository) ReplaceHints(ctx context.Context, questionID string, hints []models.HintDraft) ([]models.Hint, error) {
+	if err := repository.CheckHints(hints); err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[id]; !ok {
+		return nil, repository.NotFound("question")
+	}
+
+	for hintID, hint := range r.s.hints {
+		if hint.QuestionID == id {
+			delete(r.s.hints, hintID)
+		}
+	}
+
+	return r.s.insertHints(id, hints), nil
+}
+
+func (r *QuestionRepository) Delete(ctx context.Context, id string) error {
+	questionID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.questions[questionID]; !ok {
+		return repository.NotFound("question")
+	}
+
+	r.s.deleteQuestion(questionID)
+	return nil
+}
+
+func validateQuestionDraft(question models.QuestionDraft) error {
+	if question.Question == "" {
+		return repository.Invalid("question", "is required")
+	}
+	if question.Type == "" {
+		return repository.Invalid("type", "is required")
+	}
+
+	if _, err := repository.CheckQuestion(question.ToQuestion(), question.Answers, true); err != nil {
+		return err
+	}
+	if err := repository.CheckHints(question.Hints); err != nil {
+		return err
+	}
+	if _, err := repository.CheckTags(question.Tags); err != nil {
+		return err
+	}
+
+	for _, answer := range question.Answers {
+		if answer.Answer == "" {
+			return repository.Invalid("answer", "is required")
+		}
+	}
+
+	return nil
+}
+
+// replaceQuestion stores a changed question if it and its answers still
+// suit its type. The caller must hold the lock.
+func (s *Store) replaceQuestion(question models.Question) error {
+	previous := s.questions[question.ID]
+	s.questions[question.ID] = question
+	if err := s.checkQuestion(question.ID); err != nil {
+		s.questions[question.ID] = previous
+		return err
+	}
+
+	return nil
+}
+
+// checkQuestion checks a stored question and its answers against the rules of
+// its type. Answers may still be added later, so only the rules that more
+// answers could not fix are applied. The caller must hold the lock.
+func (s *Store) checkQuestion(questionID int) error {
+	question := s.questions[questionID]
+	var answers []models.AnswerDraft
+	for _, answer := range s.answersOf(questionID) {
+		answers = append(answers, answer.ToDraft())
+	}
+
+	_, err := repository.CheckQuestion(question, answers, false)
+	return err
+}
+
+// insertQuestionDraft stores an already validated question with its answers,
+// hints and tags in a quiz or a bank. The caller must hold the lock.
+func (s *Store) insertQuestionDraft(quizID *int, bankID *int, draft models.QuestionDraft, orderNum int) models.CreatedQuestion {
+	// Validation already accepted the scoring, so this only fills in the
+	// default
+	strategy, _ := repository.CheckQuestion(draft.ToQuestion(), nil, false)
+
+	questionID := s.nextID("questions")
+	s.questions[questionID] = models.Question{
+		ID:          questionID,
+		QuizID:      quizID,
+		BankID:      bankID,
+		Question:    draft.Question,
+		Type:        draft.Type,
+		Scoring:     strategy,
+		Order:       orderNum,
+		Config:      draft.Config,
+		Explanation: draft.Explanation,
+	}
+
+	created := models.CreatedQuestion{ID: int64(questionID), AnswerIDs: make([]int64, len(draft.Answers))}
+	for _, i := range repository.AnswerOrder(draft) {
+		answer := draft.Answers[i]
+		answerID := s.nextID("answers")
+		s.answers[answerID] = models.Answer{
+			ID:         answerID,
+			QuestionID: questionID,
+			Answer:     answer.Answer,
+			Correct:    answer.Correct,
+			Position:   answer.Position,
+			Match:      answer.Match,
+			Rationale:  answer.Rationale,
+		}
+		created.AnswerIDs[i] = int64(answerID)
+	}
+	s.insertHints(questionID, draft.Hints)
+
+	tags, _ := repository.CheckTags(draft.Tags)
+	for _, name := range tags {
+		s.questionTags[tagLink{ownerID: questionID, tagID: s.tagNamed(name).ID}] = true
+	}
+
+	return created
+}
@@ -0,0 +1,353 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+type QuizRepository struct {
+	s *Store
+}
+
+func (r *QuizRepository) GetByID(ctx context.Context, id string) (*models.Quiz, error) {
+	quizID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	quiz, ok := r.s.quizzes[quizID]
+	if !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	return &quiz, nil
+}
+
+func (r *QuizRepository) GetAll(ctx context.Context) ([]models.Quiz, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	quizzes := []models.Quiz{}
+	for _, quiz := range r.s.quizzes {
+		quizzes = append(quizzes, quiz)
+	}
+
+	sort.Slice(quizzes, func(i, j int) bool { return quizzes[i].ID < quizzes[j].ID })
+	return quizzes, nil
+}
+
+// List mirrors the Postgres keyset pagination. Filters compare lower-cased
+// strings, which is close enough to ILIKE for tests.
+func (r *QuizRepository) List(ctx context.Context, query models.QuizQuery) (*models.QuizPage, error) {
+	order, err := repository.ParseQuizSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := repository.ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *repository.QuizCursor
+	if query.Cursor != "" {
+		cursor, err := repository.DecodeQuizCursor(query.Cursor, order)
+		if err != nil {
+			return nil, err
+		}
+		after = &cursor
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	var matching []models.Quiz
+	for _, quiz := range r.s.quizzes {
+		if contains(quiz.Title, query.Title) && (query.Description == "" || quiz.Description != nil && contains(*quiz.Description, query.Description)) &&
+			r.s.matchesTags(r.s.quizTags, quiz.ID, filter) {
+			matching = append(matching, quiz)
+		}
+	}
+
+	// before reports whether a sorts ahead of b in the requested order
+	before := func(a, b repository.QuizCursor) bool {
+		less := a.ID < b.ID
+		if order.Field == "title" && a.Title != b.Title {
+			less = a.Title < b.Title
+		}
+		if order.Desc {
+			return !less && a != b
+		}
+		return less
+	}
+	keyOf := func(quiz models.Quiz) repository.QuizCursor {
+		return repository.NewQuizCursor(order, quiz)
+	}
+
+	page := &models.QuizPage{}
+	if query.IncludeTotal {
+		total := len(matching)
+		page.Total = &total
+	}
+
+	var quizzes []models.Quiz
+	for _, quiz := range matching {
+		if after == nil || before(*after, keyOf(quiz)) {
+			quizzes = append(quizzes, quiz)
+		}
+	}
+	sort.Slice(quizzes, func(i, j int) bool { return before(keyOf(quizzes[i]), keyOf(quizzes[j])) })
+
+	limit := repository.PageSize(query.Limit)
+	if len(quizzes) > limit+1 {
+		quizzes = quizzes[:limit+1]
+	}
+	page.Data, page.NextCursor = repository.TrimQuizPage(quizzes, limit, order)
+	return page, nil
+}
+
+func contains(value, substring string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substring))
+}
+
+func (r *QuizRepository) GetFull(ctx context.Context, id string) (*models.FullQuiz, error) {
+	quizID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	quiz, ok := r.s.quizzes[quizID]
+	if !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	questions := r.s.questionsOf(quizID)
+	full := &models.FullQuiz{
+		Quiz:      quiz,
+		Questions: make([]models.FullQuestion, 0, len(questions)),
+	}
+	for _, question := range questions {
+		full.Questions = append(full.Questions, r.s.fullQuestion(question))
+	}
+
+	return full, nil
+}
+
+func (r *QuizRepository) Create(ctx context.Context, input models.QuizInput) (int64, error) {
+	if input.Title == "" {
+		return 0, repository.Invalid("title", "is required")
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	id := r.s.nextID("quizzes")
+	r.s.quizzes[id] = models.Quiz{ID: id, Title: input.Title, Description: input.Description}
+
+	return int64(id), nil
+}
+
+func (r *QuizRepository) CreateFull(ctx context.Context, draft models.QuizDraft) (*models.CreatedQuiz, error) {
+	// Validate everything before writing anything, which gives the same
+	// all-or-nothing result as the Postgres transaction.
+	if draft.Title == "" {
+		return nil, repository.Invalid("title", "is required")
+	}
+	for _, question := range draft.Questions {
+		if err := validateQuestionDraft(question); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	quizID := r.s.nextID("quizzes")
+	r.s.quizzes[quizID] = models.Quiz{ID: quizID, Title: draft.Title, Description: draft.Description}
+
+	created := &models.CreatedQuiz{ID: int64(quizID), Questions: make([]models.CreatedQuestion, 0, len(draft.Questions))}
+	for i, question := range draft.Questions {
+		orderNum := question.Order
+		if orderNum == 0 {
+			orderNum = i + 1
+		}
+		created.Questions = append(created.Questions, r.s.insertQuestionDraft(&quizID, nil, question, orderNum))
+	}
+
+	return created, nil
+}
+
+func (r *QuizRepository) Replace(ctx context.Context, id string, input models.QuizInput) error {
+	if input.Title == "" {
+		return repository.Invalid("title", "is required")
+	}
+
+	quizID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[quizID]; !ok {
+		return repository.NotFound("quiz")
+	}
+	r.s.quizzes[quizID] = models.Quiz{ID: quizID, Title: input.Title, Description: input.Description}
+
+	return nil
+}
+
+func (r *QuizRepository) Patch(ctx context.Context, id string, patch models.QuizPatch) error {
+	if patch.Title.IsValue() && patch.Title.Value == "" {
+		return repository.Invalid("title", "must not be empty")
+	}
+
+	quizID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	quiz := r.s.quizzes[quizID]
+	titleSet, err := applyOptional(patch.Title, "title", false, func(v *string) { quiz.Title = *v })
+	if err != nil {
+		return err
+	}
+	descriptionSet, err := applyOptional(patch.Description, "description", true, func(v *string) { quiz.Description = v })
+	if err != nil {
+		return err
+	}
+	if !titleSet && !descriptionSet {
+		return repository.Invalid("", "no valid fields to update")
+	}
+
+	if _, ok := r.s.quizzes[quizID]; !ok {
+		return repository.NotFound("quiz")
+	}
+	r.s.quizzes[quizID] = quiz
+
+	return nil
+}
+
+func (r *QuizRepository) Delete(ctx context.Context, id string) error {
+	quizID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[quizID]; !ok {
+		return repository.NotFound("quiz")
+	}
+
+	r.s.deleteQuiz(quizID)
+	return nil
+}
+
+func (r *QuizRepository) GetDrawRules(ctx context.Context, quizID string) ([]models.DrawRule, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	return r.s.drawRulesOf(id), nil
+}
+
+func (r *QuizRepository) ReplaceDrawRules(ctx context.Context, quizID string, drafts []models.DrawRuleDraft) ([]models.DrawRule, error) {
+	if err := repository.CheckDrawRules(drafts); err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+	for _, draft := range drafts {
+		if _, ok := r.s.banks[draft.BankID]; !ok {
+			return nil, repository.InvalidReference("draw rule")
+		}
+	}
+
+	for _, rule := range r.s.drawRulesOf(id) {
+		delete(r.s.drawRules, rule.ID)
+	}
+
+	rules := make([]models.DrawRule, 0, len(drafts))
+	for i, draft := range drafts {
+		rule := models.DrawRule{
+			ID:       r.s.nextID("quiz_draw_rules"),
+			QuizID:   id,
+			BankID:   draft.BankID,
+			Position: i + 1,
+			Count:    draft.Count,
+			Tag:      draft.Tag,
+		}
+		r.s.drawRules[rule.ID] = rule
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// drawRulesOf returns a quiz's draw rules ordered by position.
+func (s *Store) drawRulesOf(quizID int) []models.DrawRule {
+	rules := []models.DrawRule{}
+	for _, rule := range s.drawRules {
+		if rule.QuizID == quizID {
+			rules = append(rules, rule)
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Position < rules[j].Position })
+	return rules
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/changangus/go-quiz-backend/internal/review"
+)
+
+// reviewKey identifies one learner's review card for one question.
+type reviewKey struct {
+	userID     string
+	questionID int
+}
+
+type ReviewRepository struct {
+	s *Store
+}
+
+func (r *ReviewRepository) GetDue(ctx context.Context, userID string, query models.ReviewQuery) ([]models.DueReview, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	scheduler := review.NewScheduler(r.s.clock)
+	var cards []models.ReviewCard
+	for key, card := range r.s.reviews {
+		if key.userID == userID && scheduler.Due(card) {
+			cards = append(cards, card)
+		}
+	}
+
+	sort.Slice(cards, func(i, j int) bool {
+		if !cards[i].DueAt.Equal(cards[j].DueAt) {
+			return cards[i].DueAt.Before(cards[j].DueAt)
+		}
+		return cards[i].QuestionID < cards[j].QuestionID
+	})
+	if limit := repository.PageSize(query.Limit); len(cards) > limit {
+		cards = cards[:limit]
+	}
+
+	questions := make([]models.FullQuestion, 0, len(cards))
+	for _, card := range cards {
+		questions = append(questions, r.s.fullQuestion(r.s.questions[card.QuestionID]))
+	}
+
+	return repository.DueReviews(cards, questions), nil
+}
+
+// recordReviews reviews every question of a submitted attempt for the
+// learner who made it, as the Postgres repository does on submit. The caller
+// must hold the lock.
+func (s *Store) recordReviews(userID string, outcomes map[int]analytics.Outcome) {
+	if userID == "" {
+		return
+	}
+
+	scheduler := review.NewScheduler(s.clock)
+	for questionID, outcome := range outcomes {
+		key := reviewKey{userID: userID, questionID: questionID}
+		card, ok := s.reviews[key]
+		if !ok {
+			card = models.ReviewCard{UserID: userID, QuestionID: questionID}
+		}
+		s.reviews[key] = scheduler.Review(card, review.Quality(outcome.Answered, outcome.Points))
+	}
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+// SearchRepository is a naive stand-in for Postgres full-text search. A
+// document matches when it contains every word of the query, ignoring case;
+// there is no stemming or query syntax. Rank is the number of occurrences.
+type SearchRepository struct {
+	s *Store
+}
+
+func (r *SearchRepository) Search(ctx context.Context, query models.SearchQuery) ([]models.SearchHit, error) {
+	terms := strings.Fields(strings.ToLower(query.Q))
+	if len(terms) == 0 {
+		return nil, repository.Invalid("q", "is required")
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	hits := []models.SearchHit{}
+	add := func(kind string, id int, quizID *int, bankID *int, questionID *int, body string) {
+		rank := matchRank(body, terms)
+		if rank == 0 {
+			return
+		}
+		var quizTitle *string
+		if quizID != nil {
+			title := r.s.quizzes[*quizID].Title
+			quizTitle = &title
+		}
+		hits = append(hits, models.SearchHit{
+			Kind:       kind,
+			ID:         id,
+			QuizID:     quizID,
+			QuizTitle:  quizTitle,
+			BankID:     bankID,
+			QuestionID: questionID,
+			Snippet:    highlight(body, terms),
+			Rank:       float64(rank),
+		})
+	}
+
+	for _, quiz := range r.s.quizzes {
+		body := quiz.Title
+		if quiz.Description != nil {
+			body += " " + *quiz.Description
+		}
+		quizID := quiz.ID
+		add(models.SearchHitQuiz, quiz.ID, &quizID, nil, nil, body)
+	}
+	for _, question := range r.s.questions {
+		questionID := question.ID
+		add(models.SearchHitQuestion, question.ID, question.QuizID, question.BankID, &questionID, question.Question)
+	}
+	for _, answer := range r.s.answers {
+		question := r.s.questions[answer.QuestionID]
+		questionID := question.ID
+		add(models.SearchHitAnswer, answer.ID, question.QuizID, question.BankID, &questionID, answer.Answer)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		if hits[i].Kind != hits[j].Kind {
+			return hits[i].Kind < hits[j].Kind
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	if limit := repository.PageSize(query.Limit); len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// matchRank counts occurrences of terms in body, or returns 0 unless every
+// term occurs.
+func matchRank(body string, terms []string) int {
+	body = strings.ToLower(body)
+	rank := 0
+	for _, term := range terms {
+		n := strings.Count(body, term)
+		if n == 0 {
+			return 0
+		}
+		rank += n
+	}
+	return rank
+}
+
+// highlight escapes body as HTML and wraps each occurrence of terms in
+// <mark> tags. Matching the raw text keeps terms from matching inside the
+// escapes.
+func highlight(body string, terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(body, -1) {
+		b.WriteString(html.EscapeString(body[last:match[0]]))
+		b.WriteString("<mark>" + html.EscapeString(body[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(body[last:]))
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+// statKey identifies the totals of one question or answer within one quiz.
+type statKey struct {
+	quizID int
+	id     int
+}
+
+type StatsRepository struct {
+	s *Store
+}
+
+func (r *StatsRepository) GetQuestionStats(ctx context.Context, questionID string) (*models.ItemStats, error) {
+	id, err := parseID(questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	question, ok := r.s.questions[id]
+	if !ok {
+		return nil, repository.NotFound("question")
+	}
+
+	var tally analytics.Tally
+	for key, t := range r.s.itemStats {
+		if key.id == id {
+			tally = tally.Add(t)
+		}
+	}
+
+	stats := r.s.itemStatsOf(question, tally, 0)
+	return &stats, nil
+}
+
+func (r *StatsRepository) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	id, err := parseID(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.quizzes[id]; !ok {
+		return nil, repository.NotFound("quiz")
+	}
+
+	stats := &models.QuizStats{QuizID: id, Questions: []models.ItemStats{}}
+	r.s.quizStats[id].Describe(stats)
+
+	var drawn []models.Question
+	for key := range r.s.itemStats {
+		if question := r.s.questions[key.id]; key.quizID == id && !inQuiz(question, id) {
+			drawn = append(drawn, question)
+		}
+	}
+	sort.Slice(drawn, func(i, j int) bool { return drawn[i].ID < drawn[j].ID })
+
+	for _, question := range append(r.s.questionsOf(id), drawn...) {
+		key := statKey{quizID: id, id: question.ID}
+		stats.Questions = append(stats.Questions, r.s.itemStatsOf(question, r.s.itemStats[key], id))
+	}
+
+	return stats, nil
+}
+
+// itemStatsOf describes a question from its tally and the selections of its
+// answers, counted only in quizID unless it is zero. The caller must hold
+// the lock.
+func (s *Store) itemStatsOf(question models.Question, tally analytics.Tally, quizID int) models.ItemStats {
+	stats := models.ItemStats{
+		QuestionID: question.ID,
+		Question:   question.Question,
+		Type:       question.Type,
+		Answers:    []models.AnswerStats{},
+	}
+	for _, answer := range s.answersOf(question.ID) {
+		selections := 0
+		for key, n := range s.selections {
+			if key.id == answer.ID && (quizID == 0 || key.quizID == quizID) {
+				selections += n
+			}
+		}
+		stats.Answers = append(stats.Answers, models.AnswerStats{
+			AnswerID:   answer.ID,
+			Answer:     answer.Answer,
+			Correct:    answer.Correct,
+			Selections: selections,
+		})
+	}
+
+	tally.Describe(&stats)
+	return stats
+}
+
+// recordStats adds a submitted attempt to the item analysis totals, as the
+// Postgres repository does on submit. The caller must hold the lock.
+func (s *Store) recordStats(quizID int, quiz analytics.QuizTally, outcomes map[int]analytics.Outcome, selected []int64) {
+	s.quizStats[quizID] = s.quizStats[quizID].Add(quiz)
+	for questionID, outcome := range outcomes {
+		key := statKey{quizID: quizID, id: questionID}
+		s.itemStats[key] = s.itemStats[key].Add(analytics.Observe(outcome))
+	}
+	for _, answerID := range selected {
+		s.selections[statKey{quizID: quizID, id: int(answerID)}]++
+	}
+}
+
+// forgetSelections removes the selection counts of a deleted answer.
+func (s *Store) forgetSelections(answerID int) {
+	for key := range s.selections {
+		if key.id == answerID {
+			delete(s.selections, key)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+// Package memory is an in-memory implementation of the repository stores. It
+// keeps the same semantics as the Postgres repositories, including cascading
+// deletes and ordering by order_num, so handlers can be tested without a
+// database.
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/changangus/go-quiz-backend/internal/review"
+)
+
+// Store holds every table in memory behind a single lock.
+type Store struct {
+	mu sync.Mutex
+	// clock is what the store reads the time from
+	clock review.Clock
+
+	lastID map[string]int
+
+	quizzes    map[int]models.Quiz
+	banks      map[int]models.Bank
+	drawRules  map[int]models.DrawRule
+	questions  map[int]models.Question
+	answers    map[int]models.Answer
+	hints      map[int]models.Hint
+	tags       map[int]models.Tag
+	objectives map[int]models.Objective
+	attempts   map[int]models.Attempt
+	responses  map[int]models.AttemptResponse
+	// revealed counts the tiers of hints revealed to a question in an
+	// attempt
+	revealed map[hintUse]int
+	// attemptQuestions holds each attempt's questions in the order it was
+	// given them
+	attemptQuestions map[int][]int
+	quizTags         map[tagLink]bool
+	questionTags     map[tagLink]bool
+	// assesses links questions to the objectives they assess
+	assesses map[objectiveLink]bool
+	// quizStats, itemStats and selections are the item analysis totals,
+	// added to as attempts are submitted
+	quizStats  map[int]analytics.QuizTally
+	itemStats  map[statKey]analytics.Tally
+	selections map[statKey]int
+	// adaptive holds the settings of adaptive quizzes, and calibrations the
+	// fitted parameters of questions, keyed by question ID
+	adaptive     map[int]models.AdaptiveSettings
+	calibrations map[int]models.Calibration
+	// reviews holds each learner's review schedule for the questions they
+	// have been given
+	reviews map[reviewKey]models.ReviewCard
+}
+
+// hintUse identifies the hints of one question within one attempt.
+type hintUse struct {
+	attemptID  int
+	questionID int
+}
+
+func NewStore() *Store {
+	return &Store{
+		clock:      review.SystemClock,
+		lastID:     make(map[string]int),
+		quizzes:    make(map[int]models.Quiz),
+		banks:      make(map[int]models.Bank),
+		drawRules:  make(map[int]models.DrawRule),
+		questions:  make(map[int]models.Question),
+		answers:    make(map[int]models.Answer),
+		hints:      make(map[int]models.Hint),
+		tags:       make(map[int]models.Tag),
+		objectives: make(map[int]models.Objective),
+		attempts:   make(map[int]models.Attempt),
+		responses:  make(map[int]models.AttemptResponse),
+		revealed:   make(map[hintUse]int),
+
+		attemptQuestions: make(map[int][]int),
+		quizTags:         make(map[tagLink]bool),
+		questionTags:     make(map[tagLink]bool),
+		assesses:         make(map[objectiveLink]bool),
+		quizStats:        make(map[int]analytics.QuizTally),
+		itemStats:        make(map[statKey]analytics.Tally),
+		selections:       make(map[statKey]int),
+		adaptive:         make(map[int]models.AdaptiveSettings),
+		calibrations:     make(map[int]models.Calibration),
+		reviews:          make(map[reviewKey]models.ReviewCard),
+	}
+}
+
+// UseClock makes the store read the time from clock rather than the system
+// clock, so tests can control when reviews fall due.
+func (s *Store) UseClock(clock review.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// NewRepositories returns stores backed by a fresh, empty Store.
+func NewRepositories() repository.Repositories {
+	return NewStore().Repositories()
+}
+
+// Repositories returns stores that all share s.
+func (s *Store) Repositories() repository.Repositories {
+	return repository.Repositories{
+		Quizzes:    &QuizRepository{s},
+		Banks:      &BankRepository{s},
+		Tags:       &TagRepository{s},
+		Objectives: &ObjectiveRepository{s},
+		Questions:  &QuestionRepository{s},
+		Answers:    &AnswerRepository{s},
+		Attempts:   &AttemptRepository{s},
+		Adaptive:   &AdaptiveRepository{s},
+		Stats:      &StatsRepository{s},
+		Reviews:    &ReviewRepository{s},
+		Search:     &SearchRepository{s},
+	}
+}
+
+// lock takes the store lock unless ctx is already done, so a cancelled
+// request fails the same way it would against Postgres.
+func (s *Store) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	return nil
+}
+
+// nextID mimics a SERIAL column.
+func (s *Store) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// parseID converts a path ID. Postgres rejects IDs that are not integers, so
+// this does too.
+func parseID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, repository.Invalid("id", "must be an integer")
+	}
+	return n, nil
+}
+
+// inQuiz reports whether a question belongs to a quiz rather than a bank or
+// another quiz.
+func inQuiz(question models.Question, quizID int) bool {
+	return question.QuizID != nil && *question.QuizID == quizID
+}
+
+// questionsOf returns a quiz's questions ordered by order_num, then id.
+func (s *Store) questionsOf(quizID int) []models.Question {
+	var questions []models.Question
+	for _, question := range s.questions {
+		if inQuiz(question, quizID) {
+			questions = append(questions, question)
+		}
+	}
+
+	sort.Slice(questions, func(i, j int) bool {
+		if questions[i].Order != questions[j].Order {
+			return questions[i].Order < questions[j].Order
+		}
+		return questions[i].ID < questions[j].ID
+	})
+	return questions
+}
+
+// bankQuestionsOf returns a bank's questions ordered by id.
+func (s *Store) bankQuestionsOf(bankID int) []models.Question {
+	var questions []models.Question
+	for _, question := range s.questions {
+		if question.BankID != nil && *question.BankID == bankID {
+			questions = append(questions, question)
+		}
+	}
+
+	sort.Slice(questions, func(i, j int) bool { return questions[i].ID < questions[j].ID })
+	return questions
+}
+
+// fullQuestion returns a question with its answers, hints and tags.
+func (s *Store) fullQuestion(question models.Question) models.FullQuestion {
+	answers := s.answersOf(question.ID)
+	if answers == nil {
+		answers = []models.Answer{}
+	}
+	tags := []string{}
+	for _, tag := range s.tagsOn(s.questionTags, question.ID) {
+		tags = append(tags, tag.Name)
+	}
+	return models.FullQuestion{
+		Question: question,
+		Answers:  answers,
+		Hints:    s.hintsOf(question.ID),
+		Tags:     tags,
+	}
+}
+
+// answersOf returns a question's answers ordered by id.
+func (s *Store) answersOf(questionID int) []models.Answer {
+	var answers []models.Answer
+	for _, answer := range s.answers {
+		if answer.QuestionID == questionID {
+			answers = append(answers, answer)
+		}
+	}
+
+	sort.Slice(answers, func(i, j int) bool { return answers[i].ID < answers[j].ID })
+	return answers
+}
+
+// hintsOf returns a question's hints ordered by tier.
+func (s *Store) hintsOf(questionID int) []models.Hint {
+	hints := []models.Hint{}
+	for _, hint := range s.hints {
+		if hint.QuestionID == questionID {
+			hints = append(hints, hint)
+		}
+	}
+
+	sort.Slice(hints, func(i, j int) bool { return hints[i].Tier < hints[j].Tier })
+	return hints
+}
+
+// insertHints stores hints for a question that has none, numbering their
+// tiers from 1. The caller must hold the lock.
+func (s *Store) insertHints(questionID int, drafts []models.HintDraft) []models.Hint {
+	hints := make([]models.Hint, 0, len(drafts))
+	for i, draft := range drafts {
+		hint := models.Hint{
+			ID:         s.nextID("question_hints"),
+			QuestionID: questionID,
+			Tier:       i + 1,
+			Hint:       draft.Hint,
+			Penalty:    draft.Penalty,
+		}
+		s.hints[hint.ID] = hint
+		hints = append(hints, hint)
+	}
+	return hints
+}
+
+// deleteQuiz removes a quiz and everything that cascades from it.
+func (s *Store) deleteQuiz(id int) {
+	delete(s.quizzes, id)
+	untagAll(s.quizTags, id)
+	for questionID, question := range s.questions {
+		if inQuiz(question, id) {
+			s.deleteQuestion(questionID)
+		}
+	}
+	for ruleID, rule := range s.drawRules {
+		if rule.QuizID == id {
+			delete(s.drawRules, ruleID)
+		}
+	}
+	for attemptID, attempt := range s.attempts {
+		if attempt.QuizID == id {
+			s.deleteAttempt(attemptID)
+		}
+	}
+	delete(s.adaptive, id)
+	delete(s.quizStats, id)
+	for key := range s.itemStats {
+		if key.quizID == id {
+			delete(s.itemStats, key)
+		}
+	}
+	for key := range s.selections {
+		if key.quizID == id {
+			delete(s.selections, key)
+		}
+	}
+}
+
+// deleteBank removes a bank, its questions and the draw rules that pick from
+// it.
+func (s *Store) deleteBank(id int) {
+	delete(s.banks, id)
+	for questionID, question := range s.questions {
+		if question.BankID != nil && *question.BankID == id {
+			s.deleteQuestion(questionID)
+		}
+	}
+	for ruleID, rule := range s.drawRules {
+		if rule.BankID == id {
+			delete(s.drawRules, ruleID)
+		}
+	}
+}
+
+// deleteQuestion removes a question, its answers, hints and tags, its
+// statistics, calibration, review schedules and responses to it. Attempts
+// that were given it lose it too.
+func (s *Store) deleteQuestion(id int) {
+	delete(s.questions, id)
+	delete(s.calibrations, id)
+	for key := range s.reviews {
+		if key.questionID == id {
+			delete(s.reviews, key)
+		}
+	}
+	untagAll(s.questionTags, id)
+	for link := range s.assesses {
+		if link.questionID == id {
+			delete(s.assesses, link)
+		}
+	}
+	for attemptID, questions := range s.attemptQuestions {
+		kept := questions[:0:0]
+		for _, questionID := range questions {
+			if questionID != id {
+				kept = append(kept, questionID)
+			}
+		}
+		s.attemptQuestions[attemptID] = kept
+	}
+	for answerID, answer := range s.answers {
+		if answer.QuestionID == id {
+			delete(s.answers, answerID)
+			s.forgetSelections(answerID)
+		}
+	}
+	for key := range s.itemStats {
+		if key.id == id {
+			delete(s.itemStats, key)
+		}
+	}
+	for hintID, hint := range s.hints {
+		if hint.QuestionID == id {
+			delete(s.hints, hintID)
+		}
+	}
+	for use := range s.revealed {
+		if use.questionID == id {
+			delete(s.revealed, use)
+		}
+	}
+	for responseID, response := range s.responses {
+		if response.QuestionID == id {
+			delete(s.responses, responseID)
+		}
+	}
+}
+
+func (s *Store) deleteAttempt(id int) {
+	delete(s.attempts, id)
+	delete(s.attemptQuestions, id)
+	for use := range s.revealed {
+		if use.attemptID == id {
+			delete(s.revealed, use)
+		}
+	}
+	for responseID, response := range s.responses {
+		if response.AttemptID == id {
+			delete(s.responses, responseID)
+		}
+	}
+}
+
+// applyOptional mirrors the Postgres repositories' handling of a patch field:
+// absent leaves the value alone, null is only allowed when nullable.
+func applyOptional[T any](field models.Optional[T], column string, nullable bool, set func(value *T)) (bool, error) {
+	if !field.Set {
+		return false, nil
+	}
+
+	if field.Null {
+		if !nullable {
+			return false, repository.Invalid(column, "cannot be null")
+		}
+		set(nil)
+		return true, nil
+	}
+
+	value := field.Value
+	set(&value)
+	return true, nil
+}
+
+var (
+	_ repository.QuizStore      = (*QuizRepository)(nil)
+	_ repository.BankStore      = (*BankRepository)(nil)
+	_ repository.TagStore       = (*TagRepository)(nil)
+	_ repository.ObjectiveStore = (*ObjectiveRepository)(nil)
+	_ repository.QuestionStore  = (*QuestionRepository)(nil)
+	_ repository.AnswerStore    = (*AnswerRepository)(nil)
+	_ repository.AttemptStore   = (*AttemptRepository)(nil)
+	_ repository.AdaptiveStore  = (*AdaptiveRepository)(nil)
+	_ repository.StatsStore     = (*StatsRepository)(nil)
+	_ repository.ReviewStore    = (*ReviewRepository)(nil)
+	_ repository.SearchStore    = (*SearchRepository)(nil)
+)
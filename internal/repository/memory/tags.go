@@ -0,0 +1,351 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+)
+
+// tagLink tags one quiz or question, depending on the map it is in.
+type tagLink struct {
+	ownerID int
+	tagID   int
+}
+
+type TagRepository struct {
+	s *Store
+}
+
+func (r *TagRepository) GetAll(ctx context.Context) ([]models.TagUsage, error) {
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	tags := []models.TagUsage{}
+	for _, tag := range r.s.tags {
+		usage := models.TagUsage{Tag: tag}
+		for link := range r.s.quizTags {
+			if link.tagID == tag.ID {
+				usage.Quizzes++
+			}
+		}
+		for link := range r.s.questionTags {
+			if link.tagID == tag.ID {
+				usage.Questions++
+			}
+		}
+		tags = append(tags, usage)
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id string) (*models.Tag, error) {
+	tagID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	tag, ok := r.s.tags[tagID]
+	if !ok {
+		return nil, repository.NotFound("tag")
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepository) Create(ctx context.Context, input models.TagInput) (int64, error) {
+	name, err := repository.CheckTagName("name", input.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.tagByName(name); ok {
+		return 0, repository.Conflict("tag already exists")
+	}
+	if input.ParentID != nil {
+		if _, ok := r.s.tags[*input.ParentID]; !ok {
+			return 0, repository.InvalidReference("tag")
+		}
+	}
+
+	tag := r.s.tagNamed(name)
+	tag.ParentID = input.ParentID
+	r.s.tags[tag.ID] = tag
+
+	return int64(tag.ID), nil
+}
+
+func (r *TagRepository) Replace(ctx context.Context, id string, input models.TagInput) error {
+	name, err := repository.CheckTagName("name", input.Name)
+	if err != nil {
+		return err
+	}
+
+	tagID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	tag, ok := r.s.tags[tagID]
+	if !ok {
+		return repository.NotFound("tag")
+	}
+	if input.ParentID != nil && r.s.tagTree(tagID)[*input.ParentID] {
+		return repository.Invalid("parent_id", "must not be the tag itself or a tag beneath it")
+	}
+	if input.ParentID != nil {
+		if _, ok := r.s.tags[*input.ParentID]; !ok {
+			return repository.InvalidReference("tag")
+		}
+	}
+	if other, ok := r.s.tagByName(name); ok && other.ID != tagID {
+		return repository.Conflict("tag already exists")
+	}
+
+	tag.Name = name
+	tag.ParentID = input.ParentID
+	r.s.tags[tagID] = tag
+
+	return nil
+}
+
+func (r *TagRepository) Delete(ctx context.Context, id string) error {
+	tagID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.tags[tagID]; !ok {
+		return repository.NotFound("tag")
+	}
+
+	delete(r.s.tags, tagID)
+	for childID, child := range r.s.tags {
+		if child.ParentID != nil && *child.ParentID == tagID {
+			child.ParentID = nil
+			r.s.tags[childID] = child
+		}
+	}
+	for _, links := range []map[tagLink]bool{r.s.quizTags, r.s.questionTags} {
+		for link := range links {
+			if link.tagID == tagID {
+				delete(links, link)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *TagRepository) GetQuizTags(ctx context.Context, quizID string) ([]models.Tag, error) {
+	return r.getAttached(ctx, r.s.quizTags, r.quizExists, quizID)
+}
+
+func (r *TagRepository) TagQuiz(ctx context.Context, quizID string, name string) (*models.Tag, error) {
+	return r.attach(ctx, r.s.quizTags, r.quizExists, quizID, name)
+}
+
+func (r *TagRepository) UntagQuiz(ctx context.Context, quizID string, tagID string) error {
+	return r.detach(ctx, r.s.quizTags, r.quizExists, quizID, tagID)
+}
+
+func (r *TagRepository) GetQuestionTags(ctx context.Context, questionID string) ([]models.Tag, error) {
+	return r.getAttached(ctx, r.s.questionTags, r.questionExists, questionID)
+}
+
+func (r *TagRepository) TagQuestion(ctx context.Context, questionID string, name string) (*models.Tag, error) {
+	return r.attach(ctx, r.s.questionTags, r.questionExists, questionID, name)
+}
+
+func (r *TagRepository) UntagQuestion(ctx context.Context, questionID string, tagID string) error {
+	return r.detach(ctx, r.s.questionTags, r.questionExists, questionID, tagID)
+}
+
+// ownerCheck reports a quiz or question that does not exist. The caller must
+// hold the lock.
+type ownerCheck func(id int) error
+
+func (r *TagRepository) quizExists(id int) error {
+	if _, ok := r.s.quizzes[id]; !ok {
+		return repository.NotFound("quiz")
+	}
+	return nil
+}
+
+func (r *TagRepository) questionExists(id int) error {
+	if _, ok := r.s.questions[id]; !ok {
+		return repository.NotFound("question")
+	}
+	return nil
+}
+
+func (r *TagRepository) getAttached(ctx context.Context, links map[tagLink]bool, exists ownerCheck, id string) ([]models.Tag, error) {
+	ownerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if err := exists(ownerID); err != nil {
+		return nil, err
+	}
+
+	return r.s.tagsOn(links, ownerID), nil
+}
+
+func (r *TagRepository) attach(ctx context.Context, links map[tagLink]bool, exists ownerCheck, id string, name string) (*models.Tag, error) {
+	name, err := repository.CheckTagName("tag", name)
+	if err != nil {
+		return nil, err
+	}
+	ownerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.s.mu.Unlock()
+
+	if err := exists(ownerID); err != nil {
+		return nil, err
+	}
+
+	tag := r.s.tagNamed(name)
+	links[tagLink{ownerID: ownerID, tagID: tag.ID}] = true
+	return &tag, nil
+}
+
+func (r *TagRepository) detach(ctx context.Context, links map[tagLink]bool, exists ownerCheck, id string, tagID string) error {
+	ownerID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	tag, err := parseID(tagID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.s.lock(ctx); err != nil {
+		return err
+	}
+	defer r.s.mu.Unlock()
+
+	if err := exists(ownerID); err != nil {
+		return err
+	}
+
+	link := tagLink{ownerID: ownerID, tagID: tag}
+	if !links[link] {
+		return repository.NotFound("tag")
+	}
+	delete(links, link)
+
+	return nil
+}
+
+func (s *Store) tagByName(name string) (models.Tag, bool) {
+	for _, tag := range s.tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+	return models.Tag{}, false
+}
+
+// tagNamed returns the tag with a name, creating it if there is none, as the
+// Postgres upsert does. The caller must hold the lock.
+func (s *Store) tagNamed(name string) models.Tag {
+	if tag, ok := s.tagByName(name); ok {
+		return tag
+	}
+
+	tag := models.Tag{ID: s.nextID("tags"), Name: name}
+	s.tags[tag.ID] = tag
+	return tag
+}
+
+// tagsOn returns the tags linked to a quiz or question, ordered by name.
+func (s *Store) tagsOn(links map[tagLink]bool, ownerID int) []models.Tag {
+	tags := []models.Tag{}
+	for link := range links {
+		if link.ownerID == ownerID {
+			tags = append(tags, s.tags[link.tagID])
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// tagTree returns the IDs of a tag and every tag beneath it.
+func (s *Store) tagTree(tagID int) map[int]bool {
+	tree := map[int]bool{tagID: true}
+	for grew := true; grew; {
+		grew = false
+		for _, tag := range s.tags {
+			if !tree[tag.ID] && tag.ParentID != nil && tree[*tag.ParentID] {
+				tree[tag.ID] = true
+				grew = true
+			}
+		}
+	}
+	return tree
+}
+
+// matchesTags reports whether the quiz or question ownerID, linked to its
+// tags through links, matches filter.
+func (s *Store) matchesTags(links map[tagLink]bool, ownerID int, filter repository.TagFilter) bool {
+	return filter.Matches(func(name string) bool {
+		tag, ok := s.tagByName(name)
+		if !ok {
+			return false
+		}
+		tree := s.tagTree(tag.ID)
+		for link := range links {
+			if link.ownerID == ownerID && tree[link.tagID] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// untagAll removes every tag from a quiz or question.
+func untagAll(links map[tagLink]bool, ownerID int) {
+	for link := range links {
+		if link.ownerID == ownerID {
+			delete(links, link)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const objectiveColumns = "o.id, o.framework, o.code, o.title, o.description"
+
+type ObjectiveRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewObjectiveRepository(db *sqlx.DB, opts ...Option) *ObjectiveRepository {
+	return &ObjectiveRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// GetAll returns the objectives of a framework, or of every framework when
+// it is empty, ordered by code.
+func (r *ObjectiveRepository) GetAll(ctx context.Context, framework string) ([]models.Objective, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectives := []models.Objective{}
+	err := r.db.SelectContext(ctx, &objectives,
+		"SELECT "+objectiveColumns+" FROM objectives o WHERE $1 = '' OR o.framework = $1",
+		framework)
+	if err != nil {
+		return nil, err
+	}
+
+	sortObjectives(objectives)
+	return objectives, nil
+}
+
+func (r *ObjectiveRepository) GetByID(ctx context.Context, id string) (*models.Objective, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objective := &models.Objective{}
+	err := r.db.GetContext(ctx, objective, "SELECT "+objectiveColumns+" FROM objectives o WHERE o.id = $1", id)
+	if err != nil {
+		return nil, dbError(err, "objective")
+	}
+
+	return objective, nil
+}
+
+func (r *ObjectiveRepository) Create(ctx context.Context, input models.ObjectiveInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := CheckObjective(input); err != nil {
+		return 0, err
+	}
+
+	var objectiveID int64
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO objectives (framework, code, title, description) VALUES ($1, $2, $3, $4) RETURNING id",
+		input.Framework, input.Code, input.Title, input.Description,
+	).Scan(&objectiveID)
+	if err != nil {
+		return 0, dbError(err, "objective")
+	}
+
+	return objectiveID, nil
+}
+
+// Replace overwrites every column of an objective, as PUT requires. A
+// missing description is stored as null.
+func (r *ObjectiveRepository) Replace(ctx context.Context, id string, input models.ObjectiveInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := CheckObjective(input); err != nil {
+		return err
+	}
+
+	query, params, err := newUpdateBuilder("objectives").
+		Set("framework", input.Framework).
+		Set("code", input.Code).
+		Set("title", input.Title).
+		Set("description", input.Description).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, params...)
+	return requireRow(result, err, "objective")
+}
+
+func (r *ObjectiveRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM objectives WHERE id = $1", id)
+	return requireRow(result, err, "objective")
+}
+
+// GetQuestionObjectives returns the objectives a question assesses.
+func (r *ObjectiveRepository) GetQuestionObjectives(ctx context.Context, questionID string) ([]models.Objective, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM questions WHERE id = $1)", questionID).Scan(&exists)
+	if err != nil {
+		return nil, dbError(err, "question")
+	}
+	if !exists {
+		return nil, NotFound("question")
+	}
+
+	objectives := []models.Objective{}
+	err = r.db.SelectContext(ctx, &objectives,
+		"SELECT "+objectiveColumns+" FROM objectives o JOIN question_objectives qo ON qo.objective_id = o.id WHERE qo.question_id = $1",
+		questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sortObjectives(objectives)
+	return objectives, nil
+}
+
+// ReplaceQuestionObjectives sets the objectives a question assesses. An
+// empty list unlinks them all.
+func (r *ObjectiveRepository) ReplaceQuestionObjectives(ctx context.Context, questionID string, objectiveIDs []int) ([]models.Objective, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM questions WHERE id = $1 FOR UPDATE", questionID).Scan(&id)
+	if err != nil {
+		return nil, dbError(err, "question")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM question_objectives WHERE question_id = $1", id); err != nil {
+		return nil, err
+	}
+	for _, objectiveID := range uniqueInts(objectiveIDs) {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO question_objectives (question_id, objective_id) VALUES ($1, $2)",
+			id, objectiveID)
+		if err != nil {
+			return nil, dbError(err, "question objective")
+		}
+	}
+
+	objectives := []models.Objective{}
+	err = tx.SelectContext(ctx, &objectives,
+		"SELECT "+objectiveColumns+" FROM objectives o JOIN question_objectives qo ON qo.objective_id = o.id WHERE qo.question_id = $1",
+		id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	sortObjectives(objectives)
+	return objectives, nil
+}
+
+// Mastery reports how well a user has done on each objective their
+// submitted attempts assessed. Objectives they have not met are left out.
+func (r *ObjectiveRepository) Mastery(ctx context.Context, userID string, query models.MasteryQuery) ([]models.Mastery, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	where := []string{"a.user_id = $1", "a.status = $2"}
+	params := []interface{}{userID, models.AttemptSubmitted}
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+	if query.Framework != "" {
+		where = append(where, "o.framework = "+param(query.Framework))
+	}
+	if query.QuizID != 0 {
+		where = append(where, "a.quiz_id = "+param(query.QuizID))
+	}
+
+	report := []models.Mastery{}
+	err := r.db.SelectContext(ctx, &report,
+		`SELECT `+objectiveColumns+`,
+			COUNT(DISTINCT aq.question_id) AS questions,
+			COALESCE(SUM(r.points), 0) AS points,
+			COUNT(*) AS max_points
+		FROM attempts a
+		JOIN attempt_questions aq ON aq.attempt_id = a.id
+		JOIN question_objectives qo ON qo.question_id = aq.question_id
+		JOIN objectives o ON o.id = qo.objective_id
+		LEFT JOIN attempt_responses r ON r.attempt_id = a.id AND r.question_id = aq.question_id`+
+			whereClause(where)+`
+		GROUP BY o.id`,
+		params...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report {
+		report[i].Rate()
+	}
+	sort.Slice(report, func(i, j int) bool { return models.CompareObjectives(report[i].Objective, report[j].Objective) < 0 })
+	return report, nil
+}
+
+// CheckObjective checks the fields binding checks for API requests, for the
+// other callers.
+func CheckObjective(input models.ObjectiveInput) error {
+	if input.Framework == "" {
+		return Invalid("framework", "is required")
+	}
+	if input.Code == "" {
+		return Invalid("code", "is required")
+	}
+	if input.Title == "" {
+		return Invalid("title", "is required")
+	}
+	return nil
+}
+
+func sortObjectives(objectives []models.Objective) {
+	sort.Slice(objectives, func(i, j int) bool { return models.CompareObjectives(objectives[i], objectives[j]) < 0 })
+}
+
+// uniqueInts returns ids with duplicates removed, preserving the first
+// occurrence of each.
+func uniqueInts(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
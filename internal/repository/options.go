@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/review"
+)
+
+// Option configures a repository.
+type Option func(*options)
+
+type options struct {
+	queryTimeout time.Duration
+	clock        review.Clock
+}
+
+// WithQueryTimeout bounds every repository call, including all the queries a
+// transaction runs, by d. The caller's context still applies; whichever ends
+// first cancels the work. A zero or negative d disables the timeout.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.queryTimeout = d
+	}
+}
+
+// WithClock makes repositories schedule reviews by clock rather than the
+// system clock, so tests can control when questions fall due. Times Postgres
+// records itself, such as when an attempt was submitted, are unaffected.
+func WithClock(clock review.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: review.SystemClock}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
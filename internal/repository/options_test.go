@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// slowQuery is how long sqlmock holds a query before answering. Tests expect
+// the repository to give up well before then.
+const slowQuery = 2 * time.Second
+
+func TestQueryTimeoutAbortsSlowQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM quizzes WHERE id = $1")).
+		WithArgs("1").
+		WillDelayFor(slowQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Slow", nil))
+
+	repo := NewQuizRepository(db, WithQueryTimeout(20*time.Millisecond))
+	start := time.Now()
+	_, err := repo.GetByID(context.Background(), "1")
+	if !errors.Is(err, sqlmock.ErrCancelled) {
+		t.Fatalf("expected the query to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= slowQuery {
+		t.Errorf("GetByID returned after %v, want it to stop at the timeout", elapsed)
+	}
+}
+
+func TestCancelledContextRollsBackTransaction(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO quizzes")).
+		WillDelayFor(slowQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectRollback()
+
+	// The request goes away while the first insert is still running
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := NewQuizRepository(db).CreateFull(ctx, models.QuizDraft{Title: "Abandoned"})
+	if err == nil {
+		t.Fatal("expected CreateFull to fail once the context was cancelled")
+	}
+
+	// database/sql rolls a cancelled transaction back from its own goroutine,
+	// so give it a moment to reach the driver.
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := mock.ExpectationsWereMet()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("transaction was not rolled back: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// fieldState is how a field appears in a merge patch body.
+type fieldState int
+
+const (
+	absent fieldState = iota
+	value
+	null
+)
+
+func (s fieldState) String() string {
+	return [...]string{"absent", "value", "null"}[s]
+}
+
+// patchField describes one column a patch can touch. apply sets the field on
+// the patch being built according to the state.
+type patchField struct {
+	column   string
+	nullable bool
+	value    interface{}
+	apply    func(state fieldState)
+}
+
+// ruleCheck describes the transaction a patch runs when it touches a column
+// that the question type rules depend on.
+type ruleCheck struct {
+	lockQuery string
+	columns   []string
+}
+
+// expects reports whether the check runs for a combination of states.
+func (c *ruleCheck) expects(fields []patchField, states []fieldState) bool {
+	if c == nil {
+		return false
+	}
+	for i, field := range fields {
+		for _, column := range c.columns {
+			if field.column == column && states[i] != absent {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runPatchCombinations exercises every absent/value/null combination of
+// fields against the SQL the repository runs. reset clears the patch before
+// each combination and patch calls the repository. check is nil for tables
+// the question type rules do not cover.
+func runPatchCombinations(t *testing.T, table string, fields []patchField, check *ruleCheck, reset func(), patch func(db *sqlx.DB) error) {
+	t.Helper()
+
+	total := 1
+	for range fields {
+		total *= 3
+	}
+
+	for combination := 0; combination < total; combination++ {
+		reset()
+		states := make([]fieldState, len(fields))
+		var names []string
+		rest := combination
+		for i, field := range fields {
+			states[i] = fieldState(rest % 3)
+			rest /= 3
+			field.apply(states[i])
+			names = append(names, field.column+"="+states[i].String())
+		}
+
+		t.Run(strings.Join(names, ","), func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer mockDB.Close()
+
+			var sets []string
+			var args []driver.Value
+			wantErr := false
+			for i, field := range fields {
+				switch states[i] {
+				case value:
+					sets = append(sets, fmt.Sprintf("%s = $%d", field.column, len(sets)+1))
+					args = append(args, field.value)
+				case null:
+					if !field.nullable {
+						wantErr = true
+					}
+					sets = append(sets, fmt.Sprintf("%s = $%d", field.column, len(sets)+1))
+					args = append(args, nil)
+				}
+			}
+			if len(sets) == 0 {
+				wantErr = true
+			}
+
+			checked := !wantErr && check.expects(fields, states)
+			if checked {
+				mock.ExpectBegin()
+				mock.ExpectQuery(check.lockQuery).
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			}
+			if !wantErr {
+				query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", table, strings.Join(sets, ", "), len(sets)+1)
+				mock.ExpectExec(query).
+					WithArgs(append(args, "1")...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if checked {
+				mock.ExpectQuery("SELECT question, type, scoring, config FROM questions WHERE id = $1").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"question", "type", "scoring", "config"}).
+						AddRow("Pick some", "multiple_select", "all_or_nothing", nil))
+				mock.ExpectQuery("SELECT COALESCE(is_correct, false) AS is_correct, position, match_text FROM answers WHERE question_id = $1 ORDER BY id").
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"is_correct", "position", "match_text"}).
+						AddRow(true, nil, nil).
+						AddRow(false, nil, nil))
+				mock.ExpectCommit()
+			}
+
+			err = patch(sqlx.NewDb(mockDB, "postgres"))
+			if wantErr && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func setState[T any](field *models.Optional[T], state fieldState, v T) {
+	switch state {
+	case value:
+		*field = models.Some(v)
+	case null:
+		*field = models.Optional[T]{Set: true, Null: true}
+	}
+}
+
+func TestQuizRepositoryPatch(t *testing.T) {
+	var patch models.QuizPatch
+	runPatchCombinations(t, "quizzes",
+		[]patchField{
+			{column: "title", value: "New title", apply: func(s fieldState) { setState(&patch.Title, s, "New title") }},
+			{column: "description", nullable: true, value: "New description", apply: func(s fieldState) { setState(&patch.Description, s, "New description") }},
+		},
+		nil,
+		func() { patch = models.QuizPatch{} },
+		func(db *sqlx.DB) error { return NewQuizRepository(db).Patch(context.Background(), "1", patch) },
+	)
+}
+
+func TestQuestionRepositoryPatch(t *testing.T) {
+	var patch models.QuestionPatch
+	runPatchCombinations(t, "questions",
+		[]patchField{
+			{column: "question", value: "New text", apply: func(s fieldState) { setState(&patch.Question, s, "New text") }},
+			{column: "type", value: "multiple_select", apply: func(s fieldState) { setState(&patch.Type, s, "multiple_select") }},
+			{column: "scoring", value: "partial", apply: func(s fieldState) { setState(&patch.Scoring, s, "partial") }},
+			{column: "order_num", value: int64(3), apply: func(s fieldState) { setState(&patch.Order, s, 3) }},
+			{column: "config", nullable: true, value: `{"accepted":["Paris"]}`, apply: func(s fieldState) {
+				setState(&patch.Config, s, models.QuestionConfig{TextAnswer: models.TextAnswer{Accepted: []string{"Paris"}}})
+			}},
+			{column: "explanation", nullable: true, value: "Because", apply: func(s fieldState) { setState(&patch.Explanation, s, "Because") }},
+		},
+		&ruleCheck{lockQuery: lockQuestionByID, columns: []string{"question", "type", "scoring", "config"}},
+		func() { patch = models.QuestionPatch{} },
+		func(db *sqlx.DB) error { return NewQuestionRepository(db).Patch(context.Background(), "1", patch) },
+	)
+}
+
+func TestAnswerRepositoryPatch(t *testing.T) {
+	var patch models.AnswerPatch
+	runPatchCombinations(t, "answers",
+		[]patchField{
+			{column: "answer", value: "New answer", apply: func(s fieldState) { setState(&patch.Answer, s, "New answer") }},
+			{column: "is_correct", value: true, apply: func(s fieldState) { setState(&patch.Correct, s, true) }},
+			{column: "position", nullable: true, value: int64(2), apply: func(s fieldState) { setState(&patch.Position, s, 2) }},
+			{column: "match_text", nullable: true, value: "Definition", apply: func(s fieldState) { setState(&patch.Match, s, "Definition") }},
+			{column: "rationale", nullable: true, value: "Because", apply: func(s fieldState) { setState(&patch.Rationale, s, "Because") }},
+		},
+		&ruleCheck{lockQuery: lockQuestionByAnswer, columns: []string{"is_correct", "position", "match_text"}},
+		func() { patch = models.AnswerPatch{} },
+		func(db *sqlx.DB) error { return NewAnswerRepository(db).Patch(context.Background(), "1", patch) },
+	)
+}
+
+func TestQuizRepositoryReplaceClearsMissingDescription(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectExec("UPDATE quizzes SET title = $1, description = $2 WHERE id = $3").
+		WithArgs("Replaced", nil, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewQuizRepository(sqlx.NewDb(mockDB, "postgres")).Replace(context.Background(), "1", models.QuizInput{Title: "Replaced"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A question patched together with its hints keeps neither change when the
+// hints fail to save.
+func TestQuestionRepositoryPatchWithHintsRollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	failure := errors.New("connection reset")
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockQuestionByID)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE questions SET explanation = $1 WHERE id = $2")).
+		WithArgs("Because", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM question_hints WHERE question_id = $1")).
+		WithArgs(int64(1)).
+		WillReturnError(failure)
+	mock.ExpectRollback()
+
+	err := NewQuestionRepository(db).PatchWithHints(context.Background(), "1",
+		models.QuestionPatch{Explanation: models.Some("Because")},
+		[]models.HintDraft{{Hint: "Think of the Seine"}})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the hint failure, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Invalid hints are rejected before the question is touched.
+func TestQuestionRepositoryPatchWithHintsChecksHintsFirst(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	err := NewQuestionRepository(db).PatchWithHints(context.Background(), "1",
+		models.QuestionPatch{Question: models.Some("New text")},
+		[]models.HintDraft{{Hint: "Too costly", Penalty: 2}})
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,35 +1,55 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"strconv"
+	"time"
 
 	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/scoring"
 	"github.com/jmoiron/sqlx"
 )
 
 type QuestionRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	timeout time.Duration
 }
 
-func NewQuestionRepository(db *sqlx.DB) *QuestionRepository {
-	return &QuestionRepository{db: db}
+func NewQuestionRepository(db *sqlx.DB, opts ...Option) *QuestionRepository {
+	return &QuestionRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
-func (r *QuestionRepository) GetByID(id string) (*models.Question, error) {
+func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*models.Question, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	question := &models.Question{}
-	err := r.db.Get(question, "SELECT id, quiz_id, question, type, order_num FROM questions WHERE id = $1", id)
+	err := r.db.GetContext(ctx, question, "SELECT id, quiz_id, bank_id, question, type, scoring, order_num, config, explanation FROM questions WHERE id = $1", id)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "question")
 	}
 
 	return question, nil
 }
 
-func (r *QuestionRepository) GetByQuizID(quizID string) ([]models.Question, error) {
+// GetByQuizID returns the questions of a quiz that match the query, in
+// order.
+func (r *QuestionRepository) GetByQuizID(ctx context.Context, quizID string, query models.QuestionQuery) ([]models.Question, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter, err := ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []interface{}{quizID}
+	set := quizQuestions.withTags(filter, &params)
+
 	var questions []models.Question
-	err := r.db.Select(&questions, 
-		"SELECT id, quiz_id, question, type, order_num FROM questions WHERE quiz_id = $1 ORDER BY order_num",
-		quizID)
+	err = r.db.SelectContext(ctx, &questions,
+		"SELECT "+questionColumns+" FROM questions q WHERE "+set.where+" ORDER BY "+set.order,
+		params...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,95 +57,452 @@ func (r *QuestionRepository) GetByQuizID(quizID string) ([]models.Question, erro
 	return questions, nil
 }
 
-func (r *QuestionRepository) Create(data map[string]interface{}) (int64, error) {
-	quizID, ok := data["quiz_id"].(float64)
-	if !ok {
-		quizIDStr, ok := data["quiz_id"].(string)
-		if !ok {
-			return 0, errors.New("quiz_id is required")
+func (r *QuestionRepository) Create(ctx context.Context, input models.QuestionInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.QuizID == 0 {
+		return 0, Invalid("quiz_id", "is required")
+	}
+	if input.Question == "" {
+		return 0, Invalid("question", "is required")
+	}
+	if input.Type == "" {
+		return 0, Invalid("type", "is required")
+	}
+	strategy, err := CheckQuestion(input.ToQuestion(), nil, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var questionID int64
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO questions (quiz_id, question, type, scoring, order_num, config, explanation) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		input.QuizID, input.Question, input.Type, strategy, input.Order, input.Config, input.Explanation,
+	).Scan(&questionID)
+	if err != nil {
+		return 0, dbError(err, "question")
+	}
+
+	return questionID, nil
+}
+
+// CreateWithAnswers inserts a question and its answers in one transaction.
+// Without an order_num the question is placed after the quiz's last question.
+func (r *QuestionRepository) CreateWithAnswers(ctx context.Context, quizID string, draft models.QuestionDraft) (*models.CreatedQuestion, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(quizID, 10, 64)
+	if err != nil {
+		return nil, Invalid("quiz_id", "must be an integer")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderNum := draft.Order
+	if orderNum == 0 {
+		err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(order_num), 0) + 1 FROM questions WHERE quiz_id = $1", id).Scan(&orderNum)
+		if err != nil {
+			return nil, err
 		}
-		// Convert string to int if needed
-		// This would require proper conversion logic
-		_ = quizIDStr // Placeholder to avoid unused variable warning
 	}
 
-	questionText, ok := data["question"].(string)
-	if !ok || questionText == "" {
-		return 0, errors.New("question text is required")
+	created, err := insertQuestionDraft(ctx, tx, questionOwner{"quiz_id", id}, draft, orderNum)
+	if err != nil {
+		return nil, err
 	}
 
-	questionType, ok := data["type"].(string)
-	if !ok || questionType == "" {
-		return 0, errors.New("question type is required")
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	orderNum, ok := data["order_num"].(float64)
-	if !ok {
-		orderNum = 0 // Default value if not provided
+	return &created, nil
+}
+
+// Reorder renumbers a quiz's questions to follow questionIDs, starting at 1.
+// questionIDs must list every question in the quiz exactly once.
+func (r *QuestionRepository) Reorder(ctx context.Context, quizID string, questionIDs []int64) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	var questionID int64
-	err := r.db.QueryRow(
-		"INSERT INTO questions (quiz_id, question, type, order_num) VALUES ($1, $2, $3, $4) RETURNING id",
-		int(quizID), questionText, questionType, int(orderNum),
-	).Scan(&questionID)
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM quizzes WHERE id = $1 FOR UPDATE", quizID).Scan(&id)
 	if err != nil {
-		return 0, err
+		return dbError(err, "quiz")
 	}
 
-	return questionID, nil
+	var existing []int64
+	err = tx.SelectContext(ctx, &existing, "SELECT id FROM questions WHERE quiz_id = $1 FOR UPDATE", id)
+	if err != nil {
+		return err
+	}
+
+	if len(scoring.UniqueIDs(questionIDs)) != len(questionIDs) {
+		return Invalid("question_ids", "must not contain duplicates")
+	}
+	if len(questionIDs) != len(existing) {
+		return Invalid("question_ids", "must list every question in the quiz")
+	}
+	inQuiz := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		inQuiz[id] = true
+	}
+	for _, id := range questionIDs {
+		if !inQuiz[id] {
+			return Invalid("question_ids", "contains question "+strconv.FormatInt(id, 10)+", which is not in this quiz")
+		}
+	}
+
+	for i, id := range questionIDs {
+		_, err := tx.ExecContext(ctx, "UPDATE questions SET order_num = $1 WHERE id = $2", i+1, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *QuestionRepository) Update(id string, data map[string]interface{}) error {
-	// Build query dynamically based on which fields are provided
-	query := "UPDATE questions SET "
-	params := []interface{}{}
-	paramCount := 1
-	first := true
+// questionOwner is the quiz or bank a new question belongs to: the column
+// that refers to it, and its ID.
+type questionOwner struct {
+	column string
+	id     int64
+}
+
+// insertQuestionDraft stores a question with its answers, hints and tags
+// inside tx. The draft is a complete question, so it must satisfy every rule
+// of its type.
+func insertQuestionDraft(ctx context.Context, tx *sqlx.Tx, owner questionOwner, question models.QuestionDraft, orderNum int) (models.CreatedQuestion, error) {
+	created := models.CreatedQuestion{AnswerIDs: make([]int64, len(question.Answers))}
+	if question.Question == "" {
+		return created, Invalid("question", "is required")
+	}
+	if question.Type == "" {
+		return created, Invalid("type", "is required")
+	}
+
+	strategy, err := CheckQuestion(question.ToQuestion(), question.Answers, true)
+	if err != nil {
+		return created, err
+	}
+	if err := CheckHints(question.Hints); err != nil {
+		return created, err
+	}
+	tags, err := CheckTags(question.Tags)
+	if err != nil {
+		return created, err
+	}
+
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO questions ("+owner.column+", question, type, scoring, order_num, config, explanation) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		owner.id, question.Question, question.Type, strategy, orderNum, question.Config, question.Explanation,
+	).Scan(&created.ID)
+	if err != nil {
+		return created, dbError(err, "question")
+	}
+
+	// AnswerIDs stay in the draft's order whatever order they are stored in
+	for _, i := range AnswerOrder(question) {
+		answer := question.Answers[i]
+		if answer.Answer == "" {
+			return created, Invalid("answer", "is required")
+		}
 
-	// Check for each possible field
-	if questionText, ok := data["question"].(string); ok {
-		if !first {
-			query += ", "
+		var answerID int64
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO answers (question_id, answer, is_correct, position, match_text, rationale) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			created.ID, answer.Answer, answer.Correct, answer.Position, answer.Match, answer.Rationale,
+		).Scan(&answerID)
+		if err != nil {
+			return created, dbError(err, "answer")
 		}
-		query += "question = $" + string(paramCount+'0')
-		params = append(params, questionText)
-		paramCount++
-		first = false
+		created.AnswerIDs[i] = answerID
+	}
+
+	if _, err := insertHints(ctx, tx, created.ID, question.Hints); err != nil {
+		return created, err
 	}
 
-	if questionType, ok := data["type"].(string); ok {
-		if !first {
-			query += ", "
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx,
+			upsertTag+" INSERT INTO question_tags (question_id, tag_id) SELECT $1, id FROM tag",
+			created.ID, tag)
+		if err != nil {
+			return created, dbError(err, "tag")
 		}
-		query += "type = $" + string(paramCount+'0')
-		params = append(params, questionType)
-		paramCount++
-		first = false
 	}
 
-	if orderNum, ok := data["order_num"].(float64); ok {
-		if !first {
-			query += ", "
+	return created, nil
+}
+
+// Replace overwrites every editable column of a question, as PUT requires.
+// The question stays in its quiz, and its answers must still suit its type.
+func (r *QuestionRepository) Replace(ctx context.Context, id string, input models.QuestionInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.Question == "" {
+		return Invalid("question", "is required")
+	}
+	if input.Type == "" {
+		return Invalid("type", "is required")
+	}
+	strategy, err := CheckQuestion(input.ToQuestion(), nil, false)
+	if err != nil {
+		return err
+	}
+
+	query, params, err := newUpdateBuilder("questions").
+		Set("question", input.Question).
+		Set("type", input.Type).
+		Set("scoring", strategy).
+		Set("order_num", input.Order).
+		Set("config", input.Config).
+		Set("explanation", input.Explanation).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	return r.updateChecked(ctx, id, query, params)
+}
+
+// Patch applies a JSON Merge Patch to a question, changing only the fields
+// that are present. Any change the type rules depend on, including the text,
+// which holds the blanks of a fill_in_blank question, re-checks the question.
+func (r *QuestionRepository) Patch(ctx context.Context, id string, patch models.QuestionPatch) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	builder, err := questionPatchBuilder(patch)
+	if err != nil {
+		return err
+	}
+
+	query, params, err := builder.Build(id)
+	if err != nil {
+		return err
+	}
+
+	if !patchesRules(patch) {
+		result, err := r.db.ExecContext(ctx, query, params...)
+		return requireRow(result, err, "question")
+	}
+
+	return r.updateChecked(ctx, id, query, params)
+}
+
+// PatchWithHints applies a patch to a question and replaces its hints in one
+// transaction, so neither change is kept unless both are. nil hints are left
+// as they are, and a patch with no fields changes only the hints.
+func (r *QuestionRepository) PatchWithHints(ctx context.Context, id string, patch models.QuestionPatch, hints []models.HintDraft) error {
+	if hints == nil {
+		return r.Patch(ctx, id, patch)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	builder, err := questionPatchBuilder(patch)
+	if err != nil {
+		return err
+	}
+	if err := CheckHints(hints); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	questionID, err := lockQuestion(ctx, tx, lockQuestionByID, id, NotFound("question"))
+	if err != nil {
+		return err
+	}
+
+	if !builder.Empty() {
+		query, params, err := builder.Build(questionID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, params...); err != nil {
+			return dbError(err, "question")
 		}
-		query += "order_num = $" + string(paramCount+'0')
-		params = append(params, int(orderNum))
-		paramCount++
-		first = false
+		if patchesRules(patch) {
+			if err := checkStoredQuestion(ctx, tx, questionID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM question_hints WHERE question_id = $1", questionID); err != nil {
+		return err
+	}
+	if _, err := insertHints(ctx, tx, questionID, hints); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// questionPatchBuilder returns the UPDATE for the fields present in a patch,
+// which has no columns when none are.
+func questionPatchBuilder(patch models.QuestionPatch) (*updateBuilder, error) {
+	if patch.Question.IsValue() && patch.Question.Value == "" {
+		return nil, Invalid("question", "must not be empty")
+	}
+
+	builder := newUpdateBuilder("questions")
+	if err := setOptional(builder, "question", patch.Question, false); err != nil {
+		return nil, err
+	}
+	if err := setOptional(builder, "type", patch.Type, false); err != nil {
+		return nil, err
+	}
+	if err := setOptional(builder, "scoring", patch.Scoring, false); err != nil {
+		return nil, err
+	}
+	if err := setOptional(builder, "order_num", patch.Order, false); err != nil {
+		return nil, err
+	}
+	if err := setOptional(builder, "config", patch.Config, true); err != nil {
+		return nil, err
+	}
+	if err := setOptional(builder, "explanation", patch.Explanation, true); err != nil {
+		return nil, err
+	}
+
+	return builder, nil
+}
+
+// patchesRules reports whether a patch changes anything the type rules
+// depend on.
+func patchesRules(patch models.QuestionPatch) bool {
+	return patch.Question.Set || patch.Type.Set || patch.Scoring.Set || patch.Config.Set
+}
+
+// updateChecked runs an UPDATE of question id and checks the result against
+// the rules of its type before committing.
+func (r *QuestionRepository) updateChecked(ctx context.Context, id string, query string, params []interface{}) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	if first {
-		return errors.New("no valid fields to update")
+	questionID, err := lockQuestion(ctx, tx, lockQuestionByID, id, NotFound("question"))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, params...); err != nil {
+		return dbError(err, "question")
+	}
+
+	if err := checkStoredQuestion(ctx, tx, questionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetHints returns a question's hints, ordered by tier.
+func (r *QuestionRepository) GetHints(ctx context.Context, questionID string) ([]models.Hint, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM questions WHERE id = $1)", questionID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NotFound("question")
+	}
+
+	hints := []models.Hint{}
+	err = r.db.SelectContext(ctx, &hints,
+		"SELECT id, question_id, tier, hint, penalty FROM question_hints WHERE question_id = $1 ORDER BY tier",
+		questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return hints, nil
+}
+
+// ReplaceHints swaps a question's hints for the given list, numbering their
+// tiers from 1 in order. An empty list removes them all.
+func (r *QuestionRepository) ReplaceHints(ctx context.Context, questionID string, hints []models.HintDraft) ([]models.Hint, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := CheckHints(hints); err != nil {
+		return nil, err
 	}
 
-	query += " WHERE id = $" + string(paramCount+'0')
-	params = append(params, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := lockQuestion(ctx, tx, lockQuestionByID, questionID, NotFound("question"))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM question_hints WHERE question_id = $1", id); err != nil {
+		return nil, err
+	}
 
-	_, err := r.db.Exec(query, params...)
-	return err
+	stored, err := insertHints(ctx, tx, id, hints)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
 }
 
-func (r *QuestionRepository) Delete(id string) error {
-	_, err := r.db.Exec("DELETE FROM questions WHERE id = $1", id)
-	return err
+// insertHints stores hints for a question that has none inside tx.
+func insertHints(ctx context.Context, tx *sqlx.Tx, questionID int64, hints []models.HintDraft) ([]models.Hint, error) {
+	stored := make([]models.Hint, 0, len(hints))
+	for i, hint := range hints {
+		stored = append(stored, models.Hint{QuestionID: int(questionID), Tier: i + 1, Hint: hint.Hint, Penalty: hint.Penalty})
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO question_hints (question_id, tier, hint, penalty) VALUES ($1, $2, $3, $4) RETURNING id",
+			questionID, i+1, hint.Hint, hint.Penalty,
+		).Scan(&stored[i].ID)
+		if err != nil {
+			return nil, dbError(err, "hint")
+		}
+	}
+
+	return stored, nil
+}
+
+func (r *QuestionRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM questions WHERE id = $1", id)
+	return requireRow(result, err, "question")
 }
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/scoring"
+	"github.com/jmoiron/sqlx"
+)
+
+// CheckQuestion applies the rules of a question's type to its scoring
+// strategy, config and answers. It returns the strategy to store, which is
+// the type's default when the question's Scoring is empty. See
+// QuestionType.CheckAnswers for what complete means.
+func CheckQuestion(question models.Question, answers []models.AnswerDraft, complete bool) (string, error) {
+	questionType, ok := models.LookupQuestionType(question.Type)
+	if !ok {
+		return "", Invalid("type", "must be a known question type")
+	}
+
+	strategy, err := questionType.ScoringStrategy(question.Scoring)
+	if err != nil {
+		return "", Invalid("scoring", err.Error())
+	}
+
+	if message := questionType.CheckConfig(question.Question, question.Config); message != "" {
+		return "", Invalid("config", message)
+	}
+
+	if message := questionType.CheckAnswers(answers, complete); message != "" {
+		return "", Invalid("answers", message)
+	}
+
+	return strategy, nil
+}
+
+// CheckHints checks the hints an author gave a question. Binding checks the
+// same for API requests; this covers the other callers.
+func CheckHints(hints []models.HintDraft) error {
+	for _, hint := range hints {
+		if hint.Hint == "" {
+			return Invalid("hints", "must each have text")
+		}
+		if hint.Penalty < 0 || hint.Penalty > 1 {
+			return Invalid("hints", "must each have a penalty between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// AnswerOrder returns the order to store a draft's answers in, as indexes
+// into its Answers. Players see answer IDs, which follow the order answers
+// are stored in, so the steps of an ordering question are stored shuffled
+// and never in their correct order.
+func AnswerOrder(draft models.QuestionDraft) []int {
+	order := make([]int, len(draft.Answers))
+	for i := range order {
+		order[i] = i
+	}
+	if draft.Type != models.QuestionTypeOrdering {
+		return order
+	}
+
+	for inPositionOrder(draft.Answers, order) {
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	return order
+}
+
+// inPositionOrder reports whether answers taken in order have strictly
+// increasing positions. A single answer always does, so it is left alone.
+func inPositionOrder(answers []models.AnswerDraft, order []int) bool {
+	if len(order) < 2 {
+		return false
+	}
+	position := func(i int) int {
+		if p := answers[order[i]].Position; p != nil {
+			return *p
+		}
+		return 0
+	}
+	for i := 1; i < len(order); i++ {
+		if position(i-1) >= position(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckTags trims the tags an author gave a question and drops repeats. It
+// returns them in the order given.
+func CheckTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var checked []string
+	for _, tag := range tags {
+		tag, err := CheckTagName("tags", tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			checked = append(checked, tag)
+		}
+	}
+	return checked, nil
+}
+
+// CheckTagName trims a tag name given as field. Names cannot contain the
+// characters tag expressions are built from, so every tag can be filtered by.
+func CheckTagName(field string, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", Invalid(field, "must not be empty")
+	}
+	if len(name) > 100 {
+		return "", Invalid(field, "must be at most 100 characters")
+	}
+	if strings.ContainsAny(name, ",|") || strings.HasPrefix(name, "!") {
+		return "", Invalid(field, `must not contain "," or "|" or start with "!"`)
+	}
+	return name, nil
+}
+
+// CheckResponse checks that a response has the shape its question's type
+// expects: answer IDs to select or order, answer IDs each paired with the
+// text of a match, or typed text.
+func CheckResponse(question models.Question, response models.AttemptResponse) error {
+	questionType, ok := models.LookupQuestionType(question.Type)
+	if !ok {
+		return Invalid("question_id", "has a question type that cannot be answered")
+	}
+
+	if response.TimeSpentMs != nil && *response.TimeSpentMs < 0 {
+		return Invalid("time_spent_ms", "must not be negative")
+	}
+
+	switch questionType.Respond {
+	case models.RespondBySelecting, models.RespondByOrdering:
+		if len(response.Text) > 0 {
+			return Invalid("text", "is not used by "+question.Type+" questions")
+		}
+		return nil
+	case models.RespondByMatching:
+		if len(scoring.UniqueIDs(response.AnswerIDs)) != len(response.AnswerIDs) {
+			return Invalid("answer_ids", "must not repeat for matching questions")
+		}
+		if len(response.Text) != len(response.AnswerIDs) {
+			return Invalid("text", "must hold one match for each of answer_ids")
+		}
+		return nil
+	}
+
+	want := questionType.ResponseCount(question.Config)
+	if len(response.AnswerIDs) > 0 {
+		return Invalid("answer_ids", "are not used by "+question.Type+" questions")
+	}
+	if len(response.Text) != want {
+		return Invalid("text", "must have "+strconv.Itoa(want)+" value(s) for this question")
+	}
+	return nil
+}
+
+// Queries that lock the question a change is about to touch, so concurrent
+// edits to one question and its answers are checked one after another.
+const (
+	lockQuestionByID     = "SELECT id FROM questions WHERE id = $1 FOR UPDATE"
+	lockQuestionByAnswer = "SELECT q.id FROM questions q JOIN answers a ON a.question_id = q.id WHERE a.id = $1 FOR UPDATE OF q"
+)
+
+// lockQuestion runs one of the lock queries and returns the question's ID.
+// missing is returned when there is no such row.
+func lockQuestion(ctx context.Context, tx *sqlx.Tx, query string, id interface{}, missing error) (int64, error) {
+	var questionID int64
+	err := tx.QueryRowContext(ctx, query, id).Scan(&questionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missing
+	}
+	if err != nil {
+		return 0, dbError(err, "question")
+	}
+
+	return questionID, nil
+}
+
+// checkStoredQuestion checks a locked question, as changed so far inside tx,
+// against the rules of its type. Answers may still be added later, so only
+// the rules that more answers could not fix are applied.
+func checkStoredQuestion(ctx context.Context, tx *sqlx.Tx, questionID int64) error {
+	var question models.Question
+	err := tx.GetContext(ctx, &question, "SELECT question, type, scoring, config FROM questions WHERE id = $1", questionID)
+	if err != nil {
+		return err
+	}
+
+	var stored []models.Answer
+	err = tx.SelectContext(ctx, &stored,
+		"SELECT COALESCE(is_correct, false) AS is_correct, position, match_text FROM answers WHERE question_id = $1 ORDER BY id",
+		questionID)
+	if err != nil {
+		return err
+	}
+
+	answers := make([]models.AnswerDraft, len(stored))
+	for i, answer := range stored {
+		answers[i] = answer.ToDraft()
+	}
+
+	_, err = CheckQuestion(question, answers, false)
+	return err
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/changangus/go-quiz-backend/internal/draw"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// CheckDrawRules checks the draw rules an author gave a quiz. Binding checks
+// most of this for API requests; this covers the other callers. Tags are
+// trimmed in place.
+func CheckDrawRules(rules []models.DrawRuleDraft) error {
+	for i := range rules {
+		if rules[i].BankID < 1 {
+			return Invalid("rules", "must each name a bank")
+		}
+		if rules[i].Count < 1 {
+			return Invalid("rules", "must each draw at least one question")
+		}
+		if tag := rules[i].Tag; tag != nil {
+			trimmed, err := CheckTagName("rules", *tag)
+			if err != nil {
+				return err
+			}
+			rules[i].Tag = &trimmed
+		}
+	}
+	return nil
+}
+
+// GetDrawRules returns a quiz's draw rules in the order they are applied.
+func (r *QuizRepository) GetDrawRules(ctx context.Context, quizID string) ([]models.DrawRule, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.GetByID(ctx, quizID); err != nil {
+		return nil, err
+	}
+
+	rules := []models.DrawRule{}
+	err := r.db.SelectContext(ctx, &rules,
+		"SELECT id, quiz_id, bank_id, position, count, tag FROM quiz_draw_rules WHERE quiz_id = $1 ORDER BY position",
+		quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// ReplaceDrawRules swaps a quiz's draw rules for the given list, applied in
+// its order. An empty list removes them all. Attempts already started keep
+// the questions they were given.
+func (r *QuizRepository) ReplaceDrawRules(ctx context.Context, quizID string, drafts []models.DrawRuleDraft) ([]models.DrawRule, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := CheckDrawRules(drafts); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM quizzes WHERE id = $1 FOR UPDATE", quizID).Scan(&id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM quiz_draw_rules WHERE quiz_id = $1", id); err != nil {
+		return nil, err
+	}
+
+	rules := make([]models.DrawRule, 0, len(drafts))
+	for i, draft := range drafts {
+		rule := models.DrawRule{QuizID: id, BankID: draft.BankID, Position: i + 1, Count: draft.Count, Tag: draft.Tag}
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO quiz_draw_rules (quiz_id, bank_id, position, count, tag) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			rule.QuizID, rule.BankID, rule.Position, rule.Count, rule.Tag,
+		).Scan(&rule.ID)
+		if err != nil {
+			return nil, dbError(err, "draw rule")
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// RuleFilter returns the tag filter a draw rule applies to its bank. Like any
+// tag filter, it also matches questions tagged beneath the rule's tag.
+func RuleFilter(rule models.DrawRule) TagFilter {
+	if rule.Tag == nil {
+		return nil
+	}
+	return TagFilter{{{Name: *rule.Tag}}}
+}
+
+// drawQuestions picks the questions of a new attempt at a quiz with seed.
+// See draw.Assemble. A rule its bank cannot fill is a conflict.
+func drawQuestions(ctx context.Context, tx *sqlx.Tx, quizID string, seed int64) ([]int, error) {
+	fixed, rules, pools, err := drawSources(ctx, tx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := draw.Assemble(seed, fixed, rules, pools)
+	if err != nil {
+		return nil, Conflict(err.Error())
+	}
+
+	return questions, nil
+}
+
+// drawSources loads what a quiz's attempts are given questions from: the
+// quiz's own questions, its draw rules, and for each rule the questions of
+// its bank that match it.
+func drawSources(ctx context.Context, q sqlx.QueryerContext, quizID string) ([]int, []models.DrawRule, [][]int, error) {
+	var fixed []int
+	err := sqlx.SelectContext(ctx, q, &fixed, "SELECT id FROM questions WHERE quiz_id = $1 ORDER BY order_num, id", quizID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var rules []models.DrawRule
+	err = sqlx.SelectContext(ctx, q, &rules,
+		"SELECT id, quiz_id, bank_id, position, count, tag FROM quiz_draw_rules WHERE quiz_id = $1 ORDER BY position",
+		quizID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pools := make([][]int, len(rules))
+	for i, rule := range rules {
+		params := []interface{}{rule.BankID}
+		param := func(value interface{}) string {
+			params = append(params, value)
+			return "$" + strconv.Itoa(len(params))
+		}
+
+		query := "SELECT q.id FROM questions q WHERE q.bank_id = $1"
+		if rule.Tag != nil {
+			query += " AND " + RuleFilter(rule).condition("question_tags", "question_id", "q.id", param)
+		}
+		if err := sqlx.SelectContext(ctx, q, &pools[i], query, params...); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return fixed, rules, pools, nil
+}
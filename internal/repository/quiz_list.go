@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// QuizSort is a parsed sort parameter for quiz listings.
+type QuizSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseQuizSort reads a sort such as "title" or "-id". An empty sort means
+// ascending by id.
+func ParseQuizSort(sort string) (QuizSort, error) {
+	if sort == "" {
+		return QuizSort{Field: "id"}, nil
+	}
+
+	s := QuizSort{Field: strings.TrimPrefix(sort, "-"), Desc: strings.HasPrefix(sort, "-")}
+	if s.Field != "id" && s.Field != "title" {
+		return QuizSort{}, Invalid("sort", "must be one of: id, title, -id, -title")
+	}
+	return s, nil
+}
+
+func (s QuizSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// QuizCursor marks the last quiz of a page. Title is only used when sorting
+// by title. Cursors are opaque to clients.
+type QuizCursor struct {
+	Sort  string `json:"s"`
+	ID    int    `json:"id"`
+	Title string `json:"t,omitempty"`
+}
+
+// NewQuizCursor returns the cursor that continues a listing after quiz.
+func NewQuizCursor(sort QuizSort, quiz models.Quiz) QuizCursor {
+	cursor := QuizCursor{Sort: sort.String(), ID: quiz.ID}
+	if sort.Field == "title" {
+		cursor.Title = quiz.Title
+	}
+	return cursor
+}
+
+func (c QuizCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeQuizCursor parses a cursor produced by Encode. A cursor is only valid
+// for the sort it was created with.
+func DecodeQuizCursor(value string, sort QuizSort) (QuizCursor, error) {
+	var cursor QuizCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.ID < 1 {
+		return QuizCursor{}, Invalid("cursor", "is not a valid cursor")
+	}
+	if cursor.Sort != sort.String() {
+		return QuizCursor{}, Invalid("cursor", "was created for a different sort")
+	}
+
+	return cursor, nil
+}
+
+// PageSize returns the limit to apply for a requested limit.
+func PageSize(limit int) int {
+	if limit <= 0 {
+		return models.DefaultPageSize
+	}
+	if limit > models.MaxPageSize {
+		return models.MaxPageSize
+	}
+	return limit
+}
+
+// List returns a page of quizzes using keyset pagination, so later pages cost
+// the same as the first. Title and description filters match substrings,
+// ignoring case; the tag filter is a tag expression.
+func (r *QuizRepository) List(ctx context.Context, query models.QuizQuery) (*models.QuizPage, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	sort, err := ParseQuizSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ParseTagFilter(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var params []interface{}
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+
+	if query.Title != "" {
+		where = append(where, "title ILIKE "+param(likePattern(query.Title)))
+	}
+	if query.Description != "" {
+		where = append(where, "description ILIKE "+param(likePattern(query.Description)))
+	}
+	if filter != nil {
+		where = append(where, filter.condition("quiz_tags", "quiz_id", "quizzes.id", param))
+	}
+
+	page := &models.QuizPage{}
+	if query.IncludeTotal {
+		var total int
+		err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM quizzes"+whereClause(where), params...)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		cursor, err := DecodeQuizCursor(query.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+
+		if sort.Field == "title" {
+			where = append(where, "(title, id) "+comparison+" ("+param(cursor.Title)+", "+param(cursor.ID)+")")
+		} else {
+			where = append(where, "id "+comparison+" "+param(cursor.ID))
+		}
+	}
+
+	orderBy := "id " + direction
+	if sort.Field == "title" {
+		orderBy = "title " + direction + ", id " + direction
+	}
+
+	// Fetch one extra row to learn whether there is another page
+	limit := PageSize(query.Limit)
+	var quizzes []models.Quiz
+	err = r.db.SelectContext(ctx, &quizzes,
+		"SELECT id, title, description FROM quizzes"+whereClause(where)+
+			" ORDER BY "+orderBy+" LIMIT "+param(limit+1),
+		params...)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Data, page.NextCursor = TrimQuizPage(quizzes, limit, sort)
+	return page, nil
+}
+
+// TrimQuizPage cuts quizzes fetched with one extra row down to limit and
+// returns the cursor for the next page, if there is one.
+func TrimQuizPage(quizzes []models.Quiz, limit int, sort QuizSort) ([]models.Quiz, *string) {
+	if quizzes == nil {
+		quizzes = []models.Quiz{}
+	}
+	if len(quizzes) <= limit {
+		return quizzes, nil
+	}
+
+	quizzes = quizzes[:limit]
+	next := NewQuizCursor(sort, quizzes[limit-1]).Encode()
+	return quizzes, &next
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// likePattern matches value anywhere in a column, treating LIKE wildcards in
+// value literally.
+func likePattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestQuizRepositoryListUsesKeyset(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer mockDB.Close()
+
+	sort := QuizSort{Field: "title", Desc: true}
+	cursor := QuizCursor{Sort: sort.String(), ID: 7, Title: "Maps"}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM quizzes WHERE title ILIKE $1").
+		WithArgs(`%100\%%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT id, title, description FROM quizzes WHERE title ILIKE $1 AND (title, id) < ($2, $3) ORDER BY title DESC, id DESC LIMIT $4").
+		WithArgs(`%100\%%`, "Maps", 7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description"}).
+			AddRow(5, "Maps", nil).
+			AddRow(9, "Lists", nil).
+			AddRow(2, "Arrays", nil))
+
+	page, err := NewQuizRepository(sqlx.NewDb(mockDB, "postgres")).List(context.Background(), models.QuizQuery{
+		Limit:        2,
+		Cursor:       cursor.Encode(),
+		Sort:         "-title",
+		Title:        "100%",
+		IncludeTotal: true,
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(page.Data) != 2 || page.Total == nil || *page.Total != 3 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if page.NextCursor == nil {
+		t.Fatal("expected a next cursor")
+	}
+	next, err := DecodeQuizCursor(*page.NextCursor, sort)
+	if err != nil || next.ID != 9 || next.Title != "Lists" {
+		t.Errorf("next cursor = %+v, %v; want the last quiz on the page", next, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
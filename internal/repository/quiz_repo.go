@@ -1,33 +1,75 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"time"
 
 	"github.com/changangus/go-quiz-backend/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
 type QuizRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	timeout time.Duration
 }
 
-func NewQuizRepository(db *sqlx.DB) *QuizRepository {
-	return &QuizRepository{db: db}
+func NewQuizRepository(db *sqlx.DB, opts ...Option) *QuizRepository {
+	return &QuizRepository{db: db, timeout: newOptions(opts).queryTimeout}
 }
 
-func (r *QuizRepository) GetByID(id string) (*models.Quiz, error) {
-	quiz := &models.Quiz{}
-	err := r.db.Get(quiz, "SELECT id, title, description FROM quizzes WHERE id = $1", id)
+func (r *QuizRepository) GetByID(ctx context.Context, id string) (*models.Quiz, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return getQuiz(ctx, r.db, id)
+}
+
+// GetFull loads a quiz with all of its questions and their answers, hints
+// and tags. It always runs exactly five queries, in one snapshot, regardless
+// of how many questions the quiz has.
+func (r *QuizRepository) GetFull(ctx context.Context, id string) (*models.FullQuiz, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, readSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quiz, err := getQuiz(ctx, tx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	questions, err := loadFullQuestions(ctx, tx, quizQuestions, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.FullQuiz{Quiz: *quiz, Questions: questions}, nil
+}
+
+func getQuiz(ctx context.Context, q sqlx.QueryerContext, id string) (*models.Quiz, error) {
+	quiz := &models.Quiz{}
+	err := sqlx.GetContext(ctx, q, quiz, "SELECT id, title, description FROM quizzes WHERE id = $1", id)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+
 	return quiz, nil
 }
 
-func (r *QuizRepository) GetAll() ([]models.Quiz, error) {
-	var quizzes []models.Quiz
-	err := r.db.Select(&quizzes, "SELECT id, title, description FROM quizzes ORDER BY id")
+func (r *QuizRepository) GetAll(ctx context.Context) ([]models.Quiz, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	quizzes := []models.Quiz{}
+	err := r.db.SelectContext(ctx, &quizzes, "SELECT id, title, description FROM quizzes ORDER BY id")
 	if err != nil {
 		return nil, err
 	}
@@ -35,18 +77,18 @@ func (r *QuizRepository) GetAll() ([]models.Quiz, error) {
 	return quizzes, nil
 }
 
-func (r *QuizRepository) Create(data map[string]interface{}) (int64, error) {
-	title, ok := data["title"].(string)
-	if !ok || title == "" {
-		return 0, errors.New("title is required")
-	}
+func (r *QuizRepository) Create(ctx context.Context, input models.QuizInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	description, _ := data["description"].(string)
+	if input.Title == "" {
+		return 0, Invalid("title", "is required")
+	}
 
 	var quizID int64
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"INSERT INTO quizzes (title, description) VALUES ($1, $2) RETURNING id",
-		title, description,
+		input.Title, input.Description,
 	).Scan(&quizID)
 	if err != nil {
 		return 0, err
@@ -55,42 +97,106 @@ func (r *QuizRepository) Create(data map[string]interface{}) (int64, error) {
 	return quizID, nil
 }
 
-func (r *QuizRepository) Update(id string, data map[string]interface{}) error {
-	title, titleOk := data["title"].(string)
-	description, descOk := data["description"].(string)
+// CreateFull inserts a quiz together with its questions and answers in a
+// single transaction, so a failure part way through leaves nothing behind.
+// Questions without an order_num are numbered by their position in the draft.
+func (r *QuizRepository) CreateFull(ctx context.Context, draft models.QuizDraft) (*models.CreatedQuiz, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	if !titleOk && !descOk {
-		return errors.New("no valid fields to update")
+	if draft.Title == "" {
+		return nil, Invalid("title", "is required")
 	}
 
-	// Build query dynamically based on which fields are provided
-	query := "UPDATE quizzes SET "
-	params := []interface{}{}
-	paramCount := 1
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	if titleOk {
-		query += "title = $" + string(paramCount+'0')
-		params = append(params, title)
-		paramCount++
+	created := &models.CreatedQuiz{Questions: make([]models.CreatedQuestion, 0, len(draft.Questions))}
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO quizzes (title, description) VALUES ($1, $2) RETURNING id",
+		draft.Title, draft.Description,
+	).Scan(&created.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if descOk {
-		if paramCount > 1 {
-			query += ", "
+	for i, question := range draft.Questions {
+		orderNum := question.Order
+		if orderNum == 0 {
+			orderNum = i + 1
 		}
-		query += "description = $" + string(paramCount+'0')
-		params = append(params, description)
-		paramCount++
+
+		createdQuestion, err := insertQuestionDraft(ctx, tx, questionOwner{"quiz_id", created.ID}, question, orderNum)
+		if err != nil {
+			return nil, err
+		}
+
+		created.Questions = append(created.Questions, createdQuestion)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Replace overwrites every column of a quiz, as PUT requires. A missing
+// description is stored as null.
+func (r *QuizRepository) Replace(ctx context.Context, id string, input models.QuizInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if input.Title == "" {
+		return Invalid("title", "is required")
 	}
 
-	query += " WHERE id = $" + string(paramCount+'0')
-	params = append(params, id)
+	query, params, err := newUpdateBuilder("quizzes").
+		Set("title", input.Title).
+		Set("description", input.Description).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, params...)
+	return requireRow(result, err, "quiz")
+}
+
+// Patch applies a JSON Merge Patch to a quiz, changing only the fields that
+// are present. A null description clears it.
+func (r *QuizRepository) Patch(ctx context.Context, id string, patch models.QuizPatch) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if patch.Title.IsValue() && patch.Title.Value == "" {
+		return Invalid("title", "must not be empty")
+	}
 
-	_, err := r.db.Exec(query, params...)
-	return err
+	builder := newUpdateBuilder("quizzes")
+	if err := setOptional(builder, "title", patch.Title, false); err != nil {
+		return err
+	}
+	if err := setOptional(builder, "description", patch.Description, true); err != nil {
+		return err
+	}
+
+	query, params, err := builder.Build(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, params...)
+	return requireRow(result, err, "quiz")
 }
 
-func (r *QuizRepository) Delete(id string) error {
-	_, err := r.db.Exec("DELETE FROM quizzes WHERE id = $1", id)
-	return err
+func (r *QuizRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM quizzes WHERE id = $1", id)
+	return requireRow(result, err, "quiz")
 }
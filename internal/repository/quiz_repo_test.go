@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// expectFullQuiz registers the queries GetFull is allowed to run for a quiz
+// with the given number of questions, each having answersPer answers.
+func expectFullQuiz(mock sqlmock.Sqlmock, questionCount int, answersPer int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM quizzes WHERE id = $1")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description"}).
+			AddRow(1, "WCAG 2.2", "Perceivable"))
+
+	questions := sqlmock.NewRows([]string{"id", "quiz_id", "question", "type", "order_num"})
+	for q := 1; q <= questionCount; q++ {
+		questions.AddRow(q, 1, fmt.Sprintf("Question %d", q), "multiple_choice", q)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM questions q WHERE q.quiz_id = $1")).
+		WithArgs("1").
+		WillReturnRows(questions)
+
+	answers := sqlmock.NewRows([]string{"id", "question_id", "answer", "is_correct"})
+	answerID := 1
+	for q := 1; q <= questionCount; q++ {
+		for a := 0; a < answersPer; a++ {
+			answers.AddRow(answerID, q, fmt.Sprintf("Answer %d", answerID), a == 0)
+			answerID++
+		}
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM answers a JOIN questions q ON q.id = a.question_id WHERE q.quiz_id = $1")).
+		WithArgs("1").
+		WillReturnRows(answers)
+
+	hints := sqlmock.NewRows([]string{"id", "question_id", "tier", "hint", "penalty"})
+	for q := 1; q <= questionCount; q++ {
+		hints.AddRow(q, q, 1, fmt.Sprintf("Hint %d", q), 0.25)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_hints h JOIN questions q ON q.id = h.question_id WHERE q.quiz_id = $1")).
+		WithArgs("1").
+		WillReturnRows(hints)
+
+	tags := sqlmock.NewRows([]string{"question_id", "name"})
+	for q := 1; q <= questionCount; q++ {
+		tags.AddRow(q, "1.4")
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_tags qt JOIN tags t ON t.id = qt.tag_id JOIN questions q ON q.id = qt.question_id WHERE q.quiz_id = $1")).
+		WithArgs("1").
+		WillReturnRows(tags)
+	mock.ExpectCommit()
+}
+
+func newMockDB(tb testing.TB) (*sqlx.DB, sqlmock.Sqlmock) {
+	tb.Helper()
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		tb.Fatalf("failed to create sqlmock: %v", err)
+	}
+	tb.Cleanup(func() { mockDB.Close() })
+
+	return sqlx.NewDb(mockDB, "postgres"), mock
+}
+
+// newCountingMockDB is newMockDB that also counts the statements run, by
+// counting the matches sqlmock makes against its expectations.
+func newCountingMockDB(tb testing.TB) (*sqlx.DB, sqlmock.Sqlmock, *int) {
+	tb.Helper()
+	statements := 0
+	matcher := sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		statements++
+		return sqlmock.QueryMatcherRegexp.Match(expected, actual)
+	})
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		tb.Fatalf("failed to create sqlmock: %v", err)
+	}
+	tb.Cleanup(func() { mockDB.Close() })
+
+	return sqlx.NewDb(mockDB, "postgres"), mock, &statements
+}
+
+func TestQuizRepositoryGetFull(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectFullQuiz(mock, 3, 4)
+
+	quiz, err := NewQuizRepository(db).GetFull(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetFull returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(quiz.Questions) != 3 {
+		t.Fatalf("expected 3 questions, got %d", len(quiz.Questions))
+	}
+	for i, question := range quiz.Questions {
+		if question.Order != i+1 {
+			t.Errorf("question %d has order_num %d", i, question.Order)
+		}
+		if len(question.Answers) != 4 {
+			t.Errorf("question %d has %d answers, want 4", question.ID, len(question.Answers))
+		}
+		if len(question.Hints) != 1 || question.Hints[0].QuestionID != question.ID {
+			t.Errorf("question %d has hints %+v, want its own one", question.ID, question.Hints)
+		}
+		if len(question.Tags) != 1 {
+			t.Errorf("question %d has tags %v, want one", question.ID, question.Tags)
+		}
+		for _, answer := range question.Answers {
+			if answer.QuestionID != question.ID {
+				t.Errorf("answer %d attached to question %d, belongs to %d", answer.ID, question.ID, answer.QuestionID)
+			}
+		}
+	}
+}
+
+// Rows for a question outside the set, such as one moved to another quiz
+// between queries, are left out rather than attached to the wrong question.
+func TestLoadFullQuestionsSkipsStrayRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM questions q WHERE q.quiz_id = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quiz_id", "question", "type", "order_num"}).
+			AddRow(1, 1, "Question 1", "multiple_choice", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM answers a")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id", "answer", "is_correct"}).
+			AddRow(1, 1, "Answer 1", true).
+			AddRow(2, 2, "Answer 2", true))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_hints h")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id", "tier", "hint", "penalty"}).
+			AddRow(1, 2, 1, "Hint", 0.25))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_tags qt")).
+		WillReturnRows(sqlmock.NewRows([]string{"question_id", "name"}).
+			AddRow(2, "1.4"))
+
+	questions, err := loadFullQuestions(context.Background(), db, quizQuestions, "1")
+	if err != nil {
+		t.Fatalf("loadFullQuestions returned error: %v", err)
+	}
+
+	if len(questions) != 1 || len(questions[0].Answers) != 1 || len(questions[0].Hints) != 0 || len(questions[0].Tags) != 0 {
+		t.Errorf("questions = %+v, want question 1 with only its own answer", questions)
+	}
+}
+
+// BenchmarkQuizRepositoryGetFull reports the queries each GetFull ran
+// alongside the usual timings. The count stays flat as the number of
+// questions grows.
+func BenchmarkQuizRepositoryGetFull(b *testing.B) {
+	for _, questionCount := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("questions=%d", questionCount), func(b *testing.B) {
+			db, mock, statements := newCountingMockDB(b)
+			repo := NewQuizRepository(db)
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				expectFullQuiz(mock, questionCount, 4)
+				b.StartTimer()
+
+				if _, err := repo.GetFull(context.Background(), "1"); err != nil {
+					b.Fatalf("GetFull returned error: %v", err)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(*statements)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func TestQuizRepositoryCreateFullRollsBackOnFailure(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO quizzes")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO questions")).
+		WithArgs(7, "Is this atomic?", "true_false", "all_or_nothing", 1, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(70))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO answers")).
+		WillReturnError(fmt.Errorf("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := NewQuizRepository(db).CreateFull(context.Background(), models.QuizDraft{
+		Title: "Transactions",
+		Questions: []models.QuestionDraft{{
+			Question: "Is this atomic?",
+			Type:     "true_false",
+			Answers:  []models.AnswerDraft{{Answer: "True", Correct: true}, {Answer: "False"}},
+		}},
+	})
+	if err == nil {
+		t.Fatal("expected CreateFull to fail")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
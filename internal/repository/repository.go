@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// QuizStore is implemented by QuizRepository and by the in-memory store in
+// the memory package.
+type QuizStore interface {
+	GetByID(ctx context.Context, id string) (*models.Quiz, error)
+	GetAll(ctx context.Context) ([]models.Quiz, error)
+	List(ctx context.Context, query models.QuizQuery) (*models.QuizPage, error)
+	GetFull(ctx context.Context, id string) (*models.FullQuiz, error)
+	Create(ctx context.Context, input models.QuizInput) (int64, error)
+	CreateFull(ctx context.Context, draft models.QuizDraft) (*models.CreatedQuiz, error)
+	Replace(ctx context.Context, id string, input models.QuizInput) error
+	Patch(ctx context.Context, id string, patch models.QuizPatch) error
+	Delete(ctx context.Context, id string) error
+	GetDrawRules(ctx context.Context, quizID string) ([]models.DrawRule, error)
+	ReplaceDrawRules(ctx context.Context, quizID string, rules []models.DrawRuleDraft) ([]models.DrawRule, error)
+}
+
+// BankStore manages question banks. A bank's questions are edited like any
+// other question once added.
+type BankStore interface {
+	GetAll(ctx context.Context) ([]models.Bank, error)
+	GetByID(ctx context.Context, id string) (*models.Bank, error)
+	GetQuestions(ctx context.Context, id string, query models.QuestionQuery) ([]models.FullQuestion, error)
+	Create(ctx context.Context, input models.BankInput) (int64, error)
+	AddQuestion(ctx context.Context, id string, draft models.QuestionDraft) (*models.CreatedQuestion, error)
+	Replace(ctx context.Context, id string, input models.BankInput) error
+	Delete(ctx context.Context, id string) error
+}
+
+// TagStore manages tags and which quizzes and questions are tagged with
+// them. Tagging by name creates the tag if there is none by that name yet.
+type TagStore interface {
+	GetAll(ctx context.Context) ([]models.TagUsage, error)
+	GetByID(ctx context.Context, id string) (*models.Tag, error)
+	Create(ctx context.Context, input models.TagInput) (int64, error)
+	Replace(ctx context.Context, id string, input models.TagInput) error
+	Delete(ctx context.Context, id string) error
+	GetQuizTags(ctx context.Context, quizID string) ([]models.Tag, error)
+	TagQuiz(ctx context.Context, quizID string, name string) (*models.Tag, error)
+	UntagQuiz(ctx context.Context, quizID string, tagID string) error
+	GetQuestionTags(ctx context.Context, questionID string) ([]models.Tag, error)
+	TagQuestion(ctx context.Context, questionID string, name string) (*models.Tag, error)
+	UntagQuestion(ctx context.Context, questionID string, tagID string) error
+}
+
+// ObjectiveStore manages learning objectives, which questions they are
+// assessed by, and how well learners have met them.
+type ObjectiveStore interface {
+	GetAll(ctx context.Context, framework string) ([]models.Objective, error)
+	GetByID(ctx context.Context, id string) (*models.Objective, error)
+	Create(ctx context.Context, input models.ObjectiveInput) (int64, error)
+	Replace(ctx context.Context, id string, input models.ObjectiveInput) error
+	Delete(ctx context.Context, id string) error
+	GetQuestionObjectives(ctx context.Context, questionID string) ([]models.Objective, error)
+	ReplaceQuestionObjectives(ctx context.Context, questionID string, objectiveIDs []int) ([]models.Objective, error)
+	Mastery(ctx context.Context, userID string, query models.MasteryQuery) ([]models.Mastery, error)
+}
+
+// QuestionStore manages questions with their hints, whether they belong to
+// a quiz or a bank, and their order within a quiz.
+type QuestionStore interface {
+	GetByID(ctx context.Context, id string) (*models.Question, error)
+	GetByQuizID(ctx context.Context, quizID string, query models.QuestionQuery) ([]models.Question, error)
+	Create(ctx context.Context, input models.QuestionInput) (int64, error)
+	CreateWithAnswers(ctx context.Context, quizID string, draft models.QuestionDraft) (*models.CreatedQuestion, error)
+	Reorder(ctx context.Context, quizID string, questionIDs []int64) error
+	GetHints(ctx context.Context, questionID string) ([]models.Hint, error)
+	ReplaceHints(ctx context.Context, questionID string, hints []models.HintDraft) ([]models.Hint, error)
+	Replace(ctx context.Context, id string, input models.QuestionInput) error
+	Patch(ctx context.Context, id string, patch models.QuestionPatch) error
+	PatchWithHints(ctx context.Context, id string, patch models.QuestionPatch, hints []models.HintDraft) error
+	Delete(ctx context.Context, id string) error
+}
+
+// AnswerStore manages the answer options of questions. Changes are checked
+// against the rules of the question's type.
+type AnswerStore interface {
+	GetByID(ctx context.Context, id string) (*models.Answer, error)
+	GetByQuestionID(ctx context.Context, questionID string) ([]models.Answer, error)
+	Create(ctx context.Context, input models.AnswerInput) (int64, error)
+	Replace(ctx context.Context, id string, input models.AnswerInput) error
+	Patch(ctx context.Context, id string, patch models.AnswerPatch) error
+	Delete(ctx context.Context, id string) error
+}
+
+// AttemptStore runs attempts at quizzes, from the questions they are given
+// through the responses recorded to grading on submit.
+type AttemptStore interface {
+	Start(ctx context.Context, quizID string, start models.AttemptStart) (*models.Attempt, error)
+	GetByID(ctx context.Context, quizID string, attemptID string) (*models.Attempt, error)
+	GetQuestions(ctx context.Context, quizID string, attemptID string) ([]models.FullQuestion, error)
+	GetByQuizID(ctx context.Context, quizID string, userID string) ([]models.Attempt, error)
+	RecordResponse(ctx context.Context, quizID string, attemptID string, response models.AttemptResponse) error
+	Submit(ctx context.Context, quizID string, attemptID string, responses []models.AttemptResponse) (*models.Attempt, error)
+	RevealHint(ctx context.Context, quizID string, attemptID string, questionID int) (*models.RevealedHint, error)
+	Next(ctx context.Context, quizID string, attemptID string) (*models.AdaptiveStep, error)
+}
+
+// AdaptiveStore manages which quizzes are adaptive and the calibration of
+// the questions they ask. AttemptStore delivers adaptive attempts.
+type AdaptiveStore interface {
+	GetSettings(ctx context.Context, quizID string) (*models.AdaptiveSettings, error)
+	ReplaceSettings(ctx context.Context, quizID string, settings models.AdaptiveSettings) (*models.AdaptiveSettings, error)
+	DeleteSettings(ctx context.Context, quizID string) error
+	GetCalibrations(ctx context.Context, quizID string) ([]models.Calibration, error)
+	Calibrate(ctx context.Context, quizID string) ([]models.Calibration, error)
+}
+
+// StatsStore reads the item analysis of questions, which AttemptStore.Submit
+// keeps up to date.
+type StatsStore interface {
+	GetQuestionStats(ctx context.Context, questionID string) (*models.ItemStats, error)
+	GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error)
+}
+
+// ReviewStore reads learners' spaced-repetition schedules, which
+// AttemptStore.Submit keeps up to date.
+type ReviewStore interface {
+	GetDue(ctx context.Context, userID string, query models.ReviewQuery) ([]models.DueReview, error)
+}
+
+// SearchStore finds quizzes, questions and answers by their text.
+type SearchStore interface {
+	Search(ctx context.Context, query models.SearchQuery) ([]models.SearchHit, error)
+}
+
+var (
+	_ QuizStore      = (*QuizRepository)(nil)
+	_ BankStore      = (*BankRepository)(nil)
+	_ TagStore       = (*TagRepository)(nil)
+	_ ObjectiveStore = (*ObjectiveRepository)(nil)
+	_ QuestionStore  = (*QuestionRepository)(nil)
+	_ AnswerStore    = (*AnswerRepository)(nil)
+	_ AttemptStore   = (*AttemptRepository)(nil)
+	_ AdaptiveStore  = (*AdaptiveRepository)(nil)
+	_ StatsStore     = (*StatsRepository)(nil)
+	_ ReviewStore    = (*ReviewRepository)(nil)
+	_ SearchStore    = (*SearchRepository)(nil)
+)
+
+// Repositories bundles one store of each kind. The API and the MCP server are
+// wired from it, so either can run on Postgres or in memory.
+type Repositories struct {
+	Quizzes    QuizStore
+	Banks      BankStore
+	Tags       TagStore
+	Objectives ObjectiveStore
+	Questions  QuestionStore
+	Answers    AnswerStore
+	Attempts   AttemptStore
+	Adaptive   AdaptiveStore
+	Stats      StatsStore
+	Reviews    ReviewStore
+	Search     SearchStore
+}
+
+// NewRepositories returns the Postgres-backed stores, all configured with
+// opts.
+func NewRepositories(db *sqlx.DB, opts ...Option) Repositories {
+	return Repositories{
+		Quizzes:    NewQuizRepository(db, opts...),
+		Banks:      NewBankRepository(db, opts...),
+		Tags:       NewTagRepository(db, opts...),
+		Objectives: NewObjectiveRepository(db, opts...),
+		Questions:  NewQuestionRepository(db, opts...),
+		Answers:    NewAnswerRepository(db, opts...),
+		Attempts:   NewAttemptRepository(db, opts...),
+		Adaptive:   NewAdaptiveRepository(db, opts...),
+		Stats:      NewStatsRepository(db, opts...),
+		Reviews:    NewReviewRepository(db, opts...),
+		Search:     NewSearchRepository(db, opts...),
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/review"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const reviewColumns = `user_id, question_id, repetitions, interval_days, ease, lapses, reviews, last_quality,
+	last_reviewed_at, due_at`
+
+// ReviewRepository reads the spaced-repetition schedules that
+// AttemptRepository.Submit keeps up to date.
+type ReviewRepository struct {
+	db        *sqlx.DB
+	timeout   time.Duration
+	scheduler review.Scheduler
+}
+
+func NewReviewRepository(db *sqlx.DB, opts ...Option) *ReviewRepository {
+	o := newOptions(opts)
+	return &ReviewRepository{db: db, timeout: o.queryTimeout, scheduler: review.NewScheduler(o.clock)}
+}
+
+// GetDue returns the questions a learner is due to review, from every quiz,
+// the longest overdue first.
+func (r *ReviewRepository) GetDue(ctx context.Context, userID string, query models.ReviewQuery) ([]models.DueReview, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, readSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var cards []models.ReviewCard
+	err = tx.SelectContext(ctx, &cards,
+		"SELECT "+reviewColumns+" FROM review_cards WHERE user_id = $1 AND due_at <= $2 ORDER BY due_at, question_id LIMIT $3",
+		userID, r.scheduler.Now(), PageSize(query.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	questionIDs := make([]int, 0, len(cards))
+	for _, card := range cards {
+		questionIDs = append(questionIDs, card.QuestionID)
+	}
+	questions, err := loadFullQuestions(ctx, tx, questionsByID, pq.Array(questionIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return DueReviews(cards, questions), nil
+}
+
+// DueReviews pairs due cards with their questions, in the cards' order.
+func DueReviews(cards []models.ReviewCard, questions []models.FullQuestion) []models.DueReview {
+	byID := make(map[int]models.FullQuestion, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+
+	due := make([]models.DueReview, 0, len(cards))
+	for _, card := range cards {
+		question := byID[card.QuestionID]
+		due = append(due, models.DueReview{
+			ReviewCard: card,
+			QuizID:     question.QuizID,
+			Question:   models.NewPlayerQuestion(question),
+		})
+	}
+	return due
+}
+
+// recordReviews reviews every question of an attempt for the learner who
+// submitted it, graded by how the attempt did on it. Anonymous attempts have
+// no schedule to keep.
+func recordReviews(ctx context.Context, tx *sqlx.Tx, scheduler review.Scheduler, userID string, outcomes map[int]analytics.Outcome) error {
+	if userID == "" {
+		return nil
+	}
+
+	questionIDs := make([]int, 0, len(outcomes))
+	for questionID := range outcomes {
+		questionIDs = append(questionIDs, questionID)
+	}
+	sort.Ints(questionIDs)
+
+	for _, questionID := range questionIDs {
+		card := models.ReviewCard{UserID: userID, QuestionID: questionID}
+		err := tx.GetContext(ctx, &card,
+			"SELECT "+reviewColumns+" FROM review_cards WHERE user_id = $1 AND question_id = $2 FOR UPDATE",
+			userID, questionID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		outcome := outcomes[questionID]
+		card = scheduler.Review(card, review.Quality(outcome.Answered, outcome.Points))
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO review_cards (`+reviewColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (user_id, question_id) DO UPDATE SET
+				repetitions = EXCLUDED.repetitions,
+				interval_days = EXCLUDED.interval_days,
+				ease = EXCLUDED.ease,
+				lapses = EXCLUDED.lapses,
+				reviews = EXCLUDED.reviews,
+				last_quality = EXCLUDED.last_quality,
+				last_reviewed_at = EXCLUDED.last_reviewed_at,
+				due_at = EXCLUDED.due_at`,
+			card.UserID, card.QuestionID, card.Repetitions, card.IntervalDays, card.Ease, card.Lapses,
+			card.Reviews, card.LastQuality, card.LastReviewedAt, card.DueAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// Due reviews are those due by the repository's clock, not the database's.
+func TestReviewRepositoryGetDueUsesClock(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	db, mock := newMockDB(t)
+	columns := []string{"user_id", "question_id", "repetitions", "interval_days", "ease", "lapses", "reviews",
+		"last_quality", "last_reviewed_at", "due_at"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM review_cards WHERE user_id = $1 AND due_at <= $2")).
+		WithArgs("ada", now, 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("ada", 7, 0, 1, 1.96, 1, 3, 1, now.Add(-24*time.Hour), now))
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE q.id = ANY($1)")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quiz_id", "bank_id", "question", "type", "scoring", "order_num", "config", "explanation"}).
+			AddRow(7, 1, nil, "Capital of France?", "multiple_choice", "all_or_nothing", 1, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM answers a")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id", "answer", "is_correct", "position", "match_text", "rationale"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_hints h")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "question_id", "tier", "hint", "penalty"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM question_tags")).
+		WillReturnRows(sqlmock.NewRows([]string{"question_id", "name"}))
+	mock.ExpectCommit()
+
+	due, err := NewReviewRepository(db, WithClock(fixedClock(now))).GetDue(context.Background(), "ada", models.ReviewQuery{})
+	if err != nil {
+		t.Fatalf("GetDue returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(due) != 1 || due[0].Question.Question != "Capital of France?" || due[0].Lapses != 1 ||
+		due[0].QuizID == nil || *due[0].QuizID != 1 {
+		t.Errorf("due = %+v, want question 7 with its card", due)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type SearchRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewSearchRepository(db *sqlx.DB, opts ...Option) *SearchRepository {
+	return &SearchRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// searchQuery ranks matches from all three tables together, keeps the best
+// ones, and only then builds headlines, which are expensive, for the page
+// being returned. Bodies are authored text, so they are HTML-escaped before
+// ts_headline adds its <mark> tags; the parser reads the escapes as entities
+// and leaves them alone.
+const searchQuery = `WITH q AS (
+	SELECT websearch_to_tsquery('english', $1) AS query
+), hits AS (
+	SELECT 'quiz' AS kind, z.id, z.id AS quiz_id, NULL::int AS bank_id, NULL::int AS question_id,
+		z.title || ' ' || coalesce(z.description, '') AS body,
+		ts_rank(z.search, q.query) AS rank
+	FROM quizzes z CROSS JOIN q
+	WHERE z.search @@ q.query
+	UNION ALL
+	SELECT 'question', qu.id, qu.quiz_id, qu.bank_id, qu.id, qu.question, ts_rank(qu.search, q.query)
+	FROM questions qu CROSS JOIN q
+	WHERE qu.search @@ q.query
+	UNION ALL
+	SELECT 'answer', a.id, qu.quiz_id, qu.bank_id, qu.id, a.answer, ts_rank(a.search, q.query)
+	FROM answers a JOIN questions qu ON qu.id = a.question_id CROSS JOIN q
+	WHERE a.search @@ q.query
+	ORDER BY rank DESC, kind, id
+	LIMIT $2
+)
+SELECT h.kind, h.id, h.quiz_id, z.title AS quiz_title, h.bank_id, h.question_id,
+	ts_headline('english', replace(replace(replace(replace(h.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), q.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2') AS snippet,
+	h.rank
+FROM hits h LEFT JOIN quizzes z ON z.id = h.quiz_id CROSS JOIN q
+ORDER BY h.rank DESC, h.kind, h.id`
+
+// Search finds quizzes, questions and answers matching query.Q, best match
+// first. Questions and answers in banks are found too.
+func (r *SearchRepository) Search(ctx context.Context, query models.SearchQuery) ([]models.SearchHit, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if strings.TrimSpace(query.Q) == "" {
+		return nil, Invalid("q", "is required")
+	}
+
+	hits := []models.SearchHit{}
+	err := r.db.SelectContext(ctx, &hits, searchQuery, query.Q, PageSize(query.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
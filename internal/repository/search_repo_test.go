@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestSearchRepositorySearch(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`replace(replace(replace(replace(h.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`)+
+		`(?s).*`+regexp.QuoteMeta("LEFT JOIN quizzes z")).
+		WithArgs(`"content on hover"`, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "quiz_id", "quiz_title", "bank_id", "question_id", "snippet", "rank"}).
+			AddRow("question", 4, 1, "WCAG 2.2", nil, 4, "<mark>Content</mark> on <mark>Hover</mark>", 0.6).
+			AddRow("answer", 9, nil, nil, 2, 8, "<mark>Content</mark> on <mark>Hover</mark> or Focus", 0.4).
+			AddRow("quiz", 1, 1, "WCAG 2.2", nil, nil, "WCAG 2.2 <mark>hover</mark>", 0.2))
+
+	hits, err := NewSearchRepository(db).Search(context.Background(), models.SearchQuery{Q: `"content on hover"`, Limit: 5})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(hits) != 3 || hits[0].Kind != models.SearchHitQuestion || hits[0].QuestionID == nil || *hits[0].QuestionID != 4 {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if bank := hits[1]; bank.QuizID != nil || bank.QuizTitle != nil || bank.BankID == nil || *bank.BankID != 2 {
+		t.Errorf("bank answer hit = %+v, want bank 2 and no quiz", bank)
+	}
+	if hits[2].QuestionID != nil {
+		t.Errorf("quiz hit should have no question_id, got %d", *hits[2].QuestionID)
+	}
+}
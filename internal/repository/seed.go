@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// CreateQuiz inserts a quiz over a plain database/sql connection. It is used
+// by the seeder, which does not go through sqlx.
+func CreateQuiz(db *sql.DB, quiz *models.Quiz) (int64, error) {
+	var quizID int64
+	err := db.QueryRow(
+		"INSERT INTO quizzes (title, description) VALUES ($1, $2) RETURNING id",
+		quiz.Title, quiz.Description,
+	).Scan(&quizID)
+	if err != nil {
+		return 0, err
+	}
+
+	return quizID, nil
+}
+
+// TagQuiz tags a quiz over a plain database/sql connection, creating the
+// tag if there is none by that name yet.
+func TagQuiz(db *sql.DB, quizID int64, name string) error {
+	_, err := db.Exec(
+		upsertTag+" INSERT INTO quiz_tags (quiz_id, tag_id) SELECT $1, id FROM tag ON CONFLICT DO NOTHING",
+		quizID, name,
+	)
+	return err
+}
+
+// CreateQuestion inserts a question over a plain database/sql connection.
+func CreateQuestion(db *sql.DB, question *models.Question) (int64, error) {
+	var questionID int64
+	err := db.QueryRow(
+		"INSERT INTO questions (quiz_id, question, type, order_num) VALUES ($1, $2, $3, $4) RETURNING id",
+		question.QuizID, question.Question, question.Type, question.Order,
+	).Scan(&questionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return questionID, nil
+}
+
+// CreateAnswer inserts an answer over a plain database/sql connection.
+func CreateAnswer(db *sql.DB, answer *models.Answer) error {
+	_, err := db.Exec(
+		"INSERT INTO answers (question_id, answer, is_correct) VALUES ($1, $2, $3)",
+		answer.QuestionID, answer.Answer, answer.Correct,
+	)
+	return err
+}
+
+// CreateObjective inserts a learning objective over a plain database/sql
+// connection.
+func CreateObjective(db *sql.DB, objective *models.Objective) (int64, error) {
+	var objectiveID int64
+	err := db.QueryRow(
+		"INSERT INTO objectives (framework, code, title, description) VALUES ($1, $2, $3, $4) RETURNING id",
+		objective.Framework, objective.Code, objective.Title, objective.Description,
+	).Scan(&objectiveID)
+	if err != nil {
+		return 0, err
+	}
+
+	return objectiveID, nil
+}
+
+// LinkObjective records that a question assesses an objective over a plain
+// database/sql connection.
+func LinkObjective(db *sql.DB, questionID int64, objectiveID int64) error {
+	_, err := db.Exec(
+		"INSERT INTO question_objectives (question_id, objective_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		questionID, objectiveID,
+	)
+	return err
+}
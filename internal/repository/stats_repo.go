@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/analytics"
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// tallyColumns sums the item_stats rows s of a question, so the same scan
+// reads a question's totals for one quiz or for all of them.
+const tallyColumns = `COALESCE(SUM(s.attempts), 0), COALESCE(SUM(s.responses), 0), COALESCE(SUM(s.correct), 0),
+	COALESCE(SUM(s.paired), 0), COALESCE(SUM(s.paired_correct), 0), COALESCE(SUM(s.rest_sum), 0),
+	COALESCE(SUM(s.rest_squares), 0), COALESCE(SUM(s.correct_rest), 0), COALESCE(SUM(s.timed), 0),
+	COALESCE(SUM(s.time_ms), 0)`
+
+// StatsRepository reads the item analysis totals that AttemptRepository.Submit
+// keeps up to date.
+type StatsRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewStatsRepository(db *sqlx.DB, opts ...Option) *StatsRepository {
+	return &StatsRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// GetQuestionStats returns the item analysis of a question over every quiz
+// it has been given in.
+func (r *StatsRepository) GetQuestionStats(ctx context.Context, questionID string) (*models.ItemStats, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	items, err := r.loadItems(ctx,
+		`SELECT q.id, q.question, q.type, `+tallyColumns+`
+		FROM questions q
+		LEFT JOIN item_stats s ON s.question_id = q.id
+		WHERE q.id = $1
+		GROUP BY q.id`,
+		0, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, NotFound("question")
+	}
+
+	return &items[0], nil
+}
+
+// GetQuizStats returns the item analysis of every question given in a
+// quiz's attempts, and of its own questions not yet given in any.
+func (r *StatsRepository) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	stats := &models.QuizStats{}
+	var tally analytics.QuizTally
+	err := r.db.QueryRowContext(ctx,
+		`SELECT q.id, COALESCE(s.attempts, 0), COALESCE(s.score_shares, 0)
+		FROM quizzes q LEFT JOIN quiz_stats s ON s.quiz_id = q.id
+		WHERE q.id = $1`,
+		quizID,
+	).Scan(&stats.QuizID, &tally.Attempts, &tally.ScoreShares)
+	if err != nil {
+		return nil, dbError(err, "quiz")
+	}
+	tally.Describe(stats)
+
+	// The quiz's own questions and those with totals in it are found by
+	// index apart, rather than with an OR across the join that would scan
+	// every question. Bank questions have no quiz_id, so they follow the
+	// quiz's own.
+	stats.Questions, err = r.loadItems(ctx,
+		`SELECT q.id, q.question, q.type, `+tallyColumns+`
+		FROM (
+			SELECT id FROM questions WHERE quiz_id = $1
+			UNION
+			SELECT question_id FROM item_stats WHERE quiz_id = $1
+		) given
+		JOIN questions q ON q.id = given.id
+		LEFT JOIN item_stats s ON s.question_id = q.id AND s.quiz_id = $1
+		GROUP BY q.id
+		ORDER BY q.quiz_id IS NULL, q.order_num, q.id`,
+		stats.QuizID, stats.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// loadItems runs a query for questions and their totals, then loads the
+// selections of their answers, counted only in quizID unless it is zero.
+func (r *StatsRepository) loadItems(ctx context.Context, query string, quizID int, params ...interface{}) ([]models.ItemStats, error) {
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ItemStats{}
+	var tallies []analytics.Tally
+	for rows.Next() {
+		var item models.ItemStats
+		var t analytics.Tally
+		err := rows.Scan(&item.QuestionID, &item.Question, &item.Type,
+			&t.Attempts, &t.Responses, &t.Correct, &t.Paired, &t.PairedCorrect,
+			&t.RestSum, &t.RestSquares, &t.CorrectRest, &t.Timed, &t.TimeMs)
+		if err != nil {
+			return nil, err
+		}
+		item.Answers = []models.AnswerStats{}
+		items = append(items, item)
+		tallies = append(tallies, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	index := make(map[int]int, len(items))
+	questionIDs := make([]int64, len(items))
+	for i, item := range items {
+		index[item.QuestionID] = i
+		questionIDs[i] = int64(item.QuestionID)
+	}
+
+	answers, err := r.db.QueryContext(ctx,
+		`SELECT a.question_id, a.id, a.answer, a.is_correct, COALESCE(SUM(s.selections), 0)
+		FROM answers a
+		LEFT JOIN answer_stats s ON s.answer_id = a.id AND ($2 = 0 OR s.quiz_id = $2)
+		WHERE a.question_id = ANY($1)
+		GROUP BY a.id
+		ORDER BY a.id`,
+		pq.Array(questionIDs), quizID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer answers.Close()
+
+	for answers.Next() {
+		var questionID int
+		var answer models.AnswerStats
+		if err := answers.Scan(&questionID, &answer.AnswerID, &answer.Answer, &answer.Correct, &answer.Selections); err != nil {
+			return nil, err
+		}
+		i := index[questionID]
+		items[i].Answers = append(items[i].Answers, answer)
+	}
+	if err := answers.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		tallies[i].Describe(&items[i])
+	}
+	return items, nil
+}
+
+// recordStats adds a submitted attempt to the item analysis totals of its
+// quiz, of each of its questions in that quiz, and of the answers selected.
+// Rows are updated in ID order so concurrent submits cannot deadlock.
+func recordStats(ctx context.Context, tx *sqlx.Tx, quizID string, quiz analytics.QuizTally, outcomes map[int]analytics.Outcome, selected []int64) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO quiz_stats AS s (quiz_id, attempts, score_shares) VALUES ($1, $2, $3)
+		ON CONFLICT (quiz_id) DO UPDATE SET
+			attempts = s.attempts + EXCLUDED.attempts,
+			score_shares = s.score_shares + EXCLUDED.score_shares`,
+		quizID, quiz.Attempts, quiz.ScoreShares,
+	)
+	if err != nil {
+		return err
+	}
+
+	questionIDs := make([]int, 0, len(outcomes))
+	for questionID := range outcomes {
+		questionIDs = append(questionIDs, questionID)
+	}
+	sort.Ints(questionIDs)
+
+	for _, questionID := range questionIDs {
+		t := analytics.Observe(outcomes[questionID])
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO item_stats AS s (quiz_id, question_id, attempts, responses, correct,
+				paired, paired_correct, rest_sum, rest_squares, correct_rest, timed, time_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (quiz_id, question_id) DO UPDATE SET
+				attempts = s.attempts + EXCLUDED.attempts,
+				responses = s.responses + EXCLUDED.responses,
+				correct = s.correct + EXCLUDED.correct,
+				paired = s.paired + EXCLUDED.paired,
+				paired_correct = s.paired_correct + EXCLUDED.paired_correct,
+				rest_sum = s.rest_sum + EXCLUDED.rest_sum,
+				rest_squares = s.rest_squares + EXCLUDED.rest_squares,
+				correct_rest = s.correct_rest + EXCLUDED.correct_rest,
+				timed = s.timed + EXCLUDED.timed,
+				time_ms = s.time_ms + EXCLUDED.time_ms`,
+			quizID, questionID, t.Attempts, t.Responses, t.Correct,
+			t.Paired, t.PairedCorrect, t.RestSum, t.RestSquares, t.CorrectRest, t.Timed, t.TimeMs,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil
+	}
+	answerIDs := append([]int64{}, selected...)
+	sort.Slice(answerIDs, func(i, j int) bool { return answerIDs[i] < answerIDs[j] })
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO answer_stats AS s (quiz_id, answer_id, selections)
+		SELECT $1, answer_id, 1 FROM unnest($2::int[]) AS answer_id
+		ON CONFLICT (quiz_id, answer_id) DO UPDATE SET selections = s.selections + 1`,
+		quizID, pq.Array(answerIDs),
+	)
+	return err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Reading statistics only touches the running totals. sqlmock fails the call
+// on any query beyond the ones registered here, such as one over attempts.
+func TestStatsRepositoryGetQuizStats(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM quizzes q LEFT JOIN quiz_stats s ON s.quiz_id = q.id")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "score_shares"}).AddRow(1, 4, 3.0))
+
+	columns := []string{"id", "question", "type", "attempts", "responses", "correct", "paired", "paired_correct",
+		"rest_sum", "rest_squares", "correct_rest", "timed", "time_ms"}
+	// The quiz's questions are found through indexes, not by scanning them all
+	mock.ExpectQuery(`SELECT id FROM questions WHERE quiz_id = \$1\s+UNION\s+SELECT question_id FROM item_stats WHERE quiz_id = \$1\s+\) given\s+` +
+		`JOIN questions q ON q.id = given.id\s+` + regexp.QuoteMeta("LEFT JOIN item_stats s ON s.question_id = q.id AND s.quiz_id = $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(7, "Capital of France?", "multiple_choice", 4, 4, 3, 0, 0, 0.0, 0.0, 0.0, 2, 5000).
+			AddRow(8, "Unseen", "true_false", 0, 0, 0, 0, 0, 0.0, 0.0, 0.0, 0, 0))
+
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN answer_stats s ON s.answer_id = a.id")).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"question_id", "id", "answer", "is_correct", "selections"}).
+			AddRow(7, 1, "Paris", true, 3).
+			AddRow(7, 2, "Lyon", false, 1))
+
+	stats, err := NewStatsRepository(db).GetQuizStats(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetQuizStats returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Attempts != 4 || stats.AverageScore == nil || *stats.AverageScore != 0.75 {
+		t.Errorf("quiz totals = %d attempts averaging %v, want 4 averaging 0.75", stats.Attempts, stats.AverageScore)
+	}
+	if len(stats.Questions) != 2 {
+		t.Fatalf("expected 2 questions, got %d", len(stats.Questions))
+	}
+
+	answered := stats.Questions[0]
+	if answered.PValue == nil || *answered.PValue != 0.75 || answered.AverageTimeMs == nil || *answered.AverageTimeMs != 2500 {
+		t.Errorf("question stats = %+v, want p-value 0.75 and 2500ms", answered)
+	}
+	if len(answered.Answers) != 2 || *answered.Answers[1].Rate != 0.25 {
+		t.Errorf("answer stats = %+v, want Lyon picked by a quarter", answered.Answers)
+	}
+
+	unseen := stats.Questions[1]
+	if unseen.PValue != nil || unseen.PointBiserial != nil || len(unseen.Answers) != 0 {
+		t.Errorf("unseen question stats = %+v, want no statistics", unseen)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"strings"
+)
+
+// maxTagTerms caps how many tags one expression may name, as each becomes a
+// subquery.
+const maxTagTerms = 20
+
+// TagTerm is one tag named in a tag expression. It matches anything tagged
+// with the tag or a tag beneath it, or with Not, anything that is not.
+type TagTerm struct {
+	Name string
+	Not  bool
+}
+
+// TagFilter is a parsed tag expression. Every group must match, and a group
+// matches when any of its terms does. A nil filter matches everything.
+type TagFilter [][]TagTerm
+
+// ParseTagFilter reads a tag expression such as "wcag,level-a|level-aa,!draft":
+// commas separate groups, "|" separates the terms of a group and "!" negates
+// a term.
+func ParseTagFilter(expr string) (TagFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	var filter TagFilter
+	terms := 0
+	for _, group := range strings.Split(expr, ",") {
+		var parsed []TagTerm
+		for _, term := range strings.Split(group, "|") {
+			term = strings.TrimSpace(term)
+			not := strings.HasPrefix(term, "!")
+			name := strings.TrimSpace(strings.TrimPrefix(term, "!"))
+			if name == "" {
+				return nil, Invalid("tags", "must not contain an empty tag")
+			}
+			parsed = append(parsed, TagTerm{Name: name, Not: not})
+			terms++
+		}
+		filter = append(filter, parsed)
+	}
+	if terms > maxTagTerms {
+		return nil, Invalid("tags", "must name at most 20 tags")
+	}
+
+	return filter, nil
+}
+
+// Matches reports whether something matches the filter, where tagged reports
+// whether it is tagged with the named tag or a tag beneath it.
+func (f TagFilter) Matches(tagged func(name string) bool) bool {
+	for _, group := range f {
+		matched := false
+		for _, term := range group {
+			if tagged(term.Name) != term.Not {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// condition returns SQL that holds for the rows whose owner, such as "q.id",
+// is linked through table and its column to tags matching the filter. It is
+// empty for a nil filter. param adds a query parameter and returns its
+// placeholder.
+func (f TagFilter) condition(table, column, owner string, param func(value interface{}) string) string {
+	groups := make([]string, 0, len(f))
+	for _, group := range f {
+		terms := make([]string, 0, len(group))
+		for _, term := range group {
+			exists := "EXISTS (SELECT 1 FROM " + table + " tl WHERE tl." + column + " = " + owner +
+				" AND tl.tag_id IN (" + tagTree(param(term.Name)) + "))"
+			if term.Not {
+				exists = "NOT " + exists
+			}
+			terms = append(terms, exists)
+		}
+		groups = append(groups, "("+strings.Join(terms, " OR ")+")")
+	}
+	return strings.Join(groups, " AND ")
+}
+
+// tagTree selects the IDs of the tag named by the placeholder name and every
+// tag beneath it.
+func tagTree(name string) string {
+	return "WITH RECURSIVE tree(id) AS (SELECT id FROM tags WHERE name = " + name +
+		" UNION SELECT t.id FROM tags t JOIN tree ON t.parent_id = tree.id) SELECT id FROM tree"
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	filter, err := ParseTagFilter(" wcag , level-a|level-aa, !draft ")
+	if err != nil {
+		t.Fatalf("ParseTagFilter failed: %v", err)
+	}
+
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"wcag", "level-a"}, true},
+		{[]string{"wcag", "level-aa"}, true},
+		{[]string{"wcag"}, false},
+		{[]string{"wcag", "level-a", "draft"}, false},
+		{[]string{"level-a"}, false},
+	}
+	for _, tt := range tests {
+		tagged := func(name string) bool {
+			for _, tag := range tt.tags {
+				if tag == name {
+					return true
+				}
+			}
+			return false
+		}
+		if got := filter.Matches(tagged); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"wcag,", "a||b", "!"} {
+		if _, err := ParseTagFilter(expr); err == nil {
+			t.Errorf("ParseTagFilter(%q) succeeded, want an error", expr)
+		}
+	}
+	if filter, err := ParseTagFilter(""); filter != nil || err != nil {
+		t.Errorf("ParseTagFilter(\"\") = %v, %v; want a nil filter", filter, err)
+	}
+}
+
+func TestTagFilterCondition(t *testing.T) {
+	filter, err := ParseTagFilter("a|!b,c")
+	if err != nil {
+		t.Fatalf("ParseTagFilter failed: %v", err)
+	}
+
+	var params []interface{}
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+
+	tree := func(n string) string {
+		return " AND tl.tag_id IN (WITH RECURSIVE tree(id) AS (SELECT id FROM tags WHERE name = $" + n +
+			" UNION SELECT t.id FROM tags t JOIN tree ON t.parent_id = tree.id) SELECT id FROM tree))"
+	}
+	exists := "EXISTS (SELECT 1 FROM quiz_tags tl WHERE tl.quiz_id = z.id"
+	want := "(" + exists + tree("1") + " OR NOT " + exists + tree("2") + ") AND (" + exists + tree("3") + ")"
+	if got := filter.condition("quiz_tags", "quiz_id", "z.id", param); got != want {
+		t.Errorf("condition =\n%s\nwant\n%s", got, want)
+	}
+	if len(params) != 3 || params[0] != "a" || params[1] != "b" || params[2] != "c" {
+		t.Errorf("params = %v", params)
+	}
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// upsertTag starts a statement with a CTE named tag that holds the tag named
+// $2, creating it if there is none by that name yet.
+const upsertTag = `WITH tag AS (
+	INSERT INTO tags (name) VALUES ($2)
+	ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+	RETURNING id, name, parent_id
+)`
+
+// tagTarget is what tags are attached to: the join table, its column for the
+// tagged row, the table of tagged rows, and the resource named in errors.
+type tagTarget struct {
+	table    string
+	column   string
+	owners   string
+	resource string
+}
+
+var (
+	quizTagTarget     = tagTarget{table: "quiz_tags", column: "quiz_id", owners: "quizzes", resource: "quiz"}
+	questionTagTarget = tagTarget{table: "question_tags", column: "question_id", owners: "questions", resource: "question"}
+)
+
+type TagRepository struct {
+	db      *sqlx.DB
+	timeout time.Duration
+}
+
+func NewTagRepository(db *sqlx.DB, opts ...Option) *TagRepository {
+	return &TagRepository{db: db, timeout: newOptions(opts).queryTimeout}
+}
+
+// GetAll returns every tag by name, with how many quizzes and questions are
+// tagged with it directly.
+func (r *TagRepository) GetAll(ctx context.Context) ([]models.TagUsage, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tags := []models.TagUsage{}
+	err := r.db.SelectContext(ctx, &tags,
+		`SELECT t.id, t.name, t.parent_id,
+			(SELECT COUNT(*) FROM quiz_tags qt WHERE qt.tag_id = t.id) AS quizzes,
+			(SELECT COUNT(*) FROM question_tags qt WHERE qt.tag_id = t.id) AS questions
+		FROM tags t
+		ORDER BY t.name`)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id string) (*models.Tag, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag := &models.Tag{}
+	err := r.db.GetContext(ctx, tag, "SELECT id, name, parent_id FROM tags WHERE id = $1", id)
+	if err != nil {
+		return nil, dbError(err, "tag")
+	}
+
+	return tag, nil
+}
+
+func (r *TagRepository) Create(ctx context.Context, input models.TagInput) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	name, err := CheckTagName("name", input.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	var tagID int64
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO tags (name, parent_id) VALUES ($1, $2) RETURNING id",
+		name, input.ParentID,
+	).Scan(&tagID)
+	if err != nil {
+		return 0, dbError(err, "tag")
+	}
+
+	return tagID, nil
+}
+
+// Replace renames a tag and moves it under another parent, or to the top
+// level without one. A tag cannot be moved beneath itself.
+func (r *TagRepository) Replace(ctx context.Context, id string, input models.TagInput) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	name, err := CheckTagName("name", input.Name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Stop concurrent moves from making a cycle between them
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE tags IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+
+	if input.ParentID != nil {
+		var cycle bool
+		err := tx.QueryRowContext(ctx,
+			`WITH RECURSIVE tree(id) AS (
+				SELECT id FROM tags WHERE id = $1
+				UNION SELECT t.id FROM tags t JOIN tree ON t.parent_id = tree.id
+			)
+			SELECT EXISTS (SELECT 1 FROM tree WHERE id = $2)`,
+			id, *input.ParentID,
+		).Scan(&cycle)
+		if err != nil {
+			return dbError(err, "tag")
+		}
+		if cycle {
+			return Invalid("parent_id", "must not be the tag itself or a tag beneath it")
+		}
+	}
+
+	query, params, err := newUpdateBuilder("tags").
+		Set("name", name).
+		Set("parent_id", input.ParentID).
+		Build(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, params...)
+	if err := requireRow(result, err, "tag"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a tag from everything tagged with it. Tags beneath it move
+// to the top level.
+func (r *TagRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tags WHERE id = $1", id)
+	return requireRow(result, err, "tag")
+}
+
+func (r *TagRepository) GetQuizTags(ctx context.Context, quizID string) ([]models.Tag, error) {
+	return r.getAttached(ctx, quizTagTarget, quizID)
+}
+
+func (r *TagRepository) TagQuiz(ctx context.Context, quizID string, name string) (*models.Tag, error) {
+	return r.attach(ctx, quizTagTarget, quizID, name)
+}
+
+func (r *TagRepository) UntagQuiz(ctx context.Context, quizID string, tagID string) error {
+	return r.detach(ctx, quizTagTarget, quizID, tagID)
+}
+
+func (r *TagRepository) GetQuestionTags(ctx context.Context, questionID string) ([]models.Tag, error) {
+	return r.getAttached(ctx, questionTagTarget, questionID)
+}
+
+func (r *TagRepository) TagQuestion(ctx context.Context, questionID string, name string) (*models.Tag, error) {
+	return r.attach(ctx, questionTagTarget, questionID, name)
+}
+
+func (r *TagRepository) UntagQuestion(ctx context.Context, questionID string, tagID string) error {
+	return r.detach(ctx, questionTagTarget, questionID, tagID)
+}
+
+// getAttached returns the tags on one quiz or question by name.
+func (r *TagRepository) getAttached(ctx context.Context, target tagTarget, id string) ([]models.Tag, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.requireOwner(ctx, target, id); err != nil {
+		return nil, err
+	}
+
+	tags := []models.Tag{}
+	err := r.db.SelectContext(ctx, &tags,
+		"SELECT t.id, t.name, t.parent_id FROM tags t JOIN "+target.table+" tl ON tl.tag_id = t.id WHERE tl."+target.column+" = $1 ORDER BY t.name",
+		id)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// attach tags a quiz or question with the named tag, creating the tag if
+// needed. Tagging twice with the same tag changes nothing.
+func (r *TagRepository) attach(ctx context.Context, target tagTarget, id string, name string) (*models.Tag, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	name, err := CheckTagName("tag", name)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.requireOwner(ctx, target, id); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{}
+	err = r.db.GetContext(ctx, tag,
+		upsertTag+`, link AS (
+			INSERT INTO `+target.table+` (`+target.column+`, tag_id) SELECT $1, id FROM tag
+			ON CONFLICT DO NOTHING
+		)
+		SELECT id, name, parent_id FROM tag`,
+		id, name)
+	if err != nil {
+		return nil, dbError(err, target.resource)
+	}
+
+	return tag, nil
+}
+
+// detach removes a tag from a quiz or question. A tag it does not have is
+// not found.
+func (r *TagRepository) detach(ctx context.Context, target tagTarget, id string, tagID string) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.requireOwner(ctx, target, id); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM "+target.table+" WHERE "+target.column+" = $1 AND tag_id = $2",
+		id, tagID)
+	return requireRow(result, err, "tag")
+}
+
+func (r *TagRepository) requireOwner(ctx context.Context, target tagTarget, id string) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+target.owners+" WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return dbError(err, target.resource)
+	}
+	if !exists {
+		return NotFound(target.resource)
+	}
+	return nil
+}
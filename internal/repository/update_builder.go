@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// updateBuilder assembles a parameterised UPDATE statement for the columns a
+// caller chooses to change. Placeholders are numbered in the order columns
+// are set, with the WHERE clause's ID last.
+type updateBuilder struct {
+	table   string
+	columns []string
+	params  []interface{}
+}
+
+func newUpdateBuilder(table string) *updateBuilder {
+	return &updateBuilder{table: table}
+}
+
+// Set adds column = value to the statement. A nil value sets the column to
+// NULL.
+func (b *updateBuilder) Set(column string, value interface{}) *updateBuilder {
+	b.columns = append(b.columns, column)
+	b.params = append(b.params, value)
+	return b
+}
+
+// Empty reports whether no columns have been set.
+func (b *updateBuilder) Empty() bool {
+	return len(b.columns) == 0
+}
+
+// Build returns the statement and its parameters for the row with the given
+// ID. It fails if no columns were set.
+func (b *updateBuilder) Build(id interface{}) (string, []interface{}, error) {
+	if b.Empty() {
+		return "", nil, Invalid("", "no valid fields to update")
+	}
+
+	var query strings.Builder
+	query.WriteString("UPDATE " + b.table + " SET ")
+	for i, column := range b.columns {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(column + " = $" + strconv.Itoa(i+1))
+	}
+	query.WriteString(" WHERE id = $" + strconv.Itoa(len(b.columns)+1))
+
+	params := make([]interface{}, 0, len(b.params)+1)
+	params = append(params, b.params...)
+	params = append(params, id)
+
+	return query.String(), params, nil
+}
+
+// setOptional adds a patch field to the statement if it was present in the
+// request. Null is only accepted for nullable columns.
+func setOptional[T any](b *updateBuilder, column string, field models.Optional[T], nullable bool) error {
+	if !field.Set {
+		return nil
+	}
+
+	if field.Null {
+		if !nullable {
+			return Invalid(column, "cannot be null")
+		}
+		b.Set(column, nil)
+		return nil
+	}
+
+	b.Set(column, field.Value)
+	return nil
+}
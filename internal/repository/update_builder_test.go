@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUpdateBuilderNumbersPlaceholders(t *testing.T) {
+	// Placeholders used to be built from a single rune and broke past $9.
+	for n := 1; n <= 12; n++ {
+		builder := newUpdateBuilder("quizzes")
+		var sets []string
+		for i := 1; i <= n; i++ {
+			builder.Set(fmt.Sprintf("col%d", i), i)
+			sets = append(sets, fmt.Sprintf("col%d = $%d", i, i))
+		}
+
+		query, params, err := builder.Build("42")
+		if err != nil {
+			t.Fatalf("n=%d: unexpected error: %v", n, err)
+		}
+
+		want := fmt.Sprintf("UPDATE quizzes SET %s WHERE id = $%d", strings.Join(sets, ", "), n+1)
+		if query != want {
+			t.Errorf("n=%d:\n got %s\nwant %s", n, query, want)
+		}
+		if len(params) != n+1 || params[n] != "42" {
+			t.Errorf("n=%d: unexpected params %v", n, params)
+		}
+	}
+}
+
+func TestUpdateBuilderRequiresAColumn(t *testing.T) {
+	if _, _, err := newUpdateBuilder("quizzes").Build("1"); err == nil {
+		t.Fatal("expected an error when no columns are set")
+	}
+}
@@ -0,0 +1,134 @@
+// Package review schedules spaced-repetition reviews of questions with the
+// SM-2 algorithm. Each submitted attempt reviews every question it was
+// given: a pass pushes the question's next review further out, a failure
+// brings it back the next day. Time comes from a Clock, never the system
+// clock directly, so tests can move it. It holds no storage code, so every
+// repository implementation schedules the same way.
+package review
+
+import (
+	"math"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// SM-2 grades a review from 0, a blackout, to 5, a perfect recall. Grades
+// from PassingQuality up count as remembered.
+const (
+	PassingQuality = 3
+	MaxQuality     = 5
+)
+
+// Every card starts with InitialEase, and poor reviews never lower it below
+// MinEase.
+const (
+	InitialEase = 2.5
+	MinEase     = 1.3
+)
+
+const day = 24 * time.Hour
+
+// Clock tells the time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the real time.
+var SystemClock Clock = systemClock{}
+
+// Quality grades how a submitted attempt did on a question from whether it
+// was answered and the points it earned after hint penalties: 5 for full
+// marks, down to 1 for none, and 0 when there was no response at all.
+// Partial credit and hints lower the grade, so leaning on hints to get a
+// question right still brings it back sooner.
+func Quality(answered bool, points float64) int {
+	switch {
+	case !answered:
+		return 0
+	case points >= 1:
+		return 5
+	case points >= 0.75:
+		return 4
+	case points >= 0.5:
+		return 3
+	case points > 0:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Scheduler reviews cards against a clock.
+type Scheduler struct {
+	clock Clock
+}
+
+// NewScheduler returns a scheduler that reads the time from clock, or from
+// SystemClock when it is nil.
+func NewScheduler(clock Clock) Scheduler {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return Scheduler{clock: clock}
+}
+
+// Now is the time by the scheduler's clock, in UTC.
+func (s Scheduler) Now() time.Time {
+	return s.clock.Now().UTC()
+}
+
+// Review records a review of card graded quality, now. A card never reviewed
+// before is new. A pass lengthens the interval to 1 day, then 6, then by the
+// ease each time; a failure is a lapse that starts the repetitions over with
+// a 1 day interval. Either way the ease moves by SM-2's rule. A pass before
+// the card is due changes nothing but the review counts, so retaking a quiz
+// the same day cannot push a question months out.
+func (s Scheduler) Review(card models.ReviewCard, quality int) models.ReviewCard {
+	now := s.Now()
+	early := card.Reviews > 0 && now.Before(card.DueAt)
+	if card.Reviews == 0 {
+		card.Ease = InitialEase
+	}
+	card.Reviews++
+	card.LastQuality = quality
+	card.LastReviewedAt = now
+
+	if quality >= PassingQuality && early {
+		return card
+	}
+
+	if quality < PassingQuality {
+		if card.Repetitions > 0 {
+			card.Lapses++
+		}
+		card.Repetitions = 0
+		card.IntervalDays = 1
+	} else {
+		card.Repetitions++
+		switch card.Repetitions {
+		case 1:
+			card.IntervalDays = 1
+		case 2:
+			card.IntervalDays = 6
+		default:
+			card.IntervalDays = int(math.Round(float64(card.IntervalDays) * card.Ease))
+		}
+	}
+
+	miss := float64(MaxQuality - quality)
+	card.Ease = math.Max(MinEase, card.Ease+0.1-miss*(0.08+miss*0.02))
+	card.DueAt = now.Add(time.Duration(card.IntervalDays) * day)
+	return card
+}
+
+// Due reports whether a card is due for review now.
+func (s Scheduler) Due(card models.ReviewCard) bool {
+	return !card.DueAt.After(s.Now())
+}
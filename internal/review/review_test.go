@@ -0,0 +1,124 @@
+package review
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// fakeClock is a clock the test moves by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(days int) {
+	c.now = c.now.Add(time.Duration(days) * day)
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func TestQuality(t *testing.T) {
+	cases := []struct {
+		answered bool
+		points   float64
+		want     int
+	}{
+		{false, 0, 0}, {true, 0, 1}, {true, 0.25, 2}, {true, 0.5, 3}, {true, 0.8, 4}, {true, 1, 5},
+	}
+	for _, c := range cases {
+		if got := Quality(c.answered, c.points); got != c.want {
+			t.Errorf("Quality(%v, %v) = %d, want %d", c.answered, c.points, got, c.want)
+		}
+	}
+}
+
+func TestReviewIntervalsGrow(t *testing.T) {
+	clock := newClock()
+	scheduler := NewScheduler(clock)
+
+	var card models.ReviewCard
+	var intervals []int
+	for i := 0; i < 4; i++ {
+		card = scheduler.Review(card, 5)
+		intervals = append(intervals, card.IntervalDays)
+		if want := clock.now.Add(time.Duration(card.IntervalDays) * day); !card.DueAt.Equal(want) {
+			t.Fatalf("due at %v, want %v", card.DueAt, want)
+		}
+		clock.advance(card.IntervalDays)
+	}
+
+	// 1 and 6 days, then by an ease that grows 0.1 with each perfect review
+	if want := []int{1, 6, 16, 45}; !reflect.DeepEqual(intervals, want) {
+		t.Errorf("intervals = %v, want %v", intervals, want)
+	}
+	if math.Abs(card.Ease-2.9) > 1e-9 || card.Repetitions != 4 || card.Reviews != 4 {
+		t.Errorf("card = %+v, want ease 2.9 after 4 repetitions", card)
+	}
+}
+
+func TestReviewLapse(t *testing.T) {
+	clock := newClock()
+	scheduler := NewScheduler(clock)
+
+	card := scheduler.Review(models.ReviewCard{}, 4)
+	clock.advance(1)
+	card = scheduler.Review(card, 4)
+	clock.advance(6)
+
+	card = scheduler.Review(card, 1)
+	if card.Repetitions != 0 || card.IntervalDays != 1 || card.Lapses != 1 {
+		t.Errorf("card after a failure = %+v, want a lapse back to 1 day", card)
+	}
+	if math.Abs(card.Ease-1.96) > 1e-9 {
+		t.Errorf("ease = %v, want 2.5 lowered by a failure to 1.96", card.Ease)
+	}
+
+	// Failing a new card is not a lapse, and ease stops at the minimum
+	fresh := models.ReviewCard{}
+	for i := 0; i < 5; i++ {
+		fresh = scheduler.Review(fresh, 0)
+		clock.advance(1)
+	}
+	if fresh.Lapses != 0 || fresh.Ease != MinEase {
+		t.Errorf("card failed from new = %+v, want no lapses and ease %v", fresh, MinEase)
+	}
+}
+
+func TestReviewBeforeDue(t *testing.T) {
+	clock := newClock()
+	scheduler := NewScheduler(clock)
+
+	card := scheduler.Review(models.ReviewCard{}, 5)
+	clock.advance(1)
+	card = scheduler.Review(card, 5)
+	due := card.DueAt
+
+	// Passing again the next day leaves the 6 day interval alone
+	clock.advance(1)
+	if scheduler.Due(card) {
+		t.Fatal("card due a day into a 6 day interval")
+	}
+	early := scheduler.Review(card, 5)
+	if !early.DueAt.Equal(due) || early.IntervalDays != 6 || early.Ease != card.Ease || early.Reviews != 3 {
+		t.Errorf("early pass = %+v, want the schedule unchanged", early)
+	}
+
+	// Failing early still counts
+	if failed := scheduler.Review(card, 2); failed.Lapses != 1 || failed.IntervalDays != 1 {
+		t.Errorf("early failure = %+v, want a lapse", failed)
+	}
+
+	clock.advance(5)
+	if !scheduler.Due(card) {
+		t.Error("card not due once its interval has passed")
+	}
+}
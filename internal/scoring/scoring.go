@@ -0,0 +1,195 @@
+// Package scoring grades player responses. It holds no storage code, so every
+// repository implementation scores attempts the same way.
+package scoring
+
+import "github.com/changangus/go-quiz-backend/internal/models"
+
+// Key is everything needed to grade one question: its type and scoring
+// strategy, the answers it offers and which of them are correct, where each
+// belongs in order or what it matches, and the config that grades text
+// questions.
+type Key struct {
+	Type      string
+	Strategy  string
+	Options   []int64
+	Correct   []int64
+	Positions map[int64]int
+	Matches   map[int64]string
+	Config    *models.QuestionConfig
+}
+
+// AddAnswer adds one of the question's answers to the key.
+func (k *Key) AddAnswer(answer models.Answer) {
+	id := int64(answer.ID)
+	k.Options = append(k.Options, id)
+	if answer.Correct {
+		k.Correct = append(k.Correct, id)
+	}
+	if answer.Position != nil {
+		if k.Positions == nil {
+			k.Positions = make(map[int64]int)
+		}
+		k.Positions[id] = *answer.Position
+	}
+	if answer.Match != nil {
+		if k.Matches == nil {
+			k.Matches = make(map[int64]string)
+		}
+		k.Matches[id] = *answer.Match
+	}
+}
+
+// Score returns the points, between 0 and 1, earned by a response that
+// selected the given answers, put them in that order, matched each with the
+// text at the same index, or typed the given text. Duplicate selections are
+// ignored.
+func (k Key) Score(selected []int64, text []string) float64 {
+	questionType, _ := models.LookupQuestionType(k.Type)
+	switch questionType.Respond {
+	case models.RespondByTyping:
+		return gradeText(k.Type, k.Strategy, k.Config, text)
+	case models.RespondByOrdering:
+		return k.placed(selected, func(id int64, i int) bool { return k.Positions[id] == i+1 })
+	case models.RespondByMatching:
+		return k.placed(selected, func(id int64, i int) bool {
+			return i < len(text) && NormalizeText(text[i], false) == NormalizeText(k.Matches[id], false)
+		})
+	}
+
+	switch k.Strategy {
+	case models.ScoringPartial:
+		return partialCredit(k.Options, k.Correct, selected)
+	case models.ScoringPenalty:
+		return penalised(k.Correct, selected)
+	default:
+		if SelectionCorrect(k.Correct, selected) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// WithHints takes the penalty for the hints a player revealed off the points
+// a response earned, with a floor of zero.
+func WithHints(points float64, penalty float64) float64 {
+	if points <= penalty {
+		return 0
+	}
+	return points - penalty
+}
+
+// placed scores a response that puts answers in order or pairs them up,
+// where right reports whether the answer at index i of selected is placed
+// correctly. Under partial credit each of the question's answers earns an
+// equal share of the point; otherwise every one must be right.
+func (k Key) placed(selected []int64, right func(id int64, i int) bool) float64 {
+	if len(k.Options) == 0 {
+		return 0
+	}
+
+	options := idSet(k.Options)
+	seen := make(map[int64]bool, len(selected))
+	count := 0
+	for i, id := range selected {
+		if seen[id] || !options[id] {
+			continue
+		}
+		seen[id] = true
+		if right(id, i) {
+			count++
+		}
+	}
+
+	if k.Strategy == models.ScoringPartial {
+		return float64(count) / float64(len(k.Options))
+	}
+	if count == len(k.Options) {
+		return 1
+	}
+	return 0
+}
+
+// partialCredit gives each option an equal share of the point, earned by
+// picking it if it is correct or leaving it if it is not.
+func partialCredit(options []int64, correct []int64, selected []int64) float64 {
+	if len(options) == 0 {
+		return 0
+	}
+
+	correctSet := idSet(correct)
+	selectedSet := idSet(selected)
+	right := 0
+	for _, id := range options {
+		if correctSet[id] == selectedSet[id] {
+			right++
+		}
+	}
+
+	return float64(right) / float64(len(options))
+}
+
+// penalised gives each correct pick an equal share of the point and takes a
+// share away for each wrong pick, with a floor of zero.
+func penalised(correct []int64, selected []int64) float64 {
+	if len(correct) == 0 {
+		return 0
+	}
+
+	correctSet := idSet(correct)
+	net := 0
+	for id := range idSet(selected) {
+		if correctSet[id] {
+			net++
+		} else {
+			net--
+		}
+	}
+
+	if net <= 0 {
+		return 0
+	}
+	return float64(net) / float64(len(correct))
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// SelectionCorrect reports whether a player's selected answers exactly match the
+// set of correct answers for a question. Duplicate selections are ignored.
+func SelectionCorrect(correct []int64, selected []int64) bool {
+	correctSet := make(map[int64]bool, len(correct))
+	for _, id := range correct {
+		correctSet[id] = true
+	}
+
+	selectedSet := make(map[int64]bool, len(selected))
+	for _, id := range selected {
+		if !correctSet[id] {
+			return false
+		}
+		selectedSet[id] = true
+	}
+
+	return len(selectedSet) == len(correctSet)
+}
+
+// UniqueIDs returns ids with duplicates removed, preserving the first
+// occurrence of each.
+func UniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	return unique
+}
@@ -0,0 +1,100 @@
+package scoring
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// gradeText scores the typed values of a response to a text question.
+func gradeText(questionType string, strategy string, config *models.QuestionConfig, text []string) float64 {
+	if config == nil {
+		return 0
+	}
+
+	switch questionType {
+	case models.QuestionTypeShortAnswer:
+		if len(text) == 1 && TextMatches(config.TextAnswer, text[0]) {
+			return 1
+		}
+	case models.QuestionTypeNumeric:
+		if len(text) == 1 && NumberMatches(*config, text[0]) {
+			return 1
+		}
+	case models.QuestionTypeFillInBlank:
+		if len(config.Blanks) == 0 || len(text) != len(config.Blanks) {
+			return 0
+		}
+		right := 0
+		for i, blank := range config.Blanks {
+			if TextMatches(blank, text[i]) {
+				right++
+			}
+		}
+		if strategy == models.ScoringPartial {
+			return float64(right) / float64(len(config.Blanks))
+		}
+		if right == len(config.Blanks) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// NormalizeText trims value, collapses runs of whitespace to one space and,
+// unless caseSensitive, lowercases it.
+func NormalizeText(value string, caseSensitive bool) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if !caseSensitive {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// TextMatches reports whether a typed value is one of the accepted answers,
+// or matches the pattern in full, once both are normalized.
+func TextMatches(answer models.TextAnswer, value string) bool {
+	value = NormalizeText(value, answer.CaseSensitive)
+	if value == "" {
+		return false
+	}
+
+	for _, accepted := range answer.Accepted {
+		if value == NormalizeText(accepted, answer.CaseSensitive) {
+			return true
+		}
+	}
+
+	if answer.Pattern != "" {
+		re, err := answer.CompilePattern()
+		return err == nil && re.MatchString(value)
+	}
+	return false
+}
+
+// NumberMatches reports whether a typed number is the expected value, within
+// its tolerance, or inside the expected range. The config's unit may follow
+// the number; any other unit is wrong.
+func NumberMatches(config models.QuestionConfig, value string) bool {
+	value = strings.TrimSpace(value)
+	if config.Unit != "" {
+		lower, unit := strings.ToLower(value), strings.ToLower(config.Unit)
+		// Lowercasing can change a character's length in bytes, as it does
+		// for the Kelvin sign, so the unit is cut from lower, not value
+		if strings.HasSuffix(lower, unit) {
+			value = strings.TrimSpace(lower[:len(lower)-len(unit)])
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	if config.Number != nil {
+		difference := number - *config.Number
+		return difference <= config.Tolerance && -difference <= config.Tolerance
+	}
+	return config.Min != nil && config.Max != nil && number >= *config.Min && number <= *config.Max
+}
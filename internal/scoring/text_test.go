@@ -0,0 +1,65 @@
+package scoring
+
+import (
+	"testing"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+func TestTextMatches(t *testing.T) {
+	tests := []struct {
+		answer models.TextAnswer
+		value  string
+		want   bool
+	}{
+		{models.TextAnswer{Accepted: []string{"New  York"}}, " new york ", true},
+		{models.TextAnswer{Accepted: []string{"Paris"}, CaseSensitive: true}, "paris", false},
+		{models.TextAnswer{Accepted: []string{"Paris"}, CaseSensitive: true}, "Paris", true},
+		{models.TextAnswer{Pattern: `^colou?r$`}, "COLOR", true},
+		{models.TextAnswer{Pattern: `^colou?r$`}, "colours", false},
+		{models.TextAnswer{Pattern: `colou?r`}, "watercolour", false},
+		{models.TextAnswer{Pattern: `red|blue`}, "blue", true},
+		{models.TextAnswer{Pattern: `red|blue`}, "reddish", false},
+		{models.TextAnswer{Pattern: `a)|(b`}, "xbx", false},
+		{models.TextAnswer{Accepted: []string{""}}, "", false},
+	}
+
+	for _, tt := range tests {
+		if got := TextMatches(tt.answer, tt.value); got != tt.want {
+			t.Errorf("TextMatches(%+v, %q) = %v, want %v", tt.answer, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNumberMatches(t *testing.T) {
+	value, low, high := 100.0, 1.0, 2.0
+	exact := models.QuestionConfig{Number: &value, Tolerance: 0.5, Unit: "cm"}
+	between := models.QuestionConfig{Min: &low, Max: &high}
+	// The Kelvin sign U+212A is three bytes but lowercases to a one byte "k"
+	kelvin := models.QuestionConfig{Number: &value, Unit: "\u212a"}
+
+	tests := []struct {
+		config models.QuestionConfig
+		value  string
+		want   bool
+	}{
+		{exact, "100", true},
+		{exact, "100.4 cm", true},
+		{exact, "100.6", false},
+		{exact, "100 mm", false},
+		{exact, "a hundred", false},
+		{between, "1.5", true},
+		{between, "2", true},
+		{between, "2.1", false},
+		{kelvin, "100 \u212a", true},
+		{kelvin, "100 K", true},
+		{kelvin, "100", true},
+		{kelvin, "100 J", false},
+	}
+
+	for _, tt := range tests {
+		if got := NumberMatches(tt.config, tt.value); got != tt.want {
+			t.Errorf("NumberMatches(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var answerItemSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"answer_text": map[string]any{"type": "string", "description": "Text of the answer option"},
+		"is_correct":  map[string]any{"type": "boolean", "description": "Whether this option is a correct answer"},
+		"position":    map[string]any{"type": "integer", "description": "Ordering questions only: the option's place in the correct order, starting at 1"},
+		"match":       map[string]any{"type": "string", "description": "Matching questions only: the text this option pairs with"},
+		"rationale":   map[string]any{"type": "string", "description": "Why this option is right or wrong, shown to players after they submit"},
+	},
+	"required": []string{"answer_text"},
+}
+
+var hintItemSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"hint_text": map[string]any{"type": "string", "description": "Text of the hint"},
+		"penalty":   map[string]any{"type": "number", "minimum": 0, "maximum": 1, "description": "Points taken off the question, out of 1, when this hint is revealed"},
+	},
+	"required": []string{"hint_text"},
+}
+
+var (
+	listQuizzesTool = mcp.NewTool("ListQuizzes",
+		mcp.WithDescription("Lists every quiz with its id, title and description. Use GetQuiz to read a quiz's questions and answers."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+
+	getQuizTool = mcp.NewTool("GetQuiz",
+		mcp.WithDescription("Returns a quiz with all of its questions, ordered by order_num, every answer including is_correct, and the hints of each question."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("quiz_id", mcp.Required(), mcp.Description("ID of the quiz, as returned by ListQuizzes")),
+	)
+
+	addQuestionTool = mcp.NewTool("AddQuestion",
+		mcp.WithDescription("Adds a question with its answers to an existing quiz. Returns the new question and answer IDs."),
+		mcp.WithNumber("quiz_id", mcp.Required(), mcp.Description("ID of the quiz to add the question to")),
+		mcp.WithString("question_text", mcp.Required(), mcp.Description("Text of the question")),
+		mcp.WithString("type", mcp.Required(), mcp.Enum(models.QuestionTypes...), mcp.Description("Question type")),
+		mcp.WithString("scoring", mcp.Enum(models.ScoringStrategies...), mcp.Description("How responses are scored. Only multiple_select questions can use anything but all_or_nothing.")),
+		mcp.WithNumber("order_num", mcp.Min(1), mcp.Description("Position of the question in the quiz. Defaults to after the last question.")),
+		mcp.WithArray("answers", mcp.Items(answerItemSchema),
+			mcp.Description("Answer options. multiple_choice, true_false and multiple_select questions need at least one with is_correct set to true. Ordering questions give each a position and matching questions a match instead. Leave out for short_answer, numeric and fill_in_blank questions, which use config.")),
+		mcp.WithObject("config", mcp.Description("What responses are accepted, for short_answer, numeric and fill_in_blank questions only. See FormatQuizForApi for its shape.")),
+		mcp.WithString("explanation", mcp.Description("Why the correct answer is correct, shown to players after they submit")),
+		mcp.WithArray("hints", mcp.Items(hintItemSchema), mcp.Description("Hints in the order they are revealed, vaguest first")),
+	)
+
+	updateQuestionTool = mcp.NewTool("UpdateQuestion",
+		mcp.WithDescription("Updates the text, type, scoring, order_num, config, explanation or hints of a question. Only the fields provided are changed, all together or not at all."),
+		mcp.WithNumber("question_id", mcp.Required(), mcp.Description("ID of the question to update")),
+		mcp.WithString("question_text", mcp.Description("New question text")),
+		mcp.WithString("type", mcp.Enum(models.QuestionTypes...), mcp.Description("New question type. Changing to short_answer, numeric or fill_in_blank needs config as well, and changing away from them needs config set to null.")),
+		mcp.WithString("scoring", mcp.Enum(models.ScoringStrategies...), mcp.Description("New scoring strategy")),
+		mcp.WithNumber("order_num", mcp.Min(1), mcp.Description("New position in the quiz. Prefer ReorderQuestions to move several questions.")),
+		mcp.WithObject("config", mcp.Description("New config, for short_answer, numeric and fill_in_blank questions only. See FormatQuizForApi for its shape. null removes it.")),
+		mcp.WithString("explanation", mcp.Description("New explanation. An empty string removes it.")),
+		mcp.WithArray("hints", mcp.Items(hintItemSchema), mcp.Description("Replaces every hint of the question, in the order they are revealed. An empty list removes them.")),
+	)
+
+	updateAnswerTool = mcp.NewTool("UpdateAnswer",
+		mcp.WithDescription("Updates the text, correctness or rationale of a single answer option. Only the fields provided are changed."),
+		mcp.WithNumber("answer_id", mcp.Required(), mcp.Description("ID of the answer to update")),
+		mcp.WithString("answer_text", mcp.Description("New answer text")),
+		mcp.WithBoolean("is_correct", mcp.Description("Whether this option is a correct answer")),
+		mcp.WithString("rationale", mcp.Description("New rationale. An empty string removes it.")),
+	)
+
+	deleteQuestionTool = mcp.NewTool("DeleteQuestion",
+		mcp.WithDescription("Deletes a question and all of its answers."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("question_id", mcp.Required(), mcp.Description("ID of the question to delete")),
+	)
+
+	reorderQuestionsTool = mcp.NewTool("ReorderQuestions",
+		mcp.WithDescription("Renumbers a quiz's questions so they follow the given order, starting at order_num 1."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithNumber("quiz_id", mcp.Required(), mcp.Description("ID of the quiz")),
+		mcp.WithArray("question_ids", mcp.Required(), mcp.Items(map[string]any{"type": "integer"}),
+			mcp.Description("Every question ID in the quiz, exactly once, in the desired order")),
+	)
+)
+
+// registerEditTools adds the tools for browsing and editing existing quizzes.
+func registerEditTools(s *server.MCPServer, quizRepo repository.QuizStore, questionRepo repository.QuestionStore, answerRepo repository.AnswerStore) {
+	s.AddTool(listQuizzesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		quizzes, err := quizRepo.GetAll(ctx)
+		if err != nil {
+			return toolError("list quizzes", err), nil
+		}
+
+		return jsonResult(quizzes)
+	})
+
+	s.AddTool(getQuizTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		quizID, err := request.RequireInt("quiz_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		quiz, err := quizRepo.GetFull(ctx, strconv.Itoa(quizID))
+		if err != nil {
+			return toolError("load quiz", err), nil
+		}
+
+		return jsonResult(quiz)
+	})
+
+	s.AddTool(addQuestionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args struct {
+			QuizID int `json:"quiz_id"`
+			GeneratedQuestion
+		}
+		if err := request.BindArguments(&args); err != nil {
+			return validationResult([]ValidationError{{Field: "arguments", Message: err.Error()}}), nil
+		}
+
+		if errs := args.GeneratedQuestion.validate(""); len(errs) > 0 {
+			return validationResult(errs), nil
+		}
+
+		created, err := questionRepo.CreateWithAnswers(ctx, strconv.Itoa(args.QuizID), args.GeneratedQuestion.ToDraft())
+		if err != nil {
+			return toolError("add question", err), nil
+		}
+
+		return jsonResult(created)
+	})
+
+	s.AddTool(updateQuestionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		questionID, err := request.RequireInt("question_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var args struct {
+			QuestionText *string                                `json:"question_text"`
+			Type         *string                                `json:"type"`
+			Scoring      *string                                `json:"scoring"`
+			OrderNum     *int                                   `json:"order_num"`
+			Config       models.Optional[models.QuestionConfig] `json:"config"`
+			Explanation  *string                                `json:"explanation"`
+			Hints        []GeneratedHint                        `json:"hints"`
+		}
+		if err := request.BindArguments(&args); err != nil {
+			return validationResult([]ValidationError{{Field: "arguments", Message: err.Error()}}), nil
+		}
+		if args.Type != nil && !models.IsKnownQuestionType(*args.Type) {
+			return validationResult([]ValidationError{{Field: "type", Message: "unknown question type " + strconv.Quote(*args.Type)}}), nil
+		}
+		if errs := validateHints("", args.Hints); len(errs) > 0 {
+			return validationResult(errs), nil
+		}
+		var patch models.QuestionPatch
+		if args.QuestionText != nil {
+			patch.Question = models.Some(*args.QuestionText)
+		}
+		if args.Type != nil {
+			patch.Type = models.Some(*args.Type)
+		}
+		if args.Scoring != nil {
+			patch.Scoring = models.Some(*args.Scoring)
+		}
+		if args.OrderNum != nil {
+			patch.Order = models.Some(*args.OrderNum)
+		}
+		patch.Config = args.Config
+		if args.Explanation != nil {
+			patch.Explanation = optionalPatch(*args.Explanation)
+		}
+
+		// Hints are stored apart from the question, so a call may change
+		// only them
+		var hints []models.HintDraft
+		if args.Hints != nil {
+			hints = toHintDrafts(args.Hints)
+		}
+
+		id := strconv.Itoa(questionID)
+		if err := questionRepo.PatchWithHints(ctx, id, patch, hints); err != nil {
+			return toolError("update question", err), nil
+		}
+
+		question, err := questionRepo.GetByID(ctx, id)
+		if err != nil {
+			return toolError("load question", err), nil
+		}
+
+		return jsonResult(question)
+	})
+
+	s.AddTool(updateAnswerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		answerID, err := request.RequireInt("answer_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var args struct {
+			AnswerText *string `json:"answer_text"`
+			IsCorrect  *bool   `json:"is_correct"`
+			Rationale  *string `json:"rationale"`
+		}
+		if err := request.BindArguments(&args); err != nil {
+			return validationResult([]ValidationError{{Field: "arguments", Message: err.Error()}}), nil
+		}
+		var patch models.AnswerPatch
+		if args.AnswerText != nil {
+			patch.Answer = models.Some(*args.AnswerText)
+		}
+		if args.IsCorrect != nil {
+			patch.Correct = models.Some(*args.IsCorrect)
+		}
+		if args.Rationale != nil {
+			patch.Rationale = optionalPatch(*args.Rationale)
+		}
+
+		id := strconv.Itoa(answerID)
+		if err := answerRepo.Patch(ctx, id, patch); err != nil {
+			return toolError("update answer", err), nil
+		}
+
+		answer, err := answerRepo.GetByID(ctx, id)
+		if err != nil {
+			return toolError("load answer", err), nil
+		}
+
+		return jsonResult(answer)
+	})
+
+	s.AddTool(deleteQuestionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		questionID, err := request.RequireInt("question_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if err := questionRepo.Delete(ctx, strconv.Itoa(questionID)); err != nil {
+			return toolError("delete question", err), nil
+		}
+
+		return jsonResult(map[string]interface{}{"deleted_question_id": questionID})
+	})
+
+	s.AddTool(reorderQuestionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		quizID, err := request.RequireInt("quiz_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		questionIDs, err := request.RequireIntSlice("question_ids")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		ids := make([]int64, 0, len(questionIDs))
+		for _, id := range questionIDs {
+			ids = append(ids, int64(id))
+		}
+
+		id := strconv.Itoa(quizID)
+		if err := questionRepo.Reorder(ctx, id, ids); err != nil {
+			return toolError("reorder questions", err), nil
+		}
+
+		questions, err := questionRepo.GetByQuizID(ctx, id, models.QuestionQuery{})
+		if err != nil {
+			return toolError("load questions", err), nil
+		}
+
+		return jsonResult(questions)
+	})
+}
+
+// optionalPatch sets a nullable text field, clearing it when text is blank.
+func optionalPatch(text string) models.Optional[string] {
+	if value := optionalText(text); value != nil {
+		return models.Some(*value)
+	}
+	return models.Optional[string]{Set: true, Null: true}
+}
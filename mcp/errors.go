@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errorMessage describes a failed repository call to the client. Domain
+// errors carry messages meant for clients. Anything else is logged and
+// reported only as a failure to do action, so database error text never
+// reaches the model.
+func errorMessage(action string, err error) string {
+	var domainErr *repository.Error
+	if errors.As(err, &domainErr) {
+		return "Failed to " + action + ": " + domainErr.Error()
+	}
+
+	log.Printf("Error: failed to %s: %v", action, err)
+	return "Failed to " + action
+}
+
+// toolError reports a failed repository call as a tool error result. See
+// errorMessage.
+func toolError(action string, err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(errorMessage(action, err))
+}
+
+// clientError reports a failed repository call from a resource or prompt
+// handler. See errorMessage.
+func clientError(action string, err error) error {
+	return errors.New(errorMessage(action, err))
+}
@@ -2,85 +2,78 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/changangus/go-quiz-backend/db"
+	"github.com/changangus/go-quiz-backend/internal/repository"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-type Request struct {
+// Helper function to get environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
 }
 
 func main() {
-	s := server.NewMCPServer(
-		"Quiz Generator",
-		"1.0.0",
-		server.WithToolCapabilities(true),
-		server.WithRecovery(),
-	)
+	// stdio suits a desktop client launching the server itself; sse lets the
+	// server run as a container that several people connect to.
+	transport := flag.String("transport", getEnv("MCP_TRANSPORT", "stdio"), "Transport to serve on: stdio or sse")
+	addr := flag.String("addr", getEnv("MCP_ADDR", ":8081"), "Address to listen on for the sse transport")
+	baseURL := flag.String("base-url", getEnv("MCP_BASE_URL", ""), "Public URL clients use to reach the sse transport, e.g. http://localhost:8081")
+	flag.Parse()
+
+	// Initialize database connection. Generated quizzes are written straight
+	// through the repositories, the same way the API stores them.
+	database := db.GetDB()
+	defer database.Close()
 
-	quizTool := mcp.NewTool("FormatQuizForApi",
-		mcp.WithDescription("Takes a complete quiz, created by Claude Desktop, and formats it for the API."),
-		mcp.WithString("quiz_json_payload",
-			mcp.Required(),
-			mcp.Description(`A JSON string representing the full quiz content. The JSON object MUST conform to the following structure:
-            {
-              "title": "string",
-              "description": "string",
-              "questions": [
-                {
-                  "question_text": "string",
-                  "type": "string",
-                  "order_num": "integer",
-                  "answers": [ { "answer_text": "string", "is_correct": "boolean" } ]
-                }
-              ]
-      }`),
-		),
-	)
+	s := newServer(repository.NewRepositories(database, repository.WithQueryTimeout(db.QueryTimeout())))
 
-	// This is the handler function for your tool
-	s.AddTool(quizTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// 1. Get the value of the "quiz_json_payload" parameter from the request
-		// The mcp.CallToolRequest object contains the parameters sent by Claude Desktop.
-		quizJSONPayload, err := request.RequireString("quiz_json_payload")
-		if err != nil {
-			// If the required parameter is missing, log the error and return an error result to Claude.
-			log.Printf("Error: Required parameter 'quiz_json_payload' is missing. %v", err)
-			// The second argument to NewToolResultError can be an error code string, or empty.
-			return mcp.NewToolResultError("Missing required parameter: quiz_json_payload. Details: " + err.Error()), nil
+	switch *transport {
+	case "stdio":
+		log.Println("MCP Server starting and listening on stdio...")
+		if err := server.ServeStdio(s); err != nil {
+			log.Fatalf("Error starting MCP server: %v\n", err)
 		}
+	case "sse":
+		serveSSE(s, *addr, *baseURL)
+	default:
+		log.Fatalf("Unknown transport %q, expected stdio or sse", *transport)
+	}
+}
 
-		// 2. Log the received JSON payload
-		// This will print the JSON string (that Claude Desktop sent) to your Go application's console.
-		log.Printf("--- Received quiz_json_payload from Claude Desktop ---")
-		log.Printf("\n%s\n", quizJSONPayload)
-		log.Printf("--- End of quiz_json_payload ---")
+func serveSSE(s *server.MCPServer, addr string, baseURL string) {
+	var opts []server.SSEOption
+	if baseURL != "" {
+		opts = append(opts, server.WithBaseURL(baseURL))
+	}
+	sseServer := server.NewSSEServer(s, opts...)
 
-		// For more direct output if your MCP server's stdout is what you're monitoring:
-		// fmt.Printf("Received quiz_json_payload from Claude Desktop:\n%s\n", quizJSONPayload)
+	go func() {
+		log.Printf("MCP Server starting and listening for SSE on %s...", addr)
+		if err := sseServer.Start(addr); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Error starting MCP server: %v\n", err)
+		}
+	}()
 
-		// 3. For now, just acknowledge receipt and return the payload (or a success message).
-		// In a real application, you would:
-		//    a. Unmarshal quizJSONPayload into your Go structs (like GeneratedQuizData).
-		//    b. Validate the data.
-		//    c. Perform any transformations.
-		//    d. Marshal the (potentially transformed) data back to a JSON string.
-		//    e. Return that final JSON string using mcp.NewToolResultText().
-		//
-		// For this step, we'll just echo back the received payload.
-		// This confirms to Claude Desktop that the tool executed and what it received.
-		responseMessage := fmt.Sprintf("Successfully received and logged quiz_json_payload. Content received:\n%s", quizJSONPayload)
-		return mcp.NewToolResultText(responseMessage), nil
-		// Alternatively, just echo the payload if that's more useful for Claude's side:
-		// return mcp.NewToolResultText(quizJSONPayload), nil
-	})
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
 
-	// You need to start the server for it to listen for tool calls from Claude Desktop
-	log.Println("MCP Server starting and listening on stdio...")
-	if err := server.ServeStdio(s); err != nil { // Or server.Serve("tcp", "localhost:PORT") for network
-		log.Fatalf("Error starting MCP server: %v\n", err)
+	log.Println("Shutting down MCP server...")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sseServer.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down MCP server: %v", err)
 	}
-
 }
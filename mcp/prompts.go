@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const defaultQuestionCount = 10
+
+var (
+	generateQuizPrompt = mcp.NewPrompt("generate_quiz",
+		mcp.WithPromptDescription("Generate a new quiz on a topic and save it with FormatQuizForApi."),
+		mcp.WithArgument("topic", mcp.RequiredArgument(), mcp.ArgumentDescription("What the quiz should test")),
+		mcp.WithArgument("num_questions", mcp.ArgumentDescription("How many questions to write. Defaults to 10.")),
+		mcp.WithArgument("question_types", mcp.ArgumentDescription("Comma separated question types to use: "+strings.Join(models.QuestionTypes, ", ")+". Defaults to all of them.")),
+	)
+
+	extendQuizPrompt = mcp.NewPrompt("extend_quiz",
+		mcp.WithPromptDescription("Add new questions to an existing quiz without repeating what it already covers."),
+		mcp.WithArgument("quiz_id", mcp.RequiredArgument(), mcp.ArgumentDescription("ID of the quiz to extend")),
+		mcp.WithArgument("num_questions", mcp.ArgumentDescription("How many questions to add. Defaults to 10.")),
+	)
+)
+
+// registerPrompts adds prompt templates that embed the payload contract, so
+// the model produces quizzes the tools accept on the first try.
+func registerPrompts(s *server.MCPServer, quizRepo repository.QuizStore) {
+	s.AddPrompt(generateQuizPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		topic := strings.TrimSpace(request.Params.Arguments["topic"])
+		if topic == "" {
+			return nil, fmt.Errorf("topic is required")
+		}
+
+		count, err := questionCount(request.Params.Arguments["num_questions"])
+		if err != nil {
+			return nil, err
+		}
+
+		types, err := questionTypes(request.Params.Arguments["question_types"])
+		if err != nil {
+			return nil, err
+		}
+
+		text := fmt.Sprintf(`Write a quiz about %s with exactly %d questions.
+Use only these question types: %s.
+Number the questions with order_num 1 to %d. Keep every answer option plausible, and give each question the answers or config its type needs, as set out below.
+Before writing, read the quizzes://all resource and avoid duplicating an existing quiz.
+
+When the quiz is ready, call the FormatQuizForApi tool with quiz_json_payload set to a JSON string in this exact structure:
+%s
+
+If the tool returns validation errors, fix every listed field and call it again.`,
+			topic, count, strings.Join(types, ", "), count, quizPayloadSchema)
+
+		return mcp.NewGetPromptResult(
+			"Generate a quiz about "+topic,
+			[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))},
+		), nil
+	})
+
+	s.AddPrompt(extendQuizPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		quizID := strings.TrimSpace(request.Params.Arguments["quiz_id"])
+		count, err := questionCount(request.Params.Arguments["num_questions"])
+		if err != nil {
+			return nil, err
+		}
+
+		quiz, err := quizRepo.GetFull(ctx, quizID)
+		if err != nil {
+			return nil, clientError("load quiz "+quizID, err)
+		}
+
+		contents, err := jsonResource(quizURIPrefix+quizID, quiz)
+		if err != nil {
+			return nil, err
+		}
+
+		text := fmt.Sprintf(`The quiz "%s" is attached above. Add %d new questions to it that cover material the existing questions do not.
+Call the AddQuestion tool once per question with quiz_id %s. Use only these question types: %s.
+Give each question the answers or config its type needs:
+%s`,
+			quiz.Title, count, quizID, strings.Join(models.QuestionTypes, ", "), questionTypeRules)
+
+		return mcp.NewGetPromptResult(
+			"Extend quiz "+quiz.Title,
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(contents[0])),
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			},
+		), nil
+	})
+}
+
+func questionCount(value string) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaultQuestionCount, nil
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("num_questions must be a positive integer")
+	}
+
+	return count, nil
+}
+
+func questionTypes(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return models.QuestionTypes, nil
+	}
+
+	var types []string
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if !models.IsKnownQuestionType(t) {
+			return nil, fmt.Errorf("unknown question type %q, expected one of: %s", t, strings.Join(models.QuestionTypes, ", "))
+		}
+		types = append(types, t)
+	}
+
+	return types, nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+)
+
+// GeneratedQuizData is the quiz_json_payload shape the model is asked to
+// produce. It uses question_text/answer_text rather than the API field names.
+type GeneratedQuizData struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Questions   []GeneratedQuestion `json:"questions"`
+}
+
+type GeneratedQuestion struct {
+	QuestionText string                 `json:"question_text"`
+	Type         string                 `json:"type"`
+	Scoring      string                 `json:"scoring,omitempty"`
+	OrderNum     int                    `json:"order_num"`
+	Config       *models.QuestionConfig `json:"config,omitempty"`
+	Explanation  string                 `json:"explanation,omitempty"`
+	Answers      []GeneratedAnswer      `json:"answers"`
+	Hints        []GeneratedHint        `json:"hints,omitempty"`
+}
+
+type GeneratedAnswer struct {
+	AnswerText string  `json:"answer_text"`
+	IsCorrect  bool    `json:"is_correct"`
+	Position   *int    `json:"position,omitempty"`
+	Match      *string `json:"match,omitempty"`
+	Rationale  string  `json:"rationale,omitempty"`
+}
+
+// GeneratedHint is one tier of a question's hints, in the order they are
+// revealed.
+type GeneratedHint struct {
+	HintText string  `json:"hint_text"`
+	Penalty  float64 `json:"penalty"`
+}
+
+// ValidationError points the model at the exact field it needs to fix.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validate checks the payload against what the API can store and grade. It
+// collects every problem rather than stopping at the first one, so the model
+// can correct the whole quiz in a single retry.
+func (q GeneratedQuizData) Validate() []ValidationError {
+	var errs []ValidationError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(q.Title) == "" {
+		add("title", "title is required")
+	}
+	if len(q.Questions) == 0 {
+		add("questions", "at least one question is required")
+	}
+
+	seenOrder := make(map[int]int)
+	for i, question := range q.Questions {
+		field := fmt.Sprintf("questions[%d]", i)
+		errs = append(errs, question.validate(field)...)
+
+		if question.OrderNum < 1 {
+			add(field+".order_num", "order_num must be a positive integer")
+		} else if previous, ok := seenOrder[question.OrderNum]; ok {
+			add(field+".order_num", "order_num %d is already used by questions[%d]", question.OrderNum, previous)
+		} else {
+			seenOrder[question.OrderNum] = i
+		}
+	}
+
+	return errs
+}
+
+// validate checks a single question and its answers. Field names in the
+// returned errors are prefixed with field.
+func (q GeneratedQuestion) validate(field string) []ValidationError {
+	var errs []ValidationError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if field != "" {
+		field += "."
+	}
+
+	if strings.TrimSpace(q.QuestionText) == "" {
+		add(field+"question_text", "question_text is required")
+	}
+
+	questionType, known := models.LookupQuestionType(q.Type)
+	if !known {
+		add(field+"type", "unknown question type %q, expected one of: %s",
+			q.Type, strings.Join(models.QuestionTypes, ", "))
+	} else {
+		if _, err := questionType.ScoringStrategy(q.Scoring); err != nil {
+			add(field+"scoring", "scoring %v", err)
+		}
+		if message := questionType.CheckConfig(q.QuestionText, q.Config); message != "" {
+			add(field+"config", "config %s", message)
+		}
+	}
+
+	errs = append(errs, validateHints(field, q.Hints)...)
+
+	// Text questions are graded against their config and have no answers
+	if known && questionType.Respond == models.RespondByTyping {
+		if len(q.Answers) > 0 {
+			add(field+"answers", "answers are not used by %s questions", q.Type)
+		}
+		return errs
+	}
+
+	if len(q.Answers) == 0 {
+		add(field+"answers", "at least one answer is required")
+	}
+
+	hasCorrect := false
+	for j, answer := range q.Answers {
+		if strings.TrimSpace(answer.AnswerText) == "" {
+			add(fmt.Sprintf("%sanswers[%d].answer_text", field, j), "answer_text is required")
+		}
+		hasCorrect = hasCorrect || answer.IsCorrect
+	}
+
+	// Ordering and matching answers carry a position or match instead
+	selecting := !known || questionType.Respond == models.RespondBySelecting
+	if len(q.Answers) > 0 && selecting && !hasCorrect {
+		add(field+"answers", "at least one answer must have is_correct set to true")
+	} else if known && len(q.Answers) > 0 {
+		if message := questionType.CheckAnswers(q.ToDraft().Answers, true); message != "" {
+			add(field+"answers", "answers %s", message)
+		}
+	}
+
+	return errs
+}
+
+// validateHints checks a question's hints. field is the question's prefix,
+// including its trailing dot.
+func validateHints(field string, hints []GeneratedHint) []ValidationError {
+	var errs []ValidationError
+	for j, hint := range hints {
+		hintField := fmt.Sprintf("%shints[%d]", field, j)
+		if strings.TrimSpace(hint.HintText) == "" {
+			errs = append(errs, ValidationError{Field: hintField + ".hint_text", Message: "hint_text is required"})
+		}
+		if hint.Penalty < 0 || hint.Penalty > 1 {
+			errs = append(errs, ValidationError{Field: hintField + ".penalty", Message: "penalty must be between 0 and 1"})
+		}
+	}
+	return errs
+}
+
+// ToDraft maps the payload onto the API's schema.
+func (q GeneratedQuizData) ToDraft() models.QuizDraft {
+	draft := models.QuizDraft{
+		Title:       strings.TrimSpace(q.Title),
+		Description: optionalText(q.Description),
+		Questions:   make([]models.QuestionDraft, 0, len(q.Questions)),
+	}
+
+	for _, question := range q.Questions {
+		draft.Questions = append(draft.Questions, question.ToDraft())
+	}
+
+	return draft
+}
+
+func (q GeneratedQuestion) ToDraft() models.QuestionDraft {
+	draft := models.QuestionDraft{
+		Question:    strings.TrimSpace(q.QuestionText),
+		Type:        q.Type,
+		Scoring:     q.Scoring,
+		Order:       q.OrderNum,
+		Config:      q.Config,
+		Explanation: optionalText(q.Explanation),
+		Answers:     make([]models.AnswerDraft, 0, len(q.Answers)),
+		Hints:       toHintDrafts(q.Hints),
+	}
+
+	for _, answer := range q.Answers {
+		draft.Answers = append(draft.Answers, models.AnswerDraft{
+			Answer:    strings.TrimSpace(answer.AnswerText),
+			Correct:   answer.IsCorrect,
+			Position:  answer.Position,
+			Match:     answer.Match,
+			Rationale: optionalText(answer.Rationale),
+		})
+	}
+
+	return draft
+}
+
+func toHintDrafts(hints []GeneratedHint) []models.HintDraft {
+	drafts := make([]models.HintDraft, 0, len(hints))
+	for _, hint := range hints {
+		drafts = append(drafts, models.HintDraft{Hint: strings.TrimSpace(hint.HintText), Penalty: hint.Penalty})
+	}
+	return drafts
+}
+
+// optionalText trims text, treating what is left empty as not given.
+func optionalText(text string) *string {
+	if text = strings.TrimSpace(text); text == "" {
+		return nil
+	}
+	return &text
+}
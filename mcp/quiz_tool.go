@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// quizPayloadSchema is the contract for quiz_json_payload. Prompts embed it
+// too, so generated quizzes can be passed straight to FormatQuizForApi.
+var quizPayloadSchema = `{
+  "title": "string",
+  "description": "string",
+  "questions": [
+    {
+      "question_text": "string",
+      "type": "` + strings.Join(models.QuestionTypes, " | ") + `",
+      "scoring": "optional, multiple_select only: ` + strings.Join(models.ScoringStrategies, " | ") + `",
+      "order_num": "integer, unique within the quiz, starting at 1",
+      "answers": [ { "answer_text": "string", "is_correct": "boolean, choice types only", "position": "ordering only", "match": "matching only", "rationale": "optional: why this answer is right or wrong" } ],
+      "config": "short_answer, numeric and fill_in_blank only, see below",
+      "explanation": "optional: why the correct answer is correct",
+      "hints": [ { "hint_text": "string", "penalty": "number from 0 to 1 taken off the question's point when revealed" } ]
+    }
+  ]
+}
+` + questionTypeRules + `
+hints are optional and revealed in order, so give the vaguest first.
+Explanations and rationales are only shown to players after they submit.`
+
+// questionTypeRules says what answers or config each question type needs.
+// It is shared by quizPayloadSchema and the prompts that add questions one
+// at a time.
+var questionTypeRules = `multiple_choice, true_false and multiple_select questions need at least
+one answer with is_correct set to true. multiple_choice questions have
+exactly one correct answer, true_false questions have exactly two answers,
+and multiple_select questions may have several correct answers.
+ordering questions give every answer its position in the correct order, 1 to
+n, and matching questions give every answer the text it matches; neither
+marks answers correct.
+short_answer, numeric and fill_in_blank questions have no answers. Their
+config says what responses are accepted:
+  short_answer:  { "accepted": ["string"], "case_sensitive": false, "pattern": "optional regular expression the whole response must match" }
+  numeric:       { "value": 9.81, "tolerance": 0.01, "unit": "m/s²" } or { "min": 1, "max": 5 }
+  fill_in_blank: { "blanks": [ { "accepted": ["string"] } ] }, one per ___ in question_text`
+
+var formatQuizTool = mcp.NewTool("FormatQuizForApi",
+	mcp.WithDescription("Takes a complete quiz, created by Claude Desktop, validates it and saves it through the API's repositories. Returns the new quiz ID, or a list of validation errors to fix before calling again."),
+	mcp.WithString("quiz_json_payload",
+		mcp.Required(),
+		mcp.Description("A JSON string representing the full quiz content. The JSON object MUST conform to the following structure:\n"+quizPayloadSchema),
+	),
+)
+
+func formatQuizHandler(quizRepo repository.QuizStore) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		quizJSONPayload, err := request.RequireString("quiz_json_payload")
+		if err != nil {
+			log.Printf("Error: Required parameter 'quiz_json_payload' is missing. %v", err)
+			return mcp.NewToolResultError("Missing required parameter: quiz_json_payload. Details: " + err.Error()), nil
+		}
+
+		var quiz GeneratedQuizData
+		if err := json.Unmarshal([]byte(quizJSONPayload), &quiz); err != nil {
+			return validationResult([]ValidationError{{
+				Field:   "quiz_json_payload",
+				Message: "payload is not valid JSON for the quiz structure: " + err.Error(),
+			}}), nil
+		}
+
+		if errs := quiz.Validate(); len(errs) > 0 {
+			return validationResult(errs), nil
+		}
+
+		created, err := quizRepo.CreateFull(ctx, quiz.ToDraft())
+		if err != nil {
+			return toolError("save quiz", err), nil
+		}
+
+		log.Printf("Saved generated quiz %d with %d questions", created.ID, len(created.Questions))
+		return jsonResult(map[string]interface{}{
+			"quiz_id":   created.ID,
+			"questions": created.Questions,
+		})
+	}
+}
+
+// jsonResult returns v as JSON text so the model can read IDs back out and
+// pass them to other tools.
+func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(payload)), nil
+}
+
+// validationResult reports every validation problem as a structured error
+// result, leaving it to the model to fix the payload and retry.
+func validationResult(errs []ValidationError) *mcp.CallToolResult {
+	payload, err := json.Marshal(map[string]interface{}{"errors": errs})
+	if err != nil {
+		return mcp.NewToolResultError("Quiz payload failed validation")
+	}
+
+	return mcp.NewToolResultError(string(payload))
+}
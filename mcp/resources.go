@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	quizURIPrefix  = "quiz://"
+	quizCatalogURI = "quizzes://all"
+)
+
+var (
+	quizResourceTemplate = mcp.NewResourceTemplate(quizURIPrefix+"{id}", "Quiz",
+		mcp.WithTemplateDescription("A quiz with all of its questions, ordered by order_num, and every answer including is_correct."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	quizCatalogResource = mcp.NewResource(quizCatalogURI, "Quiz catalog",
+		mcp.WithResourceDescription("Every quiz with its id, title and description. Read quiz://{id} for the full content of one."),
+		mcp.WithMIMEType("application/json"),
+	)
+)
+
+// registerResources exposes quizzes as read-only resources so an assistant can
+// pull existing content into context before generating more.
+func registerResources(s *server.MCPServer, quizRepo repository.QuizStore) {
+	s.AddResource(quizCatalogResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		quizzes, err := quizRepo.GetAll(ctx)
+		if err != nil {
+			return nil, clientError("list quizzes", err)
+		}
+
+		return jsonResource(request.Params.URI, quizzes)
+	})
+
+	s.AddResourceTemplate(quizResourceTemplate, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		id := strings.TrimPrefix(request.Params.URI, quizURIPrefix)
+		quiz, err := quizRepo.GetFull(ctx, id)
+		if err != nil {
+			return nil, clientError("load quiz "+id, err)
+		}
+
+		return jsonResource(request.Params.URI, quiz)
+	})
+}
+
+func jsonResource(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(payload),
+		},
+	}, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newServer builds the MCP server with every tool, resource and prompt
+// registered. All transports serve the same instance.
+func newServer(repos repository.Repositories) *server.MCPServer {
+	s := server.NewMCPServer(
+		"Quiz Generator",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, false),
+		server.WithPromptCapabilities(false),
+		server.WithRecovery(),
+	)
+
+	s.AddTool(formatQuizTool, formatQuizHandler(repos.Quizzes))
+	registerEditTools(s, repos.Quizzes, repos.Questions, repos.Answers)
+	registerResources(s, repos.Quizzes)
+	registerPrompts(s, repos.Quizzes)
+
+	return s
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/changangus/go-quiz-backend/internal/models"
+	"github.com/changangus/go-quiz-backend/internal/repository"
+	"github.com/changangus/go-quiz-backend/internal/repository/memory"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestServer builds the server over the in-memory store.
+func newTestServer() *server.MCPServer {
+	return newServer(memory.NewRepositories())
+}
+
+func startClient(t *testing.T, c *client.Client) {
+	t.Helper()
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "quiz-test", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+}
+
+func assertRegistrations(t *testing.T, c *client.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	want := "AddQuestion,DeleteQuestion,FormatQuizForApi,GetQuiz,ListQuizzes,ReorderQuestions,UpdateAnswer,UpdateQuestion"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("tools = %s, want %s", got, want)
+	}
+
+	templates, err := c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 1 || templates.ResourceTemplates[0].URITemplate.Raw() != "quiz://{id}" {
+		t.Errorf("unexpected resource templates: %+v", templates.ResourceTemplates)
+	}
+
+	prompts, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(prompts.Prompts) != 2 {
+		t.Errorf("expected 2 prompts, got %d", len(prompts.Prompts))
+	}
+}
+
+func TestServerInProcess(t *testing.T) {
+	c, err := client.NewInProcessClient(newTestServer())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	startClient(t, c)
+	assertRegistrations(t, c)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "FormatQuizForApi"
+	request.Params.Arguments = map[string]any{
+		"quiz_json_payload": `{"title": "", "questions": [{"question_text": "Q", "type": "essay", "order_num": 1, "answers": [{"answer_text": "A"}]}]}`,
+	}
+	result, err := c.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected a validation error result")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, field := range []string{`"title"`, `"questions[0].type"`, `"questions[0].answers"`} {
+		if !strings.Contains(text, field) {
+			t.Errorf("validation errors %s do not mention %s", text, field)
+		}
+	}
+}
+
+func TestServerSSE(t *testing.T) {
+	httpServer := httptest.NewUnstartedServer(nil)
+	sseServer := server.NewSSEServer(newTestServer(), server.WithBaseURL("http://"+httpServer.Listener.Addr().String()))
+	httpServer.Config.Handler = sseServer
+	httpServer.Start()
+	// Shutting down the SSE server ends open streams, which httptest's Close
+	// would otherwise wait on forever.
+	t.Cleanup(func() {
+		sseServer.Shutdown(context.Background())
+		httpServer.Close()
+	})
+
+	c, err := client.NewSSEMCPClient(httpServer.URL + "/sse")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	startClient(t, c)
+	assertRegistrations(t, c)
+}
+
+// callTool calls a tool and fails the test if the call itself fails.
+func callTool(t *testing.T, c *client.Client, name string, arguments map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	result, err := c.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	return result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func newInProcessClient(t *testing.T, repos repository.Repositories) *client.Client {
+	t.Helper()
+	c, err := client.NewInProcessClient(newServer(repos))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	startClient(t, c)
+	return c
+}
+
+func TestEditTools(t *testing.T) {
+	repos := memory.NewRepositories()
+	quizID, err := repos.Quizzes.Create(context.Background(), models.QuizInput{Title: "Capitals"})
+	if err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+	c := newInProcessClient(t, repos)
+
+	var ids []int64
+	for _, q := range [][3]string{{"Capital of France?", "Paris", "Lyon"}, {"Capital of Spain?", "Madrid", "Seville"}} {
+		result := callTool(t, c, "AddQuestion", map[string]any{
+			"quiz_id":       quizID,
+			"question_text": q[0],
+			"type":          "multiple_choice",
+			"answers": []any{
+				map[string]any{"answer_text": q[1], "is_correct": true},
+				map[string]any{"answer_text": q[2]},
+			},
+		})
+		if result.IsError {
+			t.Fatalf("AddQuestion: %s", resultText(result))
+		}
+		var created models.CreatedQuestion
+		if err := json.Unmarshal([]byte(resultText(result)), &created); err != nil {
+			t.Fatalf("AddQuestion returned %s: %v", resultText(result), err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	result := callTool(t, c, "ReorderQuestions", map[string]any{"quiz_id": quizID, "question_ids": []any{ids[1], ids[0]}})
+	if result.IsError {
+		t.Fatalf("ReorderQuestions: %s", resultText(result))
+	}
+	result = callTool(t, c, "ReorderQuestions", map[string]any{"quiz_id": 404, "question_ids": []any{ids[0]}})
+	if !result.IsError || resultText(result) != "Failed to reorder questions: quiz not found" {
+		t.Errorf("reordering a missing quiz = %q, want quiz not found", resultText(result))
+	}
+	result = callTool(t, c, "GetQuiz", map[string]any{"quiz_id": quizID})
+	var quiz models.FullQuiz
+	if err := json.Unmarshal([]byte(resultText(result)), &quiz); err != nil {
+		t.Fatalf("GetQuiz returned %s: %v", resultText(result), err)
+	}
+	if len(quiz.Questions) != 2 || int64(quiz.Questions[0].ID) != ids[1] {
+		t.Errorf("questions after reorder = %+v, want %d first", quiz.Questions, ids[1])
+	}
+
+	result = callTool(t, c, "DeleteQuestion", map[string]any{"question_id": ids[0]})
+	if result.IsError {
+		t.Fatalf("DeleteQuestion: %s", resultText(result))
+	}
+	result = callTool(t, c, "DeleteQuestion", map[string]any{"question_id": ids[0]})
+	if !result.IsError || resultText(result) != "Failed to delete question: question not found" {
+		t.Errorf("deleting twice = %q, want question not found", resultText(result))
+	}
+}
+
+func TestUpdateQuestionTool(t *testing.T) {
+	repos := memory.NewRepositories()
+	quizID, err := repos.Quizzes.Create(context.Background(), models.QuizInput{Title: "Capitals"})
+	if err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+	created, err := repos.Questions.CreateWithAnswers(context.Background(), strconv.FormatInt(quizID, 10), models.QuestionDraft{
+		Question: "Capital of France?",
+		Type:     models.QuestionTypeShortAnswer,
+		Config:   &models.QuestionConfig{TextAnswer: models.TextAnswer{Accepted: []string{"Paris"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create question: %v", err)
+	}
+	id := strconv.FormatInt(created.ID, 10)
+	c := newInProcessClient(t, repos)
+
+	// Hints that cannot be saved leave the question as it was
+	result := callTool(t, c, "UpdateQuestion", map[string]any{
+		"question_id":   created.ID,
+		"question_text": "Capital of Spain?",
+		"hints":         []any{map[string]any{"hint_text": "Too costly", "penalty": 2}},
+	})
+	if !result.IsError {
+		t.Fatalf("UpdateQuestion accepted a penalty above 1: %s", resultText(result))
+	}
+	question, err := repos.Questions.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load question: %v", err)
+	}
+	if question.Question != "Capital of France?" {
+		t.Errorf("question = %q after a failed update, want it unchanged", question.Question)
+	}
+
+	// A type that is graded from config takes its config in the same call
+	result = callTool(t, c, "UpdateQuestion", map[string]any{
+		"question_id":   created.ID,
+		"question_text": "Boiling point of water in C?",
+		"type":          models.QuestionTypeNumeric,
+		"config":        map[string]any{"value": 100, "tolerance": 1},
+		"hints":         []any{map[string]any{"hint_text": "At sea level", "penalty": 0.5}},
+	})
+	if result.IsError {
+		t.Fatalf("UpdateQuestion: %s", resultText(result))
+	}
+	question, err = repos.Questions.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load question: %v", err)
+	}
+	if question.Type != models.QuestionTypeNumeric || question.Config == nil || question.Config.Number == nil || *question.Config.Number != 100 {
+		t.Errorf("question = %+v, want numeric with a value of 100", question)
+	}
+	hints, err := repos.Questions.GetHints(context.Background(), id)
+	if err != nil || len(hints) != 1 || hints[0].Hint != "At sea level" {
+		t.Errorf("hints = %+v (%v), want the new hint", hints, err)
+	}
+}
+
+// failingQuizzes fails every read with a database error.
+type failingQuizzes struct {
+	repository.QuizStore
+}
+
+func (failingQuizzes) GetFull(ctx context.Context, id string) (*models.FullQuiz, error) {
+	return nil, errors.New(`pq: relation "quizzes" does not exist`)
+}
+
+func TestToolErrorsHideDatabaseErrors(t *testing.T) {
+	repos := memory.NewRepositories()
+	c := newInProcessClient(t, repos)
+	result := callTool(t, c, "GetQuiz", map[string]any{"quiz_id": 404})
+	if !result.IsError || resultText(result) != "Failed to load quiz: quiz not found" {
+		t.Errorf("missing quiz = %q, want quiz not found", resultText(result))
+	}
+
+	repos.Quizzes = failingQuizzes{repos.Quizzes}
+	c = newInProcessClient(t, repos)
+	result = callTool(t, c, "GetQuiz", map[string]any{"quiz_id": 1})
+	if !result.IsError || resultText(result) != "Failed to load quiz" {
+		t.Errorf("database error = %q, want it hidden", resultText(result))
+	}
+}
+
+func TestFormatQuizSaves(t *testing.T) {
+	repos := memory.NewRepositories()
+	c := newInProcessClient(t, repos)
+
+	result := callTool(t, c, "FormatQuizForApi", map[string]any{
+		"quiz_json_payload": `{"title": "Planets", "description": "The solar system", "questions": [
+			{"question_text": "Largest planet?", "type": "multiple_choice", "order_num": 1,
+			 "answers": [{"answer_text": "Jupiter", "is_correct": true}, {"answer_text": "Mars"}]},
+			{"question_text": "Pluto is a planet", "type": "true_false", "order_num": 2,
+			 "answers": [{"answer_text": "True"}, {"answer_text": "False", "is_correct": true}]}
+		]}`,
+	})
+	if result.IsError {
+		t.Fatalf("FormatQuizForApi: %s", resultText(result))
+	}
+	var saved struct {
+		QuizID int `json:"quiz_id"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &saved); err != nil {
+		t.Fatalf("FormatQuizForApi returned %s: %v", resultText(result), err)
+	}
+
+	quiz, err := repos.Quizzes.GetFull(context.Background(), strconv.Itoa(saved.QuizID))
+	if err != nil {
+		t.Fatalf("saved quiz %d not found: %v", saved.QuizID, err)
+	}
+	if quiz.Title != "Planets" || len(quiz.Questions) != 2 {
+		t.Fatalf("saved quiz = %+v, want Planets with 2 questions", quiz)
+	}
+	second := quiz.Questions[1]
+	if second.Question.Question != "Pluto is a planet" || len(second.Answers) != 2 || second.Answers[1].Answer != "False" || !second.Answers[1].Correct {
+		t.Errorf("second question = %+v, want its text and correct answer mapped", second)
+	}
+}
+
+func TestFormatQuizRejectsMissingCorrectAnswer(t *testing.T) {
+	repos := memory.NewRepositories()
+	c := newInProcessClient(t, repos)
+
+	result := callTool(t, c, "FormatQuizForApi", map[string]any{
+		"quiz_json_payload": `{"title": "Planets", "questions": [
+			{"question_text": "Largest planet?", "type": "multiple_choice", "order_num": 1, "answers": [{"answer_text": "Jupiter"}]},
+			{"question_text": "Smallest planet?", "type": "multiple_choice", "order_num": 1, "answers": [{"answer_text": "Mercury", "is_correct": true}]}
+		]}`,
+	})
+	if !result.IsError {
+		t.Fatal("expected a validation error result")
+	}
+	for _, field := range []string{`"questions[0].answers"`, `"questions[1].order_num"`} {
+		if !strings.Contains(resultText(result), field) {
+			t.Errorf("validation errors %s do not mention %s", resultText(result), field)
+		}
+	}
+	if quizzes, _ := repos.Quizzes.GetAll(context.Background()); len(quizzes) != 0 {
+		t.Errorf("invalid payload saved %d quizzes", len(quizzes))
+	}
+}
+
+func TestPromptsDescribeAnswersPerType(t *testing.T) {
+	repos := memory.NewRepositories()
+	if _, err := repos.Quizzes.Create(context.Background(), models.QuizInput{Title: "Physics"}); err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+	c := newInProcessClient(t, repos)
+
+	for name, arguments := range map[string]map[string]string{
+		"generate_quiz": {"topic": "Physics"},
+		"extend_quiz":   {"quiz_id": "1"},
+	} {
+		request := mcp.GetPromptRequest{}
+		request.Params.Name = name
+		request.Params.Arguments = arguments
+		result, err := c.GetPrompt(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		text := result.Messages[len(result.Messages)-1].Content.(mcp.TextContent).Text
+		if strings.Contains(text, "Every question needs") || !strings.Contains(text, questionTypeRules) {
+			t.Errorf("%s does not give the answer rules per question type:\n%s", name, text)
+		}
+	}
+}
+
+// readResource reads a resource and returns its text.
+func readResource(t *testing.T, c *client.Client, uri string) string {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	result, err := c.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("reading %s failed: %v", uri, err)
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("%s has %d contents, want 1", uri, len(result.Contents))
+	}
+	contents := result.Contents[0].(mcp.TextResourceContents)
+	if contents.URI != uri || contents.MIMEType != "application/json" {
+		t.Errorf("%s contents = %+v, want JSON at the URI read", uri, contents)
+	}
+	return contents.Text
+}
+
+func TestResources(t *testing.T) {
+	repos := memory.NewRepositories()
+	c := newInProcessClient(t, repos)
+
+	// An empty catalogue is an empty list, not null
+	if text := readResource(t, c, quizCatalogURI); text != "[]" {
+		t.Errorf("empty catalogue = %s, want []", text)
+	}
+	if text := resultText(callTool(t, c, "ListQuizzes", nil)); text != "[]" {
+		t.Errorf("ListQuizzes with no quizzes = %s, want []", text)
+	}
+
+	created, err := repos.Quizzes.CreateFull(context.Background(), models.QuizDraft{
+		Title: "Capitals",
+		Questions: []models.QuestionDraft{{
+			Question: "Capital of France?",
+			Type:     models.QuestionTypeMultipleChoice,
+			Answers:  []models.AnswerDraft{{Answer: "Paris", Correct: true}, {Answer: "Lyon"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+
+	var catalogue []models.Quiz
+	if err := json.Unmarshal([]byte(readResource(t, c, quizCatalogURI)), &catalogue); err != nil {
+		t.Fatalf("catalogue is not a list of quizzes: %v", err)
+	}
+	if len(catalogue) != 1 || int64(catalogue[0].ID) != created.ID || catalogue[0].Title != "Capitals" {
+		t.Errorf("catalogue = %+v, want Capitals", catalogue)
+	}
+
+	var quiz models.FullQuiz
+	if err := json.Unmarshal([]byte(readResource(t, c, "quiz://"+strconv.FormatInt(created.ID, 10))), &quiz); err != nil {
+		t.Fatalf("quiz resource is not a quiz: %v", err)
+	}
+	if quiz.Title != "Capitals" || len(quiz.Questions) != 1 || len(quiz.Questions[0].Answers) != 2 || !quiz.Questions[0].Answers[0].Correct {
+		t.Errorf("quiz resource = %+v, want Capitals with its answers and correctness", quiz)
+	}
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "quiz://404"
+	if _, err := c.ReadResource(context.Background(), request); err == nil || !strings.Contains(err.Error(), "quiz not found") {
+		t.Errorf("missing quiz = %v, want quiz not found", err)
+	}
+}
+
+// getPrompt gets a prompt and returns its result, or the error it failed with.
+func getPrompt(c *client.Client, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	return c.GetPrompt(context.Background(), request)
+}
+
+func TestGenerateQuizPrompt(t *testing.T) {
+	c := newInProcessClient(t, memory.NewRepositories())
+
+	result, err := getPrompt(c, "generate_quiz", map[string]string{
+		"topic":          "Physics",
+		"num_questions":  "3",
+		"question_types": "true_false, numeric",
+	})
+	if err != nil {
+		t.Fatalf("generate_quiz failed: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("generate_quiz has %d messages, want 1", len(result.Messages))
+	}
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	for _, want := range []string{"about Physics with exactly 3 questions", "types: true_false, numeric.", quizCatalogURI, "FormatQuizForApi"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("generate_quiz does not contain %q:\n%s", want, text)
+		}
+	}
+
+	for _, arguments := range []map[string]string{
+		{"topic": " "},
+		{"topic": "Physics", "num_questions": "0"},
+		{"topic": "Physics", "question_types": "essay"},
+	} {
+		if _, err := getPrompt(c, "generate_quiz", arguments); err == nil {
+			t.Errorf("generate_quiz accepted %v", arguments)
+		}
+	}
+}
+
+func TestExtendQuizPrompt(t *testing.T) {
+	repos := memory.NewRepositories()
+	quizID, err := repos.Quizzes.Create(context.Background(), models.QuizInput{Title: "Physics"})
+	if err != nil {
+		t.Fatalf("failed to create quiz: %v", err)
+	}
+	c := newInProcessClient(t, repos)
+	id := strconv.FormatInt(quizID, 10)
+
+	result, err := getPrompt(c, "extend_quiz", map[string]string{"quiz_id": id, "num_questions": "4"})
+	if err != nil {
+		t.Fatalf("extend_quiz failed: %v", err)
+	}
+	if len(result.Messages) != 2 {
+		t.Fatalf("extend_quiz has %d messages, want the quiz then the instructions", len(result.Messages))
+	}
+	embedded := result.Messages[0].Content.(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if embedded.URI != "quiz://"+id || !strings.Contains(embedded.Text, `"title":"Physics"`) {
+		t.Errorf("embedded quiz = %+v, want quiz %s", embedded, id)
+	}
+	text := result.Messages[1].Content.(mcp.TextContent).Text
+	if !strings.Contains(text, "Add 4 new questions") || !strings.Contains(text, "AddQuestion tool once per question with quiz_id "+id) {
+		t.Errorf("extend_quiz instructions:\n%s", text)
+	}
+
+	if _, err := getPrompt(c, "extend_quiz", map[string]string{"quiz_id": "404"}); err == nil || !strings.Contains(err.Error(), "quiz not found") {
+		t.Errorf("extending a missing quiz = %v, want quiz not found", err)
+	}
+}